@@ -0,0 +1,11 @@
+//go:build go1.21
+// +build go1.21
+
+package rollbar
+
+import "context"
+
+// afterFunc arranges to call f in its own goroutine after ctx is done, see context.AfterFunc.
+func afterFunc(ctx context.Context, f func()) (stop func() bool) {
+	return context.AfterFunc(ctx, f)
+}
@@ -0,0 +1,37 @@
+//go:build !go1.21
+// +build !go1.21
+
+package rollbar
+
+import (
+	"context"
+	"sync"
+)
+
+// afterFunc arranges to call f in its own goroutine after ctx is done. Calling stop prevents f
+// from being called if it has not been called yet, and returns whether it did so.
+func afterFunc(ctx context.Context, f func()) (stop func() bool) {
+	var once sync.Once
+	stopped := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-stopped:
+			return
+		}
+		// Whichever of this goroutine and stop runs once first wins.
+		started := false
+		once.Do(func() { started = true })
+		if started {
+			f()
+		}
+	}()
+	return func() bool {
+		ok := false
+		once.Do(func() {
+			ok = true
+			close(stopped)
+		})
+		return ok
+	}
+}
@@ -18,6 +18,12 @@ type AsyncTransport struct {
 	Buffer      int
 	bodyChannel chan payload
 	waitGroup   sync.WaitGroup
+	// room of the buffer reserved for the items of priorityLevel or higher, see SetQueuePriority
+	priorityReserve int
+	priorityLevel   string
+	// what to do with the items sent while the queue is full, see SetDropPolicy
+	dropPolicy  DropPolicy
+	dropTimeout time.Duration
 }
 
 type payload struct {
@@ -72,41 +78,44 @@ func NewAsyncTransport(token string, endpoint string, buffer int, opts ...transp
 		}()
 
 		for p := range transport.bodyChannel {
-			elapsedTime := time.Now().Sub(transport.startTime).Seconds()
-			if elapsedTime < 0 || elapsedTime >= 60 {
-				transport.startTime = time.Now()
-				transport.perMinCounter = 0
-			}
-			if transport.shouldSend() {
+			transport.resetCounters(transport.now())
+			if transport.shouldSend(p.body) {
 				canRetry, err := transport.post(p.body)
 				if err != nil {
 					if canRetry && p.retriesLeft > 0 {
 						p.retriesLeft -= 1
 						select {
 						case <-transport.ctx.Done(): // check for early termination
-							writePayloadToStderr(transport.Logger, p.body)
+							transport.dropped(p.body, DropReasonStopped)
+							writePayloadToStderr(transport.Logger, p.body, transport.CompressPayloadOnError)
+							transport.sendFailed(p.body, err)
 							transport.waitGroup.Done()
 							return
 						case transport.bodyChannel <- p:
+							transport.metrics.retried()
 						default:
 							// This can happen if the bodyChannel had an item added to it from another
 							// thread while we are processing such that the channel is now full. If we try
 							// to send the payload back to the channel without this select statement we
 							// could deadlock. Instead we consider this a retry failure.
+							transport.dropped(p.body, DropReasonFailed)
 							if transport.PrintPayloadOnError {
-								writePayloadToStderr(transport.Logger, p.body)
+								writePayloadToStderr(transport.Logger, p.body, transport.CompressPayloadOnError)
 							}
+							transport.sendFailed(p.body, err)
 							transport.waitGroup.Done()
 						}
 					} else {
+						transport.dropped(p.body, DropReasonFailed)
 						if transport.PrintPayloadOnError {
-							writePayloadToStderr(transport.Logger, p.body)
+							writePayloadToStderr(transport.Logger, p.body, transport.CompressPayloadOnError)
 						}
+						transport.sendFailed(p.body, err)
 						transport.waitGroup.Done()
 					}
 				} else {
 					transport.waitGroup.Done()
-					transport.perMinCounter++
+					transport.countSent(p.body)
 				}
 			} else {
 				transport.waitGroup.Done()
@@ -132,28 +141,70 @@ func (t *AsyncTransport) Send(body map[string]interface{}) (err error) {
 			}
 		}
 	}()
-	if len(t.bodyChannel) < t.Buffer {
-		t.waitGroup.Add(1)
-		p := payload{
-			body:        body,
-			retriesLeft: t.RetryAttempts,
+	ensureItemUUID(body)
+	queued := len(t.bodyChannel)
+	if !t.admits(body, queued) {
+		return t.queueFull(body)
+	}
+	policy, timeout := t.getDropPolicy()
+	if queued >= t.Buffer {
+		switch policy {
+		case DropOldestPolicy:
+			t.evictOldest()
+		case BlockPolicy:
+		default:
+			return t.queueFull(body)
 		}
+	}
+	return t.enqueue(payload{body: body, retriesLeft: t.RetryAttempts}, policy == BlockPolicy, timeout)
+}
+
+// enqueue queues p, waiting for room in the queue if block is true, for at most timeout if it is
+// positive. The item is dropped if the queue is full, or the context of the transport is done.
+func (t *AsyncTransport) enqueue(p payload, block bool, timeout time.Duration) error {
+	t.waitGroup.Add(1)
+	var full <-chan time.Time
+	if block && timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		full = timer.C
+	}
+	if !block {
 		select {
-		case <-t.ctx.Done(): // check for early termination
-			writePayloadToStderr(t.Logger, body)
-			return t.ctx.Err()
 		case t.bodyChannel <- p:
+			t.metrics.queued(len(t.bodyChannel))
+			return nil
 		default:
+			if t.ctx.Err() == nil {
+				t.waitGroup.Done()
+				return t.queueFull(p.body)
+			}
 		}
-	} else {
-		err = ErrBufferFull{}
-		rollbarError(t.Logger, err.Error())
-		if t.PrintPayloadOnError {
-			writePayloadToStderr(t.Logger, body)
-		}
-		return err
 	}
-	return nil
+	select {
+	case <-t.ctx.Done(): // check for early termination
+		t.waitGroup.Done()
+		t.dropped(p.body, DropReasonStopped)
+		writePayloadToStderr(t.Logger, p.body, t.CompressPayloadOnError)
+		return t.ctx.Err()
+	case t.bodyChannel <- p:
+		t.metrics.queued(len(t.bodyChannel))
+		return nil
+	case <-full:
+		t.waitGroup.Done()
+		return t.queueFull(p.body)
+	}
+}
+
+// queueFull drops the item with the given body as the queue is full and returns ErrBufferFull.
+func (t *AsyncTransport) queueFull(body map[string]interface{}) error {
+	err := ErrBufferFull{}
+	t.dropped(body, DropReasonQueueFull)
+	rollbarError(t.Logger, err.Error())
+	if t.PrintPayloadOnError {
+		writePayloadToStderr(t.Logger, body, t.CompressPayloadOnError)
+	}
+	return err
 }
 
 // Wait blocks until all of the items currently in the queue have been sent.
@@ -1,7 +1,13 @@
 package rollbar
 
 import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestAsyncTransportSend(t *testing.T) {
@@ -32,6 +38,9 @@ func TestAsyncTransportSendFull(t *testing.T) {
 	if transport.perMinCounter != 0 {
 		t.Error("shouldSend check failed")
 	}
+	if metrics := transport.Metrics(); metrics.Dropped != 1 || metrics.QueueDepth != 0 {
+		t.Error("wrong metrics, got:", metrics)
+	}
 }
 
 func TestAsyncTransportSendRecover(t *testing.T) {
@@ -97,3 +106,129 @@ func TestAsyncTransportNotSend(t *testing.T) {
 		t.Error("shouldSend check failed")
 	}
 }
+
+func TestAsyncTransportQueuePriority(t *testing.T) {
+	received := make(chan string, 10)
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.URL.Path
+		<-release
+	}))
+	defer ts.Close()
+
+	transport := NewAsyncTransport("token", ts.URL, 4)
+	transport.SetLogger(&SilentClientLogger{})
+	transport.SetPrintPayloadOnError(false)
+	transport.SetQueuePriority(2, ERR)
+	item := func(level string) map[string]interface{} {
+		return map[string]interface{}{"data": map[string]interface{}{"level": level}}
+	}
+
+	// The first item is taken off the queue and blocks the transport until released.
+	transport.Send(item(INFO))
+	<-received
+	var errs []error
+	for _, level := range []string{DEBUG, INFO, DEBUG, ERR, CRIT, ERR} {
+		errs = append(errs, transport.Send(item(level)))
+	}
+	close(release)
+	transport.Wait()
+
+	for i, dropped := range []bool{false, false, true, false, false, true} {
+		if (errs[i] != nil) != dropped {
+			t.Errorf("item %d: expected dropped to be %v, got: %v", i, dropped, errs[i])
+		}
+	}
+	if metrics := transport.Metrics(); metrics.Dropped != 2 || metrics.Sent != 5 {
+		t.Error("wrong metrics, got:", metrics)
+	}
+}
+
+// blockedAsyncTransport returns a transport whose first item blocks it until release is closed,
+// the channel receiving the bodies posted, the release channel and a func stopping the server.
+func blockedAsyncTransport(buffer int, policy DropPolicy, timeout time.Duration) (*AsyncTransport, chan string, chan struct{}, func()) {
+	received := make(chan string, 10)
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		received <- string(body)
+		<-release
+	}))
+
+	transport := NewAsyncTransport("token", ts.URL, buffer)
+	transport.SetLogger(&SilentClientLogger{})
+	transport.SetPrintPayloadOnError(false)
+	transport.SetDropPolicy(policy, timeout)
+	transport.Send(messageItem("first"))
+	<-received
+	return transport, received, release, ts.Close
+}
+
+func messageItem(message string) map[string]interface{} {
+	return map[string]interface{}{"data": map[string]interface{}{"title": message}}
+}
+
+func TestAsyncTransportDropOldestPolicy(t *testing.T) {
+	transport, received, release, stop := blockedAsyncTransport(2, DropOldestPolicy, 0)
+	defer stop()
+	for _, message := range []string{"oldest", "older", "newest"} {
+		if err := transport.Send(messageItem(message)); err != nil {
+			t.Errorf("%s: expected the oldest item to be dropped instead, got: %v", message, err)
+		}
+	}
+	close(release)
+	transport.Wait()
+
+	var sent []string
+	for len(received) > 0 {
+		body := <-received
+		for _, message := range []string{"oldest", "older", "newest"} {
+			if strings.Contains(body, `"`+message+`"`) {
+				sent = append(sent, message)
+			}
+		}
+	}
+	if fmt.Sprint(sent) != "[older newest]" {
+		t.Error("wrong items sent, got:", sent)
+	}
+	if metrics := transport.Metrics(); metrics.Dropped != 1 || metrics.Sent != 3 {
+		t.Error("wrong metrics, got:", metrics)
+	}
+}
+
+func TestAsyncTransportBlockPolicy(t *testing.T) {
+	transport, _, release, stop := blockedAsyncTransport(1, BlockPolicy, 10*time.Millisecond)
+	defer stop()
+	if config := transportConfig(transport); config.DropPolicy != BlockPolicy || config.DropTimeout != 10*time.Millisecond {
+		t.Error("wrong transport config, got:", config)
+	}
+	transport.Send(messageItem("queued"))
+	start := time.Now()
+	if err := transport.Send(messageItem("timeout")); err == nil {
+		t.Error("expected the item to be dropped once the timeout expired")
+	}
+	if elapsed := time.Since(start); elapsed < 10*time.Millisecond {
+		t.Error("expected Send to block until the timeout, returned after", elapsed)
+	}
+	close(release)
+	transport.Wait()
+
+	transport, _, release, stop = blockedAsyncTransport(1, BlockPolicy, 0)
+	defer stop()
+	transport.Send(messageItem("queued"))
+	blocked := make(chan error)
+	go func() { blocked <- transport.Send(messageItem("blocked")) }()
+	select {
+	case err := <-blocked:
+		t.Error("expected Send to block while the queue is full, got:", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	close(release)
+	if err := <-blocked; err != nil {
+		t.Error("expected the blocked item to be queued, got:", err)
+	}
+	transport.Wait()
+	if metrics := transport.Metrics(); metrics.Dropped != 0 || metrics.Sent != 3 {
+		t.Error("wrong metrics, got:", metrics)
+	}
+}
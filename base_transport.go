@@ -27,12 +27,31 @@ type baseTransport struct {
 	// PrintPayloadOnError is whether or not to output the payload to the set logger or to stderr if
 	// an error occurs during transport to the Rollbar API.
 	PrintPayloadOnError bool
+	// CompressPayloadOnError is whether payloads output because of an error are written gzip
+	// compressed and base64 encoded rather than as text. See DecodePayload.
+	CompressPayloadOnError bool
 	// ItemsPerMinute has the max number of items to send in a given minute
 	ItemsPerMinute int
 	// custom http client (http.DefaultClient used by default)
 	httpClient *http.Client
+	// clock used for rate limiting (time.Now used by default)
+	clock Clock
+	// key used to sign payloads, see SetSigningKey
+	signingKey []byte
+	// counters and gauges reported by Metrics, and events of the client
+	metrics transportMetrics
+
+	// max number of items of given levels to send in a given minute, see SetItemsPerMinuteByLevel
+	levelLimits map[string]int
+	// whether rejections by the API carry its message, see SetStrict
+	strict bool
+	// called with the items which could not be sent, see SetSendErrorHandler
+	onSendError SendErrorFunc
+	// occurrences dropped by the rate limits, reported with the next item sent of the same kind
+	suppressed suppressionCounter
 
 	perMinCounter int
+	levelCounters map[string]int
 	startTime     time.Time
 	lock          sync.RWMutex
 }
@@ -62,6 +81,13 @@ func (t *baseTransport) SetLogger(logger ClientLogger) {
 	t.Logger = logger
 }
 
+// getLogger returns the logger set with SetLogger, if any.
+func (t *baseTransport) getLogger() ClientLogger {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	return t.Logger
+}
+
 // SetRetryAttempts is how often to attempt to resend an item when a temporary network error occurs
 // This defaults to DefaultRetryAttempts
 // Set this value to 0 if you do not want retries to happen
@@ -75,6 +101,14 @@ func (t *baseTransport) SetPrintPayloadOnError(printPayloadOnError bool) {
 	t.PrintPayloadOnError = printPayloadOnError
 }
 
+// SetCompressPayloadOnError sets whether payloads output because of an error are written gzip
+// compressed and base64 encoded on a single line, preceded by a short header, rather than as text.
+// This keeps large payloads, such as those carrying goroutine dumps, from flooding log pipelines
+// while keeping them recoverable with DecodePayload.
+func (t *baseTransport) SetCompressPayloadOnError(compress bool) {
+	t.CompressPayloadOnError = compress
+}
+
 // SetHTTPClient sets custom http client. http.DefaultClient is used by default
 func (t *baseTransport) SetHTTPClient(c *http.Client) {
 	t.httpClient = c
@@ -89,13 +123,16 @@ func (t *baseTransport) getHTTPClient() *http.Client {
 	return http.DefaultClient
 }
 
-func (t *baseTransport) clientPost(body io.Reader) (resp *http.Response, err error) {
-	req, err := http.NewRequest("POST", t.Endpoint, body)
+func (t *baseTransport) clientPost(body []byte) (resp *http.Response, err error) {
+	req, err := http.NewRequest("POST", t.Endpoint, bytes.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("X-Rollbar-Access-Token", t.Token)
+	if len(t.signingKey) > 0 {
+		req.Header.Set(SignatureHeader, Sign(body, t.signingKey))
+	}
 	return t.getHTTPClient().Do(req)
 }
 
@@ -115,35 +152,87 @@ func (t *baseTransport) post(body map[string]interface{}) (bool, error) {
 	jsonBody, err := json.Marshal(body)
 	if err != nil {
 		rollbarError(t.Logger, "failed to encode payload: %s", err.Error())
+		t.metrics.sendError(err)
 		return false, err
 	}
 
-	resp, err := t.clientPost(bytes.NewReader(jsonBody))
+	start := time.Now()
+	resp, err := t.clientPost(jsonBody)
+	t.metrics.latency(time.Since(start))
 	if err != nil {
 		rollbarError(t.Logger, "POST failed: %s", err.Error())
+		t.metrics.sendError(err)
 		return isTemporary(err), err
 	}
 
+	var excerpt string
+	if resp.StatusCode != 200 {
+		excerpt = readExcerpt(resp.Body)
+	}
 	io.Copy(ioutil.Discard, resp.Body)
 	resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		rollbarError(t.Logger, "received response: %s", resp.Status)
+		var apiMessage string
+		if t.strict {
+			apiMessage = apiErrorMessage(excerpt)
+		}
+		if apiMessage != "" {
+			rollbarError(t.Logger, "received response: %s: %s", resp.Status, apiMessage)
+		} else {
+			rollbarError(t.Logger, "received response: %s", resp.Status)
+		}
 		// http.StatusTooManyRequests is only defined in Go 1.6+ so we use 429 directly
 		isRateLimit := resp.StatusCode == 429
-		return isRateLimit, ErrHTTPError(resp.StatusCode)
+		statusErr := httpError(resp, t.nowLocked())
+		if _, ok := statusErr.(ErrHTTPError); ok && t.strict {
+			statusErr = ErrRejected{StatusCode: resp.StatusCode, Message: apiMessage}
+		}
+		err := &APIError{
+			StatusCode: resp.StatusCode,
+			Body:       excerpt,
+			RateLimit:  rateLimit(resp.Header),
+			Retryable:  isRateLimit,
+			Err:        statusErr,
+		}
+		t.metrics.sendError(err)
+		if isRateLimit {
+			t.metrics.emit(RateLimited{UUID: itemUUID(body)})
+		}
+		return isRateLimit, err
 	}
 
+	t.metrics.sent(time.Since(start))
+	t.metrics.emit(ItemSent{UUID: itemUUID(body)})
 	return false, nil
 }
 
-func (t *baseTransport) shouldSend() bool {
+// dropped records that the item with the given body was discarded for the given reason, one of the
+// DropReason constants. Items which could not be sent are recorded as failed rather than dropped
+// in the metrics.
+func (t *baseTransport) dropped(body map[string]interface{}, reason string) {
+	if reason == DropReasonFailed {
+		t.metrics.failed()
+	} else {
+		t.metrics.dropped()
+	}
+	t.metrics.emit(ItemDropped{UUID: itemUUID(body), Reason: reason})
+}
+
+func (t *baseTransport) shouldSend(body map[string]interface{}) bool {
 	t.lock.RLock()
 	defer t.lock.RUnlock()
-	if t.ItemsPerMinute > 0 && t.perMinCounter >= t.ItemsPerMinute {
+	allowed, limited := t.withinLevelLimit(body)
+	if !limited && t.ItemsPerMinute > 0 && t.perMinCounter >= t.ItemsPerMinute {
 		rollbarError(t.Logger, fmt.Sprintf("item per minute limit reached: %d occurences, "+
 			"ignoring errors until timeout", t.perMinCounter))
+		allowed = false
+	}
+	if !allowed {
+		t.dropped(body, DropReasonItemsPerMinute)
+		t.suppressed.suppress(body, t.nowLocked())
 		return false
 	}
+	t.suppressed.attach(body)
 	return true
 }
@@ -0,0 +1,113 @@
+package rollbar
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// A ClaimsFunc returns the claims of the validated token of a request, such as the claims of a JWT
+// stored in the request context by an authentication middleware, or nil if there are none. The
+// rollbar package does not validate tokens itself.
+type ClaimsFunc func(*http.Request) map[string]interface{}
+
+// PersonClaims are the names of the claims holding the fields of a Person.
+type PersonClaims struct {
+	ID       string
+	Username string
+	Email    string
+}
+
+// DefaultPersonClaims are the registered and standard OpenID Connect claims describing the user
+// of a token.
+var DefaultPersonClaims = PersonClaims{
+	ID:       "sub",
+	Username: "preferred_username",
+	Email:    "email",
+}
+
+// PersonFromClaims returns the Person described by the claims of a token, or nil if the claims
+// have no ID. Claims which are not strings, such as numeric IDs, are formatted with fmt.Sprint.
+func PersonFromClaims(claims map[string]interface{}, names PersonClaims) *Person {
+	id := claimString(claims, names.ID)
+	if id == "" {
+		return nil
+	}
+	return &Person{
+		Id:       id,
+		Username: claimString(claims, names.Username),
+		Email:    claimString(claims, names.Email),
+	}
+}
+
+func claimString(claims map[string]interface{}, name string) string {
+	value, ok := claims[name]
+	if name == "" || !ok || value == nil {
+		return ""
+	}
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return fmt.Sprint(value)
+}
+
+// WithMiddlewarePersonClaims makes the middleware report the person identified by the claims of
+// the token of each request, as returned by claims, e.g. with the claims of the JWT validated by
+// an authentication middleware. A person set with NewPersonContext takes precedence.
+//
+//	rollbar.Middleware(rollbar.WithMiddlewarePersonClaims(func(r *http.Request) map[string]interface{} {
+//		claims, _ := r.Context().Value(authClaimsKey).(jwt.MapClaims)
+//		return claims
+//	}, rollbar.DefaultPersonClaims))
+func WithMiddlewarePersonClaims(claims ClaimsFunc, names PersonClaims) MiddlewareOption {
+	return func(m *middleware) {
+		m.claims = claims
+		m.personClaims = names
+	}
+}
+
+// BearerTokenClaims is a ClaimsFunc returning the claims of the JWT given as a bearer token in the
+// Authorization header of a request, or nil if there is none. The signature of the token is not
+// verified: the claims must only be used to report who made a request, never to authorize it, and
+// the token should have been validated by an authentication middleware. Numeric claims are
+// returned as json.Number so that large IDs are reported exactly.
+//
+//	rollbar.Middleware(rollbar.WithMiddlewarePersonClaims(rollbar.BearerTokenClaims, rollbar.DefaultPersonClaims))
+func BearerTokenClaims(r *http.Request) map[string]interface{} {
+	auth := r.Header.Get("Authorization")
+	if len(auth) < 7 || !strings.EqualFold(auth[:7], "bearer ") {
+		return nil
+	}
+	parts := strings.Split(strings.TrimSpace(auth[7:]), ".")
+	if len(parts) != 3 {
+		return nil
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	var claims map[string]interface{}
+	if err := decoder.Decode(&claims); err != nil {
+		return nil
+	}
+	return claims
+}
+
+// PersonProviderFromClaims returns a PersonProviderFunc resolving the person of items from the
+// claims of the token of their request, for items reported outside of the middleware, e.g.
+//
+//	rollbar.SetPersonProvider(rollbar.PersonProviderFromClaims(rollbar.BearerTokenClaims, rollbar.DefaultPersonClaims))
+func PersonProviderFromClaims(claims ClaimsFunc, names PersonClaims) PersonProviderFunc {
+	return func(ctx context.Context, r *http.Request) *Person {
+		if r == nil {
+			return nil
+		}
+		return PersonFromClaims(claims(r), names)
+	}
+}
@@ -5,11 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
-	"os"
 	"reflect"
 	"regexp"
 	"runtime"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -24,10 +26,11 @@ type Client struct {
 	io.Closer
 	// Transport used to send data to the Rollbar API. By default an asynchronous
 	// implementation of the Transport interface is used.
-	Transport     Transport
-	Telemetry     *Telemetry
-	configuration configuration
-	diagnostic    diagnostic
+	Transport  Transport
+	Telemetry  *Telemetry
+	config     atomic.Value // *configuration, see ApplyOptions
+	reconfig   sync.Mutex
+	diagnostic diagnostic
 }
 
 type clientOption func(*Client)
@@ -50,11 +53,11 @@ func NewAsync(token, environment, codeVersion, serverHost, serverRoot string, op
 	transport := NewTransport(token, configuration.endpoint)
 	diagnostic := createDiagnostic()
 	c := &Client{
-		Transport:     transport,
-		Telemetry:     NewTelemetry(nil),
-		configuration: configuration,
-		diagnostic:    diagnostic,
+		Transport:  transport,
+		Telemetry:  NewTelemetry(nil),
+		diagnostic: diagnostic,
 	}
+	c.config.Store(&configuration)
 	for _, opt := range opts {
 		// Call the option giving the instantiated
 		// *Client as the argument
@@ -72,29 +75,133 @@ func NewSync(token, environment, codeVersion, serverHost, serverRoot string) *Cl
 	configuration := createConfiguration(token, environment, codeVersion, serverHost, serverRoot)
 	transport := NewSyncTransport(token, configuration.endpoint)
 	diagnostic := createDiagnostic()
-	return &Client{
-		Transport:     transport,
-		Telemetry:     NewTelemetry(nil),
-		configuration: configuration,
-		diagnostic:    diagnostic,
+	c := &Client{
+		Transport:  transport,
+		Telemetry:  NewTelemetry(nil),
+		diagnostic: diagnostic,
 	}
+	c.config.Store(&configuration)
+	return c
+}
+
+// configuration returns the current configuration of the client. Functions reading several
+// settings should load it once, so that they are not affected by a concurrent ApplyOptions.
+func (c *Client) configuration() *configuration {
+	return c.config.Load().(*configuration)
 }
 
 // CaptureTelemetryEvent sets the user-specified telemetry event
 func (c *Client) CaptureTelemetryEvent(eventType, eventlevel string, eventData map[string]interface{}) {
+	c.CaptureTelemetryEventWithContext(context.Background(), eventType, eventlevel, eventData)
+}
+
+// CaptureTelemetryEventWithContext sets the user-specified telemetry event within the given
+// context. The event is only attached to the items reported within the context when it carries a
+// telemetry queue of its own, see NewTelemetryContext.
+func (c *Client) CaptureTelemetryEventWithContext(ctx context.Context, eventType, eventlevel string, eventData map[string]interface{}) {
 	data := map[string]interface{}{}
 	data["body"] = eventData
 	data["type"] = eventType
 	data["level"] = eventlevel
 	data["source"] = "client"
-	data["timestamp_ms"] = time.Now().UnixNano() / int64(time.Millisecond)
+	data["timestamp_ms"] = c.configuration().clock.Now().UnixNano() / int64(time.Millisecond)
+
+	c.Telemetry.record(ctx, data)
+}
+
+// telemetryItems returns a snapshot of the telemetry events attached to the items reported within
+// ctx: those of its own telemetry queue if it carries one, and the events of the client otherwise,
+// selected as set with SetAttachTelemetry, SetTelemetryLevel and SetTelemetryMaxEvents.
+func (c *Client) telemetryItems(ctx context.Context) []interface{} {
+	conf := c.configuration()
+	if !conf.telemetry {
+		return nil
+	}
+	items := c.Telemetry.GetQueueItems()
+	if queue, ok := TelemetryFromContext(ctx); ok {
+		items = queue.Snapshot()
+	}
+	return selectTelemetry(items, conf.telemetryLevel, conf.telemetryMax)
+}
+
+// RecordNavigationTelemetry captures a navigation telemetry event describing a transition from one
+// state to another, such as a switch between queues or a change of leader.
+func (c *Client) RecordNavigationTelemetry(from, to string) {
+	c.CaptureTelemetryEvent("navigation", "info", map[string]interface{}{
+		"from": from,
+		"to":   to,
+	})
+}
 
-	c.Telemetry.Queue.Push(data)
+// RecordConnectivityTelemetry captures a connectivity telemetry event recording that the
+// application went online or offline. Going offline is recorded with the warning level.
+func (c *Client) RecordConnectivityTelemetry(online bool) {
+	change, level := "offline", "warning"
+	if online {
+		change, level = "online", "info"
+	}
+	c.CaptureTelemetryEvent("connectivity", level, map[string]interface{}{
+		"change": change,
+	})
+}
+
+// RecordLogTelemetry captures a log telemetry event with the given level, such as "info" or
+// "warning", and message.
+func (c *Client) RecordLogTelemetry(level, message string) {
+	c.CaptureTelemetryEvent("log", level, map[string]interface{}{
+		"message": message,
+	})
+}
+
+// RecordNetworkTelemetry captures a network telemetry event for a request with the given method
+// and URL which got a response with the given status code, like the events captured by
+// EnableNetworkTelemetry for HTTP clients. Its level is critical for server errors, error for
+// client errors and info otherwise.
+func (c *Client) RecordNetworkTelemetry(method, url string, status int) {
+	c.CaptureTelemetryEvent("network", networkTelemetryLevel(status), map[string]interface{}{
+		"method":      method,
+		"url":         url,
+		"status_code": status,
+	})
+}
+
+// RecordManualTelemetry captures a telemetry event of the given type with the info level, for
+// breadcrumbs which are neither logs nor network requests. The type defaults to "manual".
+func (c *Client) RecordManualTelemetry(eventType string, body map[string]interface{}) {
+	if eventType == "" {
+		eventType = "manual"
+	}
+	c.CaptureTelemetryEvent(eventType, "info", body)
 }
 
 // SetTelemetry sets the telemetry
 func (c *Client) SetTelemetry(options ...OptionFunc) {
-	c.Telemetry = NewTelemetry(c.configuration.scrubHeaders, options...)
+	conf := c.configuration()
+	telemetry := NewTelemetry(conf.scrubHeaders, options...)
+	telemetry.Network.AllowedHeaders = conf.allowHeaders
+	telemetry.SetClock(conf.clock)
+	c.Telemetry = telemetry
+}
+
+// SetAttachTelemetry sets whether the captured telemetry events are attached to the reported items
+// as data.body.telemetry. Events are still captured when they are not attached. The default value
+// is true.
+func (c *Client) SetAttachTelemetry(attach bool) {
+	c.ApplyOptions(WithAttachTelemetry(attach))
+}
+
+// SetTelemetryMaxEvents sets the maximum number of telemetry events attached to an item, the most
+// recent ones being kept. The default value is 0, which attaches all the captured events.
+func (c *Client) SetTelemetryMaxEvents(max int) {
+	c.ApplyOptions(WithTelemetryMaxEvents(max))
+}
+
+// SetTelemetryLevel sets the minimum level of the telemetry events attached to items, one of
+// "debug", "info", "warning", "error" and "critical". Events of the "log" level, such as those
+// captured by EnableLoggerTelemetry, rank as "info", and events of other levels are always
+// attached. The default value is "", which attaches events of all levels.
+func (c *Client) SetTelemetryLevel(level string) {
+	c.ApplyOptions(WithTelemetryLevel(level))
 }
 func (c *Client) SetContext(ctx context.Context) {
 	c.ctx = ctx
@@ -106,7 +213,7 @@ func (c *Client) SetContext(ctx context.Context) {
 // If this is false then no calls will be made to the network.
 // One place where this is useful is for turning off reporting in tests.
 func (c *Client) SetEnabled(enabled bool) {
-	c.configuration.enabled = enabled
+	c.ApplyOptions(WithEnabled(enabled))
 }
 
 // SetToken sets the token used by this Client.
@@ -114,45 +221,55 @@ func (c *Client) SetEnabled(enabled bool) {
 // It is required to set this value before any of the other functions herein will be able to work
 // properly. This also configures the underlying Transport.
 func (c *Client) SetToken(token string) {
-	c.configuration.token = token
+	c.ApplyOptions(WithToken(token))
 	c.Transport.SetToken(token)
 }
 
 // SetEnvironment sets the environment under which all errors and messages will be submitted.
 func (c *Client) SetEnvironment(environment string) {
-	c.configuration.environment = environment
+	c.ApplyOptions(WithEnvironment(environment))
+}
+
+// SetContextName sets the name of the operation reported as data.context for all items, e.g.
+// "billing#charge" for a worker, which Rollbar uses to group and filter items by operation. The name
+// carried by the context of a report, see NewContextNameContext, takes precedence. The default
+// value is "", which reports no context.
+func (c *Client) SetContextName(name string) {
+	c.ApplyOptions(WithContextName(name))
 }
 
 // SetEndpoint sets the endpoint to post items to. This also configures the underlying Transport.
 func (c *Client) SetEndpoint(endpoint string) {
-	c.configuration.endpoint = endpoint
+	c.ApplyOptions(WithEndpoint(endpoint))
 	c.Transport.SetEndpoint(endpoint)
 }
 
-// SetPlatform sets the platform to be reported for all items.
+// SetPlatform sets the platform to be reported for all items. The default value is detected from
+// the environment: "heroku", "aws_lambda", "cloud_run" or "docker", whose details such as the dyno
+// or the function name are reported in data.server, or else runtime.GOOS.
 func (c *Client) SetPlatform(platform string) {
-	c.configuration.platform = platform
+	c.ApplyOptions(WithPlatform(platform))
 }
 
 // SetCodeVersion sets the string describing the running code version on the server.
 func (c *Client) SetCodeVersion(codeVersion string) {
-	c.configuration.codeVersion = codeVersion
+	c.ApplyOptions(WithCodeVersion(codeVersion))
 }
 
 // SetServerHost sets the hostname sent with each item. This value will be indexed.
 func (c *Client) SetServerHost(serverHost string) {
-	c.configuration.serverHost = serverHost
+	c.ApplyOptions(WithServerHost(serverHost))
 }
 
 // SetServerRoot sets the path to the application code root, not including the final slash.
 // This is used to collapse non-project code when displaying tracebacks.
 func (c *Client) SetServerRoot(serverRoot string) {
-	c.configuration.serverRoot = serverRoot
+	c.ApplyOptions(WithServerRoot(serverRoot))
 }
 
 // SetCustom sets any arbitrary metadata you want to send with every item.
 func (c *Client) SetCustom(custom map[string]interface{}) {
-	c.configuration.custom = custom
+	c.ApplyOptions(WithCustom(custom))
 }
 
 // SetPerson information for identifying a user associated with
@@ -178,7 +295,7 @@ func (c *Client) SetPerson(id, username, email string, opts ...personOption) {
 		opt(&person)
 	}
 
-	c.configuration.person = person
+	c.ApplyOptions(func(conf *configuration) { conf.person = person })
 }
 
 // ClearPerson clears any previously set person information. See `SetPerson` for more
@@ -186,13 +303,36 @@ func (c *Client) SetPerson(id, username, email string, opts ...personOption) {
 func (c *Client) ClearPerson() {
 	person := Person{}
 
-	c.configuration.person = person
+	c.ApplyOptions(func(conf *configuration) { conf.person = person })
+}
+
+// SetPersonProvider sets the PersonProviderFunc used to resolve the person of the items when they
+// are reported. The person carried by the context of a report, see NewPersonContext, takes
+// precedence over the provider, which takes precedence over the person set with SetPerson when it
+// returns a person. A nil provider, the default, disables it.
+func (c *Client) SetPersonProvider(provider PersonProviderFunc) {
+	c.ApplyOptions(func(conf *configuration) { conf.personProvider = provider })
+}
+
+// SetPersonScrubPolicy sets how the email and username of the person of the items are reported,
+// e.g. HashEmail|OmitUsername for deployments which may not send them to Rollbar. The person id is
+// always reported as is, so that the affected users are still counted. The policy applies to the
+// person set with SetPerson as well as to the ones carried by contexts and returned by the person
+// provider. The default value is 0, which reports them as is.
+func (c *Client) SetPersonScrubPolicy(policy PersonScrubPolicy) {
+	c.ApplyOptions(WithPersonScrubPolicy(policy))
+}
+
+// PersonScrubPolicy returns how the email and username of the person are reported, see
+// SetPersonScrubPolicy.
+func (c *Client) PersonScrubPolicy() PersonScrubPolicy {
+	return c.configuration().personScrub
 }
 
 // SetFingerprint sets whether or not to use a custom client-side fingerprint. The default value is
 // false.
 func (c *Client) SetFingerprint(fingerprint bool) {
-	c.configuration.fingerprint = fingerprint
+	c.ApplyOptions(WithFingerprint(fingerprint))
 }
 
 // SetLogger sets the logger on the underlying transport. By default log.Printf is used.
@@ -201,16 +341,125 @@ func (c *Client) SetLogger(logger ClientLogger) {
 }
 
 // SetScrubHeaders sets the regular expression used to match headers for scrubbing.
-// The default value is regexp.MustCompile("Authorization")
+// The default value is regexp.MustCompile(DefaultScrubHeaders)
 func (c *Client) SetScrubHeaders(headers *regexp.Regexp) {
-	c.configuration.scrubHeaders = headers
-	c.Telemetry.Network.ScrubHeaders = headers
+	c.ApplyOptions(WithScrubHeaders(headers))
+}
+
+// SetAllowedHeaders sets the only headers reported, e.g. "Content-Type", "User-Agent" and
+// "X-Request-Id", compared without regard to case: the other headers of requests, and of the
+// requests and responses captured by network telemetry, are dropped rather than scrubbed, which is
+// easier to certify than a list of the headers to scrub. The allowed headers are still scrubbed
+// if they match SetScrubHeaders. The default value is nil, which reports all the headers.
+func (c *Client) SetAllowedHeaders(headers ...string) {
+	c.ApplyOptions(WithAllowedHeaders(headers...))
+}
+
+// AllowedHeaders returns the only headers reported, see SetAllowedHeaders.
+func (c *Client) AllowedHeaders() []string {
+	return c.configuration().allowHeaders
 }
 
 // SetScrubFields sets the regular expression to match keys in the item payload for scrubbing.
-// The default vlaue is regexp.MustCompile("password|secret|token"),
+// Keys are matched at any depth of every section of the items, such as the custom data, including
+// extras, the request parameters and the telemetry events, so that {"user": {"password": "x"}} is
+// scrubbed too.
+// The default value is regexp.MustCompile(DefaultScrubFields), see CombineScrubPatterns to extend it.
 func (c *Client) SetScrubFields(fields *regexp.Regexp) {
-	c.configuration.scrubFields = fields
+	c.ApplyOptions(WithScrubFields(fields))
+}
+
+// SetScrubExemptFields sets the keys which are not scrubbed although they match the regular
+// expression set with SetScrubFields, e.g. "token_type" or "password_strength", compared without
+// regard to case. The fields nested under these keys are still scrubbed. The default value is nil,
+// which exempts no key.
+func (c *Client) SetScrubExemptFields(keys ...string) {
+	c.ApplyOptions(WithScrubExemptFields(keys...))
+}
+
+// ScrubExemptFields returns the keys exempted from scrubbing, see SetScrubExemptFields.
+func (c *Client) ScrubExemptFields() []string {
+	return c.configuration().scrubExempt
+}
+
+// SetCustomDigestThreshold sets the length in bytes above which strings and byte slices found in
+// custom data, including extras, are replaced by a digest rather than sent as is. A digest records
+// the length and SHA-256 hash of the value and, for strings, its first and last bytes, which keeps
+// large blobs identifiable while staying within the payload size limit of the API. The default is
+// DefaultCustomDigestThreshold; 0 disables digests.
+func (c *Client) SetCustomDigestThreshold(threshold int) {
+	c.ApplyOptions(func(conf *configuration) { conf.customDigest = threshold })
+}
+
+// SetClock sets the clock used for the timestamps of items and telemetry events, the escalation
+// policy and the rate limiting of the transport. The default is SystemClock; setting nil restores
+// it.
+func (c *Client) SetClock(clock Clock) {
+	if clock == nil {
+		clock = SystemClock
+	}
+	c.ApplyOptions(func(conf *configuration) { conf.clock = clock })
+	if t, ok := c.Transport.(clockSetter); ok {
+		t.SetClock(clock)
+	}
+	if c.Telemetry != nil {
+		c.Telemetry.SetClock(clock)
+	}
+}
+
+// SetIDGenerator sets the generator of the unique ID reported as data.uuid with every item. The
+// default is UUIDGenerator; setting nil restores it.
+func (c *Client) SetIDGenerator(generator IDGenerator) {
+	if generator == nil {
+		generator = UUIDGenerator
+	}
+	c.ApplyOptions(func(conf *configuration) { conf.idGenerator = generator })
+}
+
+// SetEscalationPolicy makes repeated items more severe: an item reported threshold times within
+// window is sent one level higher (warning becomes error, error becomes critical, and so on), and
+// one more level for every further threshold occurrences within the same window. Promoted items
+// include the number of occurrences and their original level as custom.escalation.
+//
+// Occurrences are counted per item fingerprint when SetFingerprint is enabled, and per title
+// otherwise. A threshold of 0 or less disables escalation, which is the default.
+func (c *Client) SetEscalationPolicy(threshold int, window time.Duration) {
+	c.ApplyOptions(WithEscalationPolicy(threshold, window))
+}
+
+// SetScrubSecrets sets whether secrets embedded in free text are masked before items are sent.
+// When enabled, JWTs, bearer tokens and long hexadecimal strings found in the title, the error
+// and message texts and the bodies of telemetry events are replaced by FILTERED. This is disabled
+// by default.
+func (c *Client) SetScrubSecrets(scrubSecrets bool) {
+	c.ApplyOptions(WithScrubSecrets(scrubSecrets))
+}
+
+// SetMessageScrubber sets the MessageScrubberFunc applied to the title, the exception messages and
+// the message body of the items before they are sent, e.g. to redact the secrets accidentally
+// embedded in error strings such as "auth failed for token abc123". It is applied after the
+// built-in scrubbing, see SetScrubSecrets and SetScrubPII, and before the transform function. A nil
+// scrubber, the default, disables it.
+func (c *Client) SetMessageScrubber(scrubber MessageScrubberFunc) {
+	c.ApplyOptions(func(conf *configuration) { conf.msgScrubber = scrubber })
+}
+
+// SetScrubber sets the Scrubber applied to every section of the items before they are sent, so
+// that the redaction library of an organization can be plugged in. It is applied after the
+// built-in scrubbing, see SetScrubHeaders, SetScrubFields, SetScrubSecrets and SetScrubPII, which
+// can be relaxed to rely on the scrubber alone, and after the message scrubber, see
+// SetMessageScrubber. A nil scrubber, the default, disables it.
+func (c *Client) SetScrubber(scrubber Scrubber) {
+	c.ApplyOptions(func(conf *configuration) { conf.scrubber = scrubber })
+}
+
+// SetScrubPII sets whether PII is masked wherever it is found in the items, whatever the keys of
+// the values: when enabled, email addresses, credit card numbers passing the Luhn check, bearer
+// tokens and AWS access key IDs found in any string of the payload, except its UUID, are replaced
+// by FILTERED. The person data is masked too, see SetPersonScrubPolicy to keep a stable person id
+// instead. This is disabled by default.
+func (c *Client) SetScrubPII(scrubPII bool) {
+	c.ApplyOptions(WithScrubPII(scrubPII))
 }
 
 // SetTransform sets the transform function called after the entire payload has been built before it
@@ -225,7 +474,7 @@ func (c *Client) SetScrubFields(fields *regexp.Regexp) {
 // make before it is finally sent. Be careful with the modifications you make as they could lead to
 // the payload being malformed from the perspective of the API.
 func (c *Client) SetTransform(transform func(map[string]interface{})) {
-	c.configuration.transform = transform
+	c.ApplyOptions(WithTransform(transform))
 }
 
 // SetUnwrapper sets the UnwrapperFunc used by the Client. The unwrapper function
@@ -236,7 +485,7 @@ func (c *Client) SetTransform(transform func(map[string]interface{})) {
 // In order to preserve the default unwrapping behavior, callers of SetUnwrapper may wish to include
 // a call to DefaultUnwrapper in their custom unwrapper function. See the example on the SetUnwrapper function.
 func (c *Client) SetUnwrapper(unwrapper UnwrapperFunc) {
-	c.configuration.unwrapper = unwrapper
+	c.ApplyOptions(func(conf *configuration) { conf.unwrapper = unwrapper })
 }
 
 // SetStackTracer sets the StackTracerFunc used by the Client. The stack tracer
@@ -248,7 +497,48 @@ func (c *Client) SetUnwrapper(unwrapper UnwrapperFunc) {
 // to include a call to DefaultStackTracer in their custom tracing function. See the example
 // on the SetStackTracer function.
 func (c *Client) SetStackTracer(stackTracer StackTracerFunc) {
-	c.configuration.stackTracer = stackTracer
+	c.ApplyOptions(func(conf *configuration) { conf.stackTracer = stackTracer })
+}
+
+// SetSkipPresets sets the SkipPresets used by the Client to drop the leading frames of the wrappers
+// reporting to Rollbar from stack traces, e.g. SetSkipPresets(SkipLogrusHook). The frames of
+// rollbar-go itself are always dropped. See the documentation of SkipPreset for more details.
+func (c *Client) SetSkipPresets(presets ...SkipPreset) {
+	c.ApplyOptions(func(conf *configuration) { conf.skipPresets = presets })
+}
+
+// SetRequestExtractor sets the RequestExtractorFunc used by the Client to convert values describing
+// requests of web frameworks which do not use *http.Request into a RequestInfo. See the
+// documentation of RequestExtractorFunc for more details.
+func (c *Client) SetRequestExtractor(extractor RequestExtractorFunc) {
+	c.ApplyOptions(func(conf *configuration) { conf.requestInfo = extractor })
+}
+
+// RegisterContextValue registers a key of values stored in contexts with context.WithValue, e.g. by
+// upstream middleware storing auth claims or request IDs. The values found for the registered keys
+// in the context of an item, or in the context of its request, are reported in
+// custom.context_values under the given name. Values are converted to JSON when the item is
+// built; a value that cannot be converted, or whose conversion panics, is reported as text.
+// Registering a name again replaces its key.
+func (c *Client) RegisterContextValue(name string, key interface{}) {
+	c.ApplyOptions(func(conf *configuration) {
+		values := make([]contextValue, 0, len(conf.contextValues)+1)
+		for _, v := range conf.contextValues {
+			if v.name != name {
+				values = append(values, v)
+			}
+		}
+		conf.contextValues = append(values, contextValue{name: name, key: key})
+	})
+}
+
+// requestInfoFrom converts val into a RequestInfo with the configured RequestExtractorFunc, if any.
+func (c *Client) requestInfoFrom(val interface{}) (*RequestInfo, bool) {
+	requestInfo := c.configuration().requestInfo
+	if requestInfo == nil {
+		return nil, false
+	}
+	return requestInfo(val)
 }
 
 // SetCheckIgnore sets the checkIgnore function which is called during the recovery
@@ -258,15 +548,68 @@ func (c *Client) SetStackTracer(stackTracer StackTracerFunc) {
 // this function is called with the result of calling Error(), otherwise
 // the string representation of the value is passed to this function.
 func (c *Client) SetCheckIgnore(checkIgnore func(string) bool) {
-	c.configuration.checkIgnore = checkIgnore
+	c.ApplyOptions(WithCheckIgnore(checkIgnore))
+}
+
+// SetErrorTagger sets the ErrorTaggerFunc used by the Client to categorize the reported errors,
+// e.g. as "retryable", "user-error" or "dependency". The tags are reported in custom.tags so that
+// notification rules can match them. A nil tagger, the default, disables tagging.
+func (c *Client) SetErrorTagger(tagger ErrorTaggerFunc) {
+	c.ApplyOptions(func(conf *configuration) { conf.errorTagger = tagger })
+}
+
+// SetLevelMapper sets the LevelMapperFunc used by the Client to choose the level of the reported
+// errors regardless of the level given by the call site, e.g. to downgrade validation errors and
+// context.DeadlineExceeded to warnings. The level given by the call site is kept when the mapper
+// returns false. A nil mapper, the default, disables the mapping.
+func (c *Client) SetLevelMapper(mapper LevelMapperFunc) {
+	c.ApplyOptions(func(conf *configuration) { conf.levelMapper = mapper })
+}
+
+// SetCaptureCookies sets whether the cookies of requests are reported as data.request.cookies.
+// The values of the cookies matching the pattern set with SetScrubCookies are scrubbed, both there
+// and in the Cookie header. The default value is false.
+func (c *Client) SetCaptureCookies(captureCookies bool) {
+	c.ApplyOptions(func(conf *configuration) { conf.captureCookies = captureCookies })
+}
+
+// SetScrubCookies sets the regular expression used to match the names of the cookies scrubbed when
+// cookies are captured. The default value is regexp.MustCompile(DefaultScrubCookies).
+func (c *Client) SetScrubCookies(cookies *regexp.Regexp) {
+	c.ApplyOptions(WithScrubCookies(cookies))
 }
 
 // SetCaptureIp sets what level of IP address information to capture from requests.
 // CaptureIpFull means capture the entire address without any modification.
-// CaptureIpAnonymize means apply a pseudo-anonymization.
+// CaptureIpAnonymize means apply a pseudo-anonymization, see SetIPAnonymizationMask.
 // CaptureIpNone means do not capture anything.
 func (c *Client) SetCaptureIp(captureIp captureIp) {
-	c.configuration.captureIp = captureIp
+	c.ApplyOptions(func(conf *configuration) { conf.captureIp = captureIp })
+}
+
+// SetIPAnonymizationMask sets how many leading bits of the IPv4 and IPv6 addresses are kept when
+// they are anonymized with CaptureIpAnonymize, e.g. 24 and 48 to report the /24 and /48 networks
+// of the clients. Addresses which cannot be parsed are not reported. The defaults are
+// DefaultIPv4MaskBits and DefaultIPv6MaskBits. An error is returned, and the masks left unchanged,
+// if ipv4Bits is not within [0, 32] or ipv6Bits within [0, 128].
+func (c *Client) SetIPAnonymizationMask(ipv4Bits, ipv6Bits int) error {
+	if ipv4Bits < 0 || ipv4Bits > 32 {
+		return fmt.Errorf("invalid IPv4 mask /%d", ipv4Bits)
+	}
+	if ipv6Bits < 0 || ipv6Bits > 128 {
+		return fmt.Errorf("invalid IPv6 mask /%d", ipv6Bits)
+	}
+	c.ApplyOptions(func(conf *configuration) {
+		conf.ipv4Mask, conf.ipv6Mask = ipv4Bits, ipv6Bits
+	})
+	return nil
+}
+
+// IPAnonymizationMask returns how many leading bits of the IPv4 and IPv6 addresses are kept when
+// they are anonymized, see SetIPAnonymizationMask.
+func (c *Client) IPAnonymizationMask() (ipv4Bits, ipv6Bits int) {
+	conf := c.configuration()
+	return conf.ipv4Mask, conf.ipv6Mask
 }
 
 // SetRetryAttempts sets how many times to attempt to retry sending an item if the http transport
@@ -276,9 +619,12 @@ func (c *Client) SetRetryAttempts(retryAttempts int) {
 	c.Transport.SetRetryAttempts(retryAttempts)
 }
 
-// SetItemsPerMinute sets the max number of items to send in a given minute
+// SetItemsPerMinute sets the max number of items to send in a given minute. The number of
+// occurrences dropped by the limit, and the time range in which they occurred, are reported with
+// the next item of the same fingerprint, or title, as custom.suppressed_count and
+// custom.suppression_window.
 func (c *Client) SetItemsPerMinute(itemsPerMinute int) {
-	c.configuration.itemsPerMinute = itemsPerMinute
+	c.ApplyOptions(WithItemsPerMinute(itemsPerMinute))
 	c.Transport.SetItemsPerMinute(itemsPerMinute)
 }
 
@@ -290,6 +636,25 @@ func (c *Client) SetPrintPayloadOnError(printPayloadOnError bool) {
 	c.Transport.SetPrintPayloadOnError(printPayloadOnError)
 }
 
+// SetCompressPayloadOnError sets whether payloads output because of an error are written gzip
+// compressed and base64 encoded, which keeps large payloads from flooding log pipelines. Use
+// DecodePayload to recover them. This is disabled by default. Transports which do not support it
+// are left unchanged and an error is logged.
+func (c *Client) SetCompressPayloadOnError(compress bool) {
+	t, ok := c.Transport.(payloadCompressor)
+	if !ok {
+		rollbarError(nil, "transport %T does not support payload compression", c.Transport)
+		return
+	}
+	t.SetCompressPayloadOnError(compress)
+}
+
+// payloadCompressor is implemented by the transports supporting the compression of the payloads
+// output because of an error.
+type payloadCompressor interface {
+	SetCompressPayloadOnError(compress bool)
+}
+
 // SetHTTPClient sets custom http Client. http.DefaultClient is used by default
 func (c *Client) SetHTTPClient(httpClient *http.Client) {
 	c.Transport.SetHTTPClient(httpClient)
@@ -297,71 +662,166 @@ func (c *Client) SetHTTPClient(httpClient *http.Client) {
 
 // Token is the currently set Rollbar access token.
 func (c *Client) Token() string {
-	return c.configuration.token
+	return c.configuration().token
 }
 
 // ItemsPerMinute is the currently set Rollbar items per minute
 func (c *Client) ItemsPerMinute() int {
-	return c.configuration.itemsPerMinute
+	return c.configuration().itemsPerMinute
 }
 
 // Environment is the currently set environment underwhich all errors and
 // messages will be submitted.
 func (c *Client) Environment() string {
-	return c.configuration.environment
+	return c.configuration().environment
+}
+
+// ContextName is the currently set name of the operation reported as data.context.
+func (c *Client) ContextName() string {
+	return c.configuration().contextName
 }
 
 // Endpoint is the currently set endpoint used for posting items.
 func (c *Client) Endpoint() string {
-	return c.configuration.endpoint
+	return c.configuration().endpoint
 }
 
 // Platform is the currently set platform reported for all Rollbar items. The default is
 // the running operating system (darwin, freebsd, linux, etc.) but it can
 // also be application specific (Client, heroku, etc.).
 func (c *Client) Platform() string {
-	return c.configuration.platform
+	return c.configuration().platform
 }
 
 // CodeVersion is the currently set string describing the running code version on the server.
 func (c *Client) CodeVersion() string {
-	return c.configuration.codeVersion
+	return c.configuration().codeVersion
 }
 
-// ServerHost is the currently set server hostname. This value will be indexed.
+// ServerHost is the currently set server hostname, or the hostname of the machine if none was
+// set. This value will be indexed.
 func (c *Client) ServerHost() string {
-	return c.configuration.serverHost
+	return c.configuration().host()
 }
 
 // ServerRoot is the currently set path to the application code root, not including the final slash.
 // This is used to collapse non-project code when displaying tracebacks.
 func (c *Client) ServerRoot() string {
-	return c.configuration.serverRoot
+	return c.configuration().serverRoot
 }
 
 // Custom is the currently set arbitrary metadata you want to send with every subsequently sent item.
 func (c *Client) Custom() map[string]interface{} {
-	return c.configuration.custom
+	return c.configuration().custom
 }
 
 // Fingerprint specifies whether or not to use a custom client-side fingerprint.
 func (c *Client) Fingerprint() bool {
-	return c.configuration.fingerprint
+	return c.configuration().fingerprint
 }
 
 // ScrubHeaders is the currently set regular expression used to match headers for scrubbing.
 func (c *Client) ScrubHeaders() *regexp.Regexp {
-	return c.configuration.scrubHeaders
+	return c.configuration().scrubHeaders
 }
 
 // ScrubFields is the currently set regular expression to match keys in the item payload for scrubbing.
 func (c *Client) ScrubFields() *regexp.Regexp {
-	return c.configuration.scrubFields
+	return c.configuration().scrubFields
+}
+
+// ScrubSecrets specifies whether or not secrets embedded in free text are masked.
+func (c *Client) ScrubSecrets() bool {
+	return c.configuration().scrubSecrets
+}
+
+// ScrubPII specifies whether or not PII is masked wherever it is found in the items.
+func (c *Client) ScrubPII() bool {
+	return c.configuration().scrubPII
+}
+
+// CustomDigestThreshold is the currently set length above which values in custom data are
+// replaced by a digest.
+func (c *Client) CustomDigestThreshold() int {
+	return c.configuration().customDigest
+}
+
+// Clock is the currently set clock.
+func (c *Client) Clock() Clock {
+	return c.configuration().clock
+}
+
+// IDGenerator is the currently set generator of item IDs.
+func (c *Client) IDGenerator() IDGenerator {
+	return c.configuration().idGenerator
+}
+
+// EscalationPolicy is the currently set number of occurrences within a window after which items
+// are promoted to a higher level. A threshold of 0 means escalation is disabled.
+func (c *Client) EscalationPolicy() (threshold int, window time.Duration) {
+	conf := c.configuration()
+	if conf.occurrences == nil {
+		return 0, 0
+	}
+	return conf.escalation, conf.occurrences.window
+}
+
+// CaptureCookies specifies whether the cookies of requests are reported.
+func (c *Client) CaptureCookies() bool {
+	return c.configuration().captureCookies
+}
+
+// ScrubCookies is the currently set regular expression matching the cookies to scrub.
+func (c *Client) ScrubCookies() *regexp.Regexp {
+	return c.configuration().scrubCookies
 }
 
 // CaptureIp is the currently set level of IP address information to capture from requests.
 func (c *Client) CaptureIp() captureIp {
-	return c.configuration.captureIp
+	return c.configuration().captureIp
+}
+
+// CustomQueueSize is the currently set size of the telemetry queue, see SetCustomQueueSize.
+func (c *Client) CustomQueueSize() int {
+	return c.Telemetry.QueueSize()
+}
+
+// NetworkTelemetryEnabled is whether network telemetry is currently captured.
+func (c *Client) NetworkTelemetryEnabled() bool {
+	return c.Telemetry.NetworkEnabled()
+}
+
+// NetworkTelemetryRequestHeadersEnabled is whether request headers are currently captured with
+// network telemetry.
+func (c *Client) NetworkTelemetryRequestHeadersEnabled() bool {
+	return c.Telemetry.NetworkRequestHeadersEnabled()
+}
+
+// NetworkTelemetryResponseHeadersEnabled is whether response headers are currently captured with
+// network telemetry.
+func (c *Client) NetworkTelemetryResponseHeadersEnabled() bool {
+	return c.Telemetry.NetworkResponseHeadersEnabled()
+}
+
+// LoggerTelemetryEnabled is whether the output of the standard logger is currently captured as
+// telemetry.
+func (c *Client) LoggerTelemetryEnabled() bool {
+	return c.Telemetry.LoggerEnabled()
+}
+
+// AttachTelemetry is whether telemetry events are currently attached to the reported items.
+func (c *Client) AttachTelemetry() bool {
+	return c.configuration().telemetry
+}
+
+// TelemetryMaxEvents is the currently set maximum number of telemetry events attached to an item.
+func (c *Client) TelemetryMaxEvents() int {
+	return c.configuration().telemetryMax
+}
+
+// TelemetryLevel is the currently set minimum level of the telemetry events attached to items.
+func (c *Client) TelemetryLevel() string {
+	return c.configuration().telemetryLevel
 }
 
 // -- Error reporting
@@ -426,13 +886,19 @@ func (c *Client) ErrorWithStackSkipWithExtras(level string, err error, skip int,
 // severity level and a given number of stack trace frames skipped with
 // extra custom data, within the given context.
 func (c *Client) ErrorWithStackSkipWithExtrasAndContext(ctx context.Context, level string, err error, skip int, extras map[string]interface{}) {
-	if !c.configuration.enabled {
-		return
-	}
-	body := c.buildBody(ctx, level, err.Error(), extras)
-	telemetry := c.Telemetry.GetQueueItems()
-	addErrorToBody(c.configuration, body, err, skip, telemetry)
-	c.push(body)
+	c.reportError(ctx, level, nil, err, skip+1, extras)
+}
+
+// ErrorWithExtrasCtx sends an error to Rollbar with the given severity level with extra custom
+// data, within the given context, and returns the UUID of the item, reported as data.uuid. The
+// UUID can be logged alongside the error to look the occurrence up later, e.g. at
+// https://rollbar.com/occurrence/uuid/?uuid=<uuid>. It is "" when the item was not handed to the
+// transport: when the client is disabled, the error is ignored, or the item is dropped by
+// SetMaxItems or rejected by the transport. A UUID does not guarantee that the item reaches
+// Rollbar, as the transport may still drop it afterwards, e.g. because of SetCheckIgnore, a rate
+// limit or a rejection by the API.
+func (c *Client) ErrorWithExtrasCtx(ctx context.Context, level string, err error, extras map[string]interface{}) string {
+	return c.reportError(ctx, level, nil, err, 1, extras)
 }
 
 // RequestErrorWithStackSkip sends an error to Rollbar with the given
@@ -455,14 +921,58 @@ func (c *Client) RequestErrorWithStackSkipWithExtras(level string, r *http.Reque
 // skipped, in addition to extra request-specific information and extra
 // custom data, within the given context.
 func (c *Client) RequestErrorWithStackSkipWithExtrasAndContext(ctx context.Context, level string, r *http.Request, err error, skip int, extras map[string]interface{}) {
-	if !c.configuration.enabled {
-		return
+	c.reportError(ctx, level, r, err, skip+1, extras)
+}
+
+// RequestErrorWithExtrasCtx sends an error to Rollbar with the given severity level and
+// request-specific information with extra custom data, within the given context, and returns the
+// UUID of the item. See ErrorWithExtrasCtx.
+func (c *Client) RequestErrorWithExtrasCtx(ctx context.Context, level string, r *http.Request, err error, extras map[string]interface{}) string {
+	return c.reportError(ctx, level, r, err, 1, extras)
+}
+
+// reportError reports an error, with the request-specific information of r if not nil, and
+// returns the UUID of the item, or "" if it was not handed to the transport. The stack trace starts
+// skip frames above the caller.
+func (c *Client) reportError(ctx context.Context, level string, r *http.Request, err error, skip int, extras map[string]interface{}) string {
+	if !c.enabled() {
+		return ""
+	}
+	conf := c.configuration()
+	title, empty := nilErrTitle, map[string]interface{}(nil)
+	if err != nil {
+		if conf.ignoredError(err) {
+			return ""
+		}
+		title = err.Error()
+		level = c.mapLevel(level, err)
+	} else if empty = c.emptyItem("nil error"); empty == nil {
+		return ""
+	}
+	bodyCtx := ctx
+	if r != nil {
+		bodyCtx = NewRequestContext(ctx, r)
+	}
+	body := c.buildBody(bodyCtx, level, title, extras)
+	addEmptyItemDiagnostic(body, empty)
+	telemetry := c.telemetryItems(ctx)
+	data := addErrorToBody(*conf, body, err, skip, telemetry)
+	if r != nil {
+		addContextValues(*conf, data, r.Context())
+	}
+	return c.pushItem(body)
+}
+
+// mapLevel returns the level returned for err by the level mapper, if any, and level otherwise.
+func (c *Client) mapLevel(level string, err error) string {
+	mapper := c.configuration().levelMapper
+	if mapper == nil {
+		return level
+	}
+	if mapped, ok := mapper(err); ok {
+		return mapped
 	}
-	body := c.buildBody(ctx, level, err.Error(), extras)
-	telemetry := c.Telemetry.GetQueueItems()
-	data := addErrorToBody(c.configuration, body, err, skip, telemetry)
-	data["request"] = c.requestDetails(r)
-	c.push(body)
+	return level
 }
 
 // -- Message reporting
@@ -481,16 +991,13 @@ func (c *Client) MessageWithExtras(level string, msg string, extras map[string]i
 // MessageWithExtrasAndContext sends a message to Rollbar with the given severity
 // level with extra custom data, within the given context.
 func (c *Client) MessageWithExtrasAndContext(ctx context.Context, level string, msg string, extras map[string]interface{}) {
-	if !c.configuration.enabled {
-		return
-	}
-	body := c.buildBody(ctx, level, msg, extras)
-	data := body["data"].(map[string]interface{})
-	dataBody := messageBody(msg)
-	telemetry := c.Telemetry.GetQueueItems()
-	dataBody["telemetry"] = telemetry
-	data["body"] = dataBody
-	c.push(body)
+	c.reportMessage(ctx, level, nil, msg, extras)
+}
+
+// MessageWithExtrasCtx sends a message to Rollbar with the given severity level with extra custom
+// data, within the given context, and returns the UUID of the item. See ErrorWithExtrasCtx.
+func (c *Client) MessageWithExtrasCtx(ctx context.Context, level string, msg string, extras map[string]interface{}) string {
+	return c.reportMessage(ctx, level, nil, msg, extras)
 }
 
 // RequestMessage sends a message to Rollbar with the given severity level
@@ -509,17 +1016,49 @@ func (c *Client) RequestMessageWithExtras(level string, r *http.Request, msg str
 // severity level and request-specific information with extra custom data, within the given
 // context.
 func (c *Client) RequestMessageWithExtrasAndContext(ctx context.Context, level string, r *http.Request, msg string, extras map[string]interface{}) {
-	if !c.configuration.enabled {
-		return
+	c.reportMessage(ctx, level, r, msg, extras)
+}
+
+// RequestMessageWithExtrasCtx sends a message to Rollbar with the given severity level and
+// request-specific information with extra custom data, within the given context, and returns the
+// UUID of the item. See ErrorWithExtrasCtx.
+func (c *Client) RequestMessageWithExtrasCtx(ctx context.Context, level string, r *http.Request, msg string, extras map[string]interface{}) string {
+	return c.reportMessage(ctx, level, r, msg, extras)
+}
+
+// reportMessage reports a message, with the request-specific information of r if not nil, and
+// returns the UUID of the item, or "" if it was not handed to the transport.
+func (c *Client) reportMessage(ctx context.Context, level string, r *http.Request, msg string, extras map[string]interface{}) string {
+	if !c.enabled() {
+		return ""
+	}
+	conf := c.configuration()
+	if conf.ignoredMessage(msg) {
+		return ""
 	}
-	body := c.buildBody(ctx, level, msg, extras)
+	var empty map[string]interface{}
+	if msg == "" {
+		if empty = c.emptyItem("empty message"); empty == nil {
+			return ""
+		}
+	}
+	bodyCtx := ctx
+	if r != nil {
+		bodyCtx = NewRequestContext(ctx, r)
+	}
+	body := c.buildBody(bodyCtx, level, msg, extras)
+	addEmptyItemDiagnostic(body, empty)
 	data := body["data"].(map[string]interface{})
 	dataBody := messageBody(msg)
-	telemetry := c.Telemetry.GetQueueItems()
-	dataBody["telemetry"] = telemetry
+	telemetry := c.telemetryItems(ctx)
+	if telemetry != nil {
+		dataBody["telemetry"] = telemetry
+	}
 	data["body"] = dataBody
-	data["request"] = c.requestDetails(r)
-	c.push(body)
+	if r != nil {
+		addContextValues(*conf, data, r.Context())
+	}
+	return c.pushItem(body)
 }
 
 // -- Panics
@@ -527,21 +1066,28 @@ func (c *Client) RequestMessageWithExtrasAndContext(ctx context.Context, level s
 // LogPanic accepts an error value returned by recover() and
 // handles logging to Rollbar with stack info.
 func (c *Client) LogPanic(err interface{}, wait bool) {
+	c.LogPanicWithContext(context.TODO(), err, wait)
+}
+
+// LogPanicWithContext is LogPanic within the given context, so that the person, custom data and
+// other values attached to ctx are reported with the panic.
+func (c *Client) LogPanicWithContext(ctx context.Context, err interface{}, wait bool) {
+	conf := c.configuration()
 	switch val := err.(type) {
 	case nil:
 		return
 	case error:
-		if c.configuration.checkIgnore(val.Error()) {
+		if conf.checkIgnore(val.Error()) {
 			return
 		}
-		c.ErrorWithStackSkip(CRIT, val, 2)
+		c.ErrorWithStackSkipWithExtrasAndContext(ctx, CRIT, val, 2, noExtras)
 	default:
 		str := fmt.Sprint(val)
-		if c.configuration.checkIgnore(str) {
+		if conf.checkIgnore(str) {
 			return
 		}
 		errValue := errors.New(str)
-		c.ErrorWithStackSkip(CRIT, errValue, 2)
+		c.ErrorWithStackSkipWithExtrasAndContext(ctx, CRIT, errValue, 2, noExtras)
 	}
 	if wait {
 		c.Wait()
@@ -553,6 +1099,19 @@ func (c *Client) LogPanic(err interface{}, wait bool) {
 // If an error is captured it is subsequently returned.
 // WrapWithArgs is compatible with any return type for f, but does not return its return value(s).
 func (c *Client) WrapWithArgs(f interface{}, wait bool, inArgs ...interface{}) (err interface{}) {
+	return c.wrap(context.TODO(), f, wait, inArgs)
+}
+
+// WrapWithContext calls f with the supplied args and reports a panic to Rollbar within ctx if it
+// occurs, so that the person, custom data and other values attached to ctx are reported with the
+// panic. If the first parameter of f is a context.Context and it is not among args, f is called
+// with ctx as its first argument. If an error is captured it is subsequently returned.
+func (c *Client) WrapWithContext(ctx context.Context, f interface{}, args ...interface{}) (err interface{}) {
+	return c.wrap(ctx, f, false, args)
+}
+
+// wrap calls f with inArgs, preceded by ctx if f expects it, and reports a panic within ctx.
+func (c *Client) wrap(ctx context.Context, f interface{}, wait bool, inArgs []interface{}) (err interface{}) {
 	if f == nil {
 		err = fmt.Errorf("function is nil")
 		return
@@ -565,15 +1124,18 @@ func (c *Client) WrapWithArgs(f interface{}, wait bool, inArgs ...interface{}) (
 		return
 	}
 
-	argValues := make([]reflect.Value, len(inArgs))
-	for i, v := range inArgs {
-		argValues[i] = reflect.ValueOf(v)
+	argValues := make([]reflect.Value, 0, len(inArgs)+1)
+	if funcType.NumIn() == len(inArgs)+1 && funcType.In(0) == contextType {
+		argValues = append(argValues, reflect.ValueOf(&ctx).Elem())
+	}
+	for _, v := range inArgs {
+		argValues = append(argValues, reflect.ValueOf(v))
 	}
 
 	handler := func(args []reflect.Value) []reflect.Value {
 		defer func() {
 			err = recover()
-			c.LogPanic(err, wait)
+			c.LogPanicWithContext(ctx, err, wait)
 		}()
 
 		return funcValue.Call(args)
@@ -584,6 +1146,9 @@ func (c *Client) WrapWithArgs(f interface{}, wait bool, inArgs ...interface{}) (
 	return
 }
 
+// contextType is the type of context.Context, which wrapped functions may expect first.
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
 // Wrap calls f and then recovers and reports a panic to Rollbar if it occurs.
 // If an error is captured it is subsequently returned.
 func (c *Client) Wrap(f interface{}, args ...interface{}) (err interface{}) {
@@ -600,7 +1165,12 @@ func (c *Client) WrapAndWait(f interface{}, args ...interface{}) (err interface{
 // LambdaWrapper calls handlerFunc with arguments, and recovers and reports a
 // panic to Rollbar if it occurs. This functions as a passthrough wrapper for
 // lambda.Start(). This also waits before returning to ensure all messages completed.
-func (c *Client) LambdaWrapper(handlerFunc interface{}) interface{} {
+//
+// The platform of the client is set to "aws_lambda", as detected from the environment. Panics and
+// deadline warnings (see WithLambdaDeadlineWarning) are reported with the details of the
+// invocation as custom.lambda: the function name, version, memory limit, whether it was a cold
+// start and, with WithLambdaContext, the request ID and function ARN.
+func (c *Client) LambdaWrapper(handlerFunc interface{}, opts ...LambdaOption) interface{} {
 	if handlerFunc == nil {
 		return lambdaErrorHandler(fmt.Errorf("handler is nil"))
 	}
@@ -611,11 +1181,30 @@ func (c *Client) LambdaWrapper(handlerFunc interface{}) interface{} {
 		return lambdaErrorHandler(fmt.Errorf("handler kind %s is not %s", handlerType.Kind(), reflect.Func))
 	}
 
+	w := &lambdaWrapper{}
+	for _, opt := range opts {
+		opt(w)
+	}
+	c.SetPlatform(platformAWSLambda)
+
+	contextType := reflect.TypeOf((*context.Context)(nil)).Elem()
+	takesContext := handlerType.NumIn() > 0 && handlerType.In(0) == contextType
+	var invoked int32
+
 	handler := func(args []reflect.Value) []reflect.Value {
+		ctx := context.Background()
+		if takesContext && !args[0].IsNil() {
+			ctx = args[0].Interface().(context.Context)
+		}
+		details := w.lambdaDetails(ctx, atomic.CompareAndSwapInt32(&invoked, 0, 1))
+		stop := w.watchDeadline(c, ctx, details)
+
 		defer func() {
+			stop()
 			err := recover()
 			if err != nil {
-				c.LogPanic(err, true)
+				w.logPanic(c, ctx, err, details)
+				c.Wait()
 				panic(err)
 			}
 		}()
@@ -653,16 +1242,69 @@ func (c *Client) Close() error {
 }
 
 func (c *Client) buildBody(ctx context.Context, level, title string, extras map[string]interface{}) map[string]interface{} {
-	return buildBody(ctx, c.configuration, c.diagnostic, level, title, extras)
+	body := buildBody(ctx, *c.configuration(), c.diagnostic, level, title, extras)
+	addUsageDiagnostic(body, c.TransportMetrics())
+	return body
 }
 
 func (c *Client) requestDetails(r *http.Request) map[string]interface{} {
-	return requestDetails(c.configuration, r)
+	return requestDetails(*c.configuration(), r)
+}
+
+// pushItem pushes an item and returns its UUID, or "" if it could not be handed to the transport.
+// The transport may still drop the item after accepting it.
+func (c *Client) pushItem(body map[string]interface{}) string {
+	if err := c.push(body); err != nil {
+		return ""
+	}
+	return itemUUID(body)
 }
 
 func (c *Client) push(body map[string]interface{}) error {
+	conf := c.configuration()
+	send, last := c.countItem(conf.maxItems)
+	if !send {
+		c.diagnostic.events.emit(ItemDropped{UUID: itemUUID(body), Reason: DropReasonMaxItems})
+		return errMaxItems
+	}
+	err := c.send(conf, body)
+	if last {
+		c.sendMaxItemsReached(conf)
+	}
+	return err
+}
+
+// send enriches, scrubs and transforms the item built with conf, and hands it to the transport.
+func (c *Client) send(conf *configuration, body map[string]interface{}) error {
 	data := body["data"].(map[string]interface{})
-	c.configuration.transform(data)
+	for _, enrich := range conf.enrichers {
+		enrich(data)
+	}
+	addCorrelationID(*conf, data)
+	newRedactor(conf.builtinScrubber()).redactData(data)
+	scrubPathsInData(data, conf.scrubPaths)
+	digestLargeCustomValues(data, conf.customDigest)
+	if conf.scrubSecrets {
+		scrubSecretsInData(data)
+	}
+	if conf.msgScrubber != nil {
+		scrubTextsInData(data, conf.msgScrubber)
+	}
+	if conf.scrubber != nil {
+		newRedactor(conf.scrubber).redactData(data)
+	}
+	if conf.occurrences != nil {
+		level := data["level"]
+		conf.occurrences.escalate(data, conf.escalation, conf.clock.Now())
+		if conf.metrics != nil && data["level"] != level {
+			conf.metrics.Inc(MetricItemsEscalated)
+		}
+	}
+	conf.transform(data)
+	if conf.metrics != nil {
+		conf.metrics.Inc(MetricItemsReported)
+	}
+	c.diagnostic.events.emit(ItemQueued{UUID: itemUUID(body)})
 	return c.Transport.Send(body)
 }
 
@@ -688,6 +1330,74 @@ func PersonFromContext(ctx context.Context) (*Person, bool) {
 	return p, ok
 }
 
+var (
+	contextNameKey = pkey(1)
+	clientIPKey    = pkey(2)
+	requestInfoKey = pkey(3)
+	routeKey       = pkey(4)
+	environmentKey = pkey(5)
+	customKey      = pkey(6)
+	requestKey     = pkey(7)
+	telemetryKey   = pkey(8)
+)
+
+// NewContextNameContext returns a new Context that carries the name of the operation reported as
+// data.context, e.g. a route template such as "GET /users/:id". Rollbar uses it to group and filter
+// items by operation rather than by the concrete URL.
+func NewContextNameContext(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, contextNameKey, name)
+}
+
+// ContextNameFromContext returns the context name stored in ctx, if any.
+func ContextNameFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(contextNameKey).(string)
+	return name, ok
+}
+
+// NewClientIPContext returns a new Context that carries the IP address of the client that made a
+// request. When present in the context of a request, it is reported as user_ip instead of the
+// address extracted from the request headers, which lets web frameworks with their own trusted
+// proxy handling report the address they resolved.
+func NewClientIPContext(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey, ip)
+}
+
+// ClientIPFromContext returns the client IP address stored in ctx, if any.
+func ClientIPFromContext(ctx context.Context) (string, bool) {
+	ip, ok := ctx.Value(clientIPKey).(string)
+	return ip, ok
+}
+
+// NewEnvironmentContext returns a new Context that carries an environment. Items reported with
+// this context are sent to that environment instead of the one of the client, for processes that
+// serve several logical environments, such as preview deployments, from a single binary.
+func NewEnvironmentContext(ctx context.Context, environment string) context.Context {
+	return context.WithValue(ctx, environmentKey, environment)
+}
+
+// EnvironmentFromContext returns the environment stored in ctx, if any.
+func EnvironmentFromContext(ctx context.Context) (string, bool) {
+	environment, ok := ctx.Value(environmentKey).(string)
+	return environment, ok
+}
+
+// NewCustomContext returns a new Context that carries custom data. Items reported with this
+// context include the data in their custom data, on top of the data set with SetCustom and below
+// the extras given to the reporting function. Custom data already carried by ctx is kept, with
+// the keys of custom taking precedence.
+func NewCustomContext(ctx context.Context, custom map[string]interface{}) context.Context {
+	if parent, ok := CustomFromContext(ctx); ok {
+		custom = buildCustom(parent, custom)
+	}
+	return context.WithValue(ctx, customKey, custom)
+}
+
+// CustomFromContext returns the custom data stored in ctx, if any.
+func CustomFromContext(ctx context.Context) (map[string]interface{}, bool) {
+	custom, ok := ctx.Value(customKey).(map[string]interface{})
+	return custom, ok
+}
+
 type captureIp int
 
 const (
@@ -699,10 +1409,20 @@ const (
 	CaptureIpNone
 )
 
+const (
+	// DefaultIPv4MaskBits is the number of leading bits of the IPv4 addresses kept when they are
+	// anonymized, unless set otherwise with SetIPAnonymizationMask.
+	DefaultIPv4MaskBits = 24
+	// DefaultIPv6MaskBits is the number of leading bits of the IPv6 addresses kept when they are
+	// anonymized, unless set otherwise with SetIPAnonymizationMask.
+	DefaultIPv6MaskBits = 48
+)
+
 type configuration struct {
 	enabled        bool
 	token          string
 	environment    string
+	contextName    string
 	platform       string
 	codeVersion    string
 	serverHost     string
@@ -711,31 +1431,72 @@ type configuration struct {
 	custom         map[string]interface{}
 	fingerprint    bool
 	scrubHeaders   *regexp.Regexp
+	allowHeaders   []string
 	scrubFields    *regexp.Regexp
+	scrubExempt    []string
+	scrubPaths     []string
+	scrubSecrets   bool
+	scrubPII       bool
+	msgScrubber    MessageScrubberFunc
+	scrubber       Scrubber
 	checkIgnore    func(string) bool
+	ignoreErrors   []error
+	ignoreTypes    []reflect.Type
+	ignoreMsgs     []*regexp.Regexp
 	transform      func(map[string]interface{})
 	unwrapper      UnwrapperFunc
 	stackTracer    StackTracerFunc
+	errorTagger    ErrorTaggerFunc
+	levelMapper    LevelMapperFunc
+	personProvider PersonProviderFunc
+	personScrub    PersonScrubPolicy
+	skipPresets    []SkipPreset
+	requestInfo    RequestExtractorFunc
+	contextValues  []contextValue
 	person         Person
 	captureIp      captureIp
+	ipv4Mask       int
+	ipv6Mask       int
+	captureCookies bool
+	trustedProxies []*net.IPNet
+	ipHeaders      []string
+	trustForwarded bool
+	correlation    []string
+	telemetry      bool
+	telemetryMax   int
+	telemetryLevel string
+	emptyItems     emptyItemPolicy
+	scrubCookies   *regexp.Regexp
 	itemsPerMinute int
+	escalation     int
+	occurrences    *occurrenceCache
+	enrichers      []EnricherFunc
+	clock          Clock
+	idGenerator    IDGenerator
+	testMode       bool
+	testName       string
+	customDigest   int
+	metrics        MetricsRecorder
+	environments   []string
+	modules        []string
+	platformInfo   map[string]interface{}
+	maxItems       int
+	levelLimits    map[string]int
 }
 
 func createConfiguration(token, environment, codeVersion, serverHost, serverRoot string) configuration {
-	hostname := serverHost
-	if hostname == "" {
-		hostname, _ = os.Hostname()
-	}
+	platform, platformInfo := detectPlatform()
 	return configuration{
 		enabled:        true,
 		token:          token,
 		environment:    environment,
-		platform:       runtime.GOOS,
+		platform:       platform,
+		platformInfo:   platformInfo,
 		endpoint:       "https://api.rollbar.com/api/1/item/",
-		scrubHeaders:   regexp.MustCompile("Authorization"),
-		scrubFields:    regexp.MustCompile("password|secret|token"),
+		scrubHeaders:   regexp.MustCompile(DefaultScrubHeaders),
+		scrubFields:    regexp.MustCompile(DefaultScrubFields),
 		codeVersion:    codeVersion,
-		serverHost:     hostname,
+		serverHost:     serverHost,
 		serverRoot:     serverRoot,
 		fingerprint:    false,
 		checkIgnore:    func(_s string) bool { return false },
@@ -744,17 +1505,33 @@ func createConfiguration(token, environment, codeVersion, serverHost, serverRoot
 		stackTracer:    DefaultStackTracer,
 		person:         Person{},
 		captureIp:      CaptureIpFull,
+		ipv4Mask:       DefaultIPv4MaskBits,
+		ipv6Mask:       DefaultIPv6MaskBits,
+		scrubCookies:   regexp.MustCompile(DefaultScrubCookies),
+		ipHeaders:      DefaultClientIPHeaders,
 		itemsPerMinute: 0,
+		clock:          SystemClock,
+		telemetry:      true,
+		idGenerator:    UUIDGenerator,
+		customDigest:   DefaultCustomDigestThreshold,
 	}
 }
 
 type diagnostic struct {
 	languageVersion string
+	emptyItems      *emptyItemCounter
+	events          *eventStream
+	suppressed      *int32
+	items           *int64
 }
 
 func createDiagnostic() diagnostic {
 	return diagnostic{
 		languageVersion: runtime.Version(),
+		emptyItems:      &emptyItemCounter{},
+		events:          &eventStream{},
+		suppressed:      new(int32),
+		items:           new(int64),
 	}
 }
 
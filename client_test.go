@@ -1,14 +1,19 @@
 package rollbar
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"mime/multipart"
 	"net/http"
+	"net/http/httptest"
+	"os"
 	"reflect"
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 )
 
 type TestTransport struct {
@@ -112,6 +117,38 @@ func TestWrapWithArgs(t *testing.T) {
 	client.Close()
 }
 
+func TestWrapWithContext(t *testing.T) {
+	client := testClient()
+	ctx := NewPersonContext(context.Background(), &Person{Id: "7"})
+	var received context.Context
+	result := client.WrapWithContext(ctx, func(ctx context.Context, foo string) {
+		received = ctx
+		panic(fmt.Errorf("%v", foo))
+	}, "foo")
+	if received != ctx {
+		t.Error("the context should be passed to the function")
+	}
+	if fmt.Sprint(result) != "foo" {
+		t.Error("Got:", result, "Expected: foo")
+	}
+	data := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	if errorFromData(data)["message"] != "foo" {
+		t.Error("data should have correct error message")
+	}
+	person := data["person"].(map[string]string)
+	if person["id"] != "7" {
+		t.Error("expected the person of the context, got:", person)
+	}
+
+	client.WrapWithContext(ctx, func(ctx context.Context) {
+		received = ctx
+	}, context.Background())
+	if received != context.Background() {
+		t.Error("a context among the args should be passed as is, got:", received)
+	}
+	client.Close()
+}
+
 func TestWrapNonError(t *testing.T) {
 	client := testClient()
 	err := "hello rollbar"
@@ -396,6 +433,66 @@ func TestLambdaWrapperWithMessage(t *testing.T) {
 	}
 }
 
+func TestLambdaWrapperDetails(t *testing.T) {
+	os.Setenv("AWS_LAMBDA_FUNCTION_NAME", "handler")
+	os.Setenv("AWS_LAMBDA_FUNCTION_MEMORY_SIZE", "128")
+	defer os.Unsetenv("AWS_LAMBDA_FUNCTION_NAME")
+	defer os.Unsetenv("AWS_LAMBDA_FUNCTION_MEMORY_SIZE")
+
+	client := testClient()
+	handler := client.LambdaWrapper(func(ctx context.Context) error {
+		panic("bork")
+	}, WithLambdaContext(func(ctx context.Context) (string, string) {
+		return "request-id", "arn:aws:lambda:us-east-1:123456789012:function:handler"
+	}))
+	invoke := func() {
+		defer func() { recover() }()
+		handler.(func(context.Context) error)(context.Background())
+	}
+
+	for _, coldStart := range []bool{true, false} {
+		invoke()
+		data := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+		if data["platform"] != "aws_lambda" {
+			t.Error("Expected platform to be lambda, got:", data["platform"])
+		}
+		details := data["custom"].(map[string]interface{})["lambda"].(map[string]interface{})
+		if details["function_name"] != "handler" || details["memory_limit_mb"] != 128 {
+			t.Error("Unexpected function details:", details)
+		}
+		if details["request_id"] != "request-id" || details["function_arn"] == nil {
+			t.Error("Unexpected invocation details:", details)
+		}
+		if details["cold_start"] != coldStart {
+			t.Error("Expected cold_start to be", coldStart, "got:", details["cold_start"])
+		}
+	}
+}
+
+func TestLambdaWrapperDeadlineWarning(t *testing.T) {
+	client := testClient()
+	handler := client.LambdaWrapper(func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	}, WithLambdaDeadlineWarning(40*time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	handler.(func(context.Context) error)(ctx)
+
+	transport := client.Transport.(*TestTransport)
+	if transport.Body == nil {
+		t.Fatal("Expected a deadline warning to be reported")
+	}
+	data := transport.Body["data"].(map[string]interface{})
+	if data["level"] != WARN {
+		t.Error("Expected a warning, got:", data["level"])
+	}
+	if _, ok := data["custom"].(map[string]interface{})["lambda"]; !ok {
+		t.Error("Expected lambda details in the warning")
+	}
+}
+
 func TestGettersAndSetters_Default(t *testing.T) {
 	c := testClient()
 	c.Transport = &TestTransport{}
@@ -662,6 +759,52 @@ func TestSetStackTracerClient(t *testing.T) {
 	}
 }
 
+func TestSetErrorTagger(t *testing.T) {
+	errTimeout := errors.New("timeout")
+	client := testClient()
+	client.SetErrorTagger(func(err error) []string {
+		if errors.Is(err, errTimeout) {
+			return []string{"retryable", "dependency"}
+		}
+		return nil
+	})
+
+	client.ErrorWithExtras(ERR, fmt.Errorf("calling billing: %w", errTimeout), map[string]interface{}{"tags": []string{"billing"}})
+	data := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	tags := data["custom"].(map[string]interface{})["tags"].([]string)
+	if strings.Join(tags, ",") != "billing,retryable,dependency" {
+		t.Error("wrong tags, got:", tags)
+	}
+
+	client.ErrorWithLevel(ERR, errors.New("invalid input"))
+	data = client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	if custom, ok := data["custom"].(map[string]interface{}); ok && custom["tags"] != nil {
+		t.Error("untagged errors should not have tags, got:", custom["tags"])
+	}
+}
+
+func TestSetLevelMapper(t *testing.T) {
+	client := testClient()
+	client.SetLevelMapper(func(err error) (string, bool) {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return WARN, true
+		}
+		return "", false
+	})
+
+	client.ErrorWithLevel(CRIT, fmt.Errorf("calling billing: %w", context.DeadlineExceeded))
+	data := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	if data["level"] != WARN {
+		t.Error("expected the mapped level, got:", data["level"])
+	}
+
+	client.ErrorWithLevel(CRIT, errors.New("invalid input"))
+	data = client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	if data["level"] != CRIT {
+		t.Error("expected the level of the call site, got:", data["level"])
+	}
+}
+
 func TestEnabled(t *testing.T) {
 	client := testClient()
 	client.SetEnabled(false)
@@ -707,6 +850,584 @@ func TestCaptureTelemetryEvent(t *testing.T) {
 	}
 }
 
+func TestRecordNavigationAndConnectivityTelemetry(t *testing.T) {
+	client := testClient()
+	client.RecordNavigationTelemetry("queue-a", "queue-b")
+	client.RecordConnectivityTelemetry(false)
+	client.RecordConnectivityTelemetry(true)
+	items := client.Telemetry.GetQueueItems()
+	if len(items) != 3 {
+		t.Fatal("Queue should have 3 items, got:", len(items))
+	}
+
+	expected := []map[string]interface{}{
+		{"type": "navigation", "level": "info", "source": "client",
+			"body": map[string]interface{}{"from": "queue-a", "to": "queue-b"}},
+		{"type": "connectivity", "level": "warning", "source": "client",
+			"body": map[string]interface{}{"change": "offline"}},
+		{"type": "connectivity", "level": "info", "source": "client",
+			"body": map[string]interface{}{"change": "online"}},
+	}
+	for i, item := range items {
+		event := item.(map[string]interface{})
+		delete(event, "timestamp_ms")
+		if !reflect.DeepEqual(event, expected[i]) {
+			t.Errorf("Event %d is different, got: %v", i, event)
+		}
+	}
+}
+
+func TestRecordTypedTelemetry(t *testing.T) {
+	client := testClient()
+	client.RecordLogTelemetry("warning", "cache miss")
+	client.RecordNetworkTelemetry("GET", "https://example.com/users", 503)
+	client.RecordManualTelemetry("", map[string]interface{}{"step": "checkout"})
+	items := client.Telemetry.GetQueueItems()
+	if len(items) != 3 {
+		t.Fatal("Queue should have 3 items, got:", len(items))
+	}
+
+	expected := []map[string]interface{}{
+		{"type": "log", "level": "warning", "source": "client",
+			"body": map[string]interface{}{"message": "cache miss"}},
+		{"type": "network", "level": "critical", "source": "client",
+			"body": map[string]interface{}{"method": "GET", "url": "https://example.com/users", "status_code": 503}},
+		{"type": "manual", "level": "info", "source": "client",
+			"body": map[string]interface{}{"step": "checkout"}},
+	}
+	for i, item := range items {
+		event := item.(map[string]interface{})
+		delete(event, "timestamp_ms")
+		if !reflect.DeepEqual(event, expected[i]) {
+			t.Errorf("Event %d is different, got: %v", i, event)
+		}
+	}
+}
+
+func TestAttachTelemetry(t *testing.T) {
+	client := testClient()
+	client.RecordLogTelemetry("debug", "cache lookup")
+	client.RecordLogTelemetry("info", "cache miss")
+	client.RecordLogTelemetry("warning", "slow query")
+	client.RecordLogTelemetry("error", "query failed")
+
+	client.SetTelemetryLevel("info")
+	client.SetTelemetryMaxEvents(2)
+	client.ErrorWithLevel(ERR, errors.New("boom"))
+	body := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})["body"].(map[string]interface{})
+	telemetry := body["telemetry"].([]interface{})
+	if len(telemetry) != 2 {
+		t.Fatal("expected 2 telemetry events, got:", telemetry)
+	}
+	for i, message := range []string{"slow query", "query failed"} {
+		if got := telemetry[i].(map[string]interface{})["body"].(map[string]interface{})["message"]; got != message {
+			t.Errorf("event %d: expected %q, got: %v", i, message, got)
+		}
+	}
+
+	client.SetAttachTelemetry(false)
+	client.ErrorWithLevel(ERR, errors.New("boom"))
+	body = client.Transport.(*TestTransport).Body["data"].(map[string]interface{})["body"].(map[string]interface{})
+	if _, ok := body["telemetry"]; ok {
+		t.Error("expected no telemetry, got:", body["telemetry"])
+	}
+	if len(client.Telemetry.GetQueueItems()) != 4 {
+		t.Error("expected the telemetry events to be kept in the queue")
+	}
+}
+
+func TestScrubSecretsInItems(t *testing.T) {
+	client := testClient()
+	client.SetScrubSecrets(true)
+	client.CaptureTelemetryEvent("log", "info", map[string]interface{}{"message": "using Bearer abc123"})
+
+	client.ErrorWithLevel(ERR, fmt.Errorf("request failed: Bearer abc123"))
+	data := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	expected := "request failed: Bearer " + FILTERED
+	if data["title"] != expected {
+		t.Error("title should be scrubbed, got:", data["title"])
+	}
+	if errorFromData(data)["message"] != expected {
+		t.Error("exception message should be scrubbed, got:", errorFromData(data)["message"])
+	}
+	telemetry := data["body"].(map[string]interface{})["telemetry"].([]interface{})
+	event := telemetry[0].(map[string]interface{})["body"].(map[string]interface{})
+	if event["message"] != "using Bearer "+FILTERED {
+		t.Error("telemetry should be scrubbed, got:", event["message"])
+	}
+	queued := client.Telemetry.GetQueueItems()[0].(map[string]interface{})["body"].(map[string]interface{})
+	if queued["message"] != "using Bearer abc123" {
+		t.Error("queued telemetry should not be modified, got:", queued["message"])
+	}
+
+	client.Message(INFO, "token Bearer abc123")
+	data = client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	message := data["body"].(map[string]interface{})["message"].(map[string]interface{})
+	if message["body"] != "token Bearer "+FILTERED {
+		t.Error("message should be scrubbed, got:", message["body"])
+	}
+
+	client.SetScrubSecrets(false)
+	client.Message(INFO, "token Bearer abc123")
+	data = client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	if data["title"] != "token Bearer abc123" {
+		t.Error("title should not be scrubbed when disabled, got:", data["title"])
+	}
+}
+
+func TestSetMessageScrubber(t *testing.T) {
+	client := testClient()
+	tokenPattern := regexp.MustCompile(`token \S+`)
+	client.SetMessageScrubber(func(s string) string {
+		return tokenPattern.ReplaceAllString(s, "token "+FILTERED)
+	})
+
+	client.ErrorWithLevel(ERR, fmt.Errorf("auth failed for token abc123"))
+	data := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	expected := "auth failed for token " + FILTERED
+	if data["title"] != expected {
+		t.Error("title should be scrubbed, got:", data["title"])
+	}
+	if errorFromData(data)["message"] != expected {
+		t.Error("exception message should be scrubbed, got:", errorFromData(data)["message"])
+	}
+
+	client.Message(INFO, "retrying with token abc123")
+	data = client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	message := data["body"].(map[string]interface{})["message"].(map[string]interface{})
+	if message["body"] != "retrying with token "+FILTERED {
+		t.Error("message should be scrubbed, got:", message["body"])
+	}
+	if !client.Config().MessageScrubber {
+		t.Error("expected the snapshot to report the message scrubber")
+	}
+}
+
+type testScrubber struct{}
+
+func (testScrubber) ScrubHeader(name string) bool { return name == "X-Api-Key" }
+
+func (testScrubber) ScrubField(key string) bool { return key == "ssn" }
+
+func (testScrubber) ScrubValue(value string) string {
+	return strings.Replace(value, "secret", "******", -1)
+}
+
+func TestSetScrubber(t *testing.T) {
+	client := testClient()
+	client.SetScrubber(testScrubber{})
+	client.SetCustom(map[string]interface{}{"user": map[string]interface{}{"ssn": "123-45-6789"}})
+	client.CaptureTelemetryEvent("network", "info", map[string]interface{}{
+		"request_headers": map[string]interface{}{"X-Api-Key": "abc", "Accept": "*/*"},
+	})
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("X-Api-Key", "abc")
+	client.RequestError(ERR, r, errors.New("the secret is out"))
+	data := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	if data["title"] != "the ****** is out" {
+		t.Error("title should be scrubbed, got:", data["title"])
+	}
+	headers := data["request"].(map[string]interface{})["headers"].(map[string]interface{})
+	if headers["X-Api-Key"] != FILTERED {
+		t.Error("request header should be scrubbed, got:", headers)
+	}
+	user := data["custom"].(map[string]interface{})["user"].(map[string]interface{})
+	if user["ssn"] != FILTERED {
+		t.Error("custom field should be scrubbed, got:", user)
+	}
+	telemetry := data["body"].(map[string]interface{})["telemetry"].([]interface{})
+	event := telemetry[0].(map[string]interface{})["body"].(map[string]interface{})
+	eventHeaders := event["request_headers"].(map[string]interface{})
+	if eventHeaders["X-Api-Key"] != FILTERED || eventHeaders["Accept"] != "*/*" {
+		t.Error("telemetry headers should be scrubbed, got:", eventHeaders)
+	}
+	queued := client.Telemetry.GetQueueItems()[0].(map[string]interface{})["body"].(map[string]interface{})
+	if queued["request_headers"].(map[string]interface{})["X-Api-Key"] != "abc" {
+		t.Error("queued telemetry should not be modified, got:", queued)
+	}
+	if !client.Config().Scrubber {
+		t.Error("expected the snapshot to report the scrubber")
+	}
+}
+
+func TestBuiltinScrubbingEverySection(t *testing.T) {
+	client := testClient()
+	client.SetScrubHeaders(regexp.MustCompile("X-Api-Key"))
+	client.CaptureTelemetryEvent("log", "info", map[string]interface{}{
+		"message":         "login",
+		"password":        "hunter2",
+		"request_headers": map[string]interface{}{"X-Api-Key": "abc"},
+	})
+	client.ErrorWithExtras(ERR, errors.New("failed"), map[string]interface{}{"token": "abc"})
+
+	data := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	if data["custom"].(map[string]interface{})["token"] != FILTERED {
+		t.Error("custom field should be scrubbed, got:", data["custom"])
+	}
+	telemetry := data["body"].(map[string]interface{})["telemetry"].([]interface{})
+	event := telemetry[0].(map[string]interface{})["body"].(map[string]interface{})
+	if event["password"] != FILTERED || event["message"] != "login" {
+		t.Error("telemetry field should be scrubbed, got:", event)
+	}
+	if event["request_headers"].(map[string]interface{})["X-Api-Key"] != FILTERED {
+		t.Error("telemetry header should be scrubbed, got:", event)
+	}
+}
+
+type testContextKey string
+
+type panickyValue struct{}
+
+func (panickyValue) MarshalJSON() ([]byte, error) {
+	panic("cannot encode")
+}
+
+func TestScrubFieldsInNestedData(t *testing.T) {
+	client := testClient()
+	user := map[string]interface{}{"name": "jane", "password": "x"}
+	client.MessageWithExtras(INFO, "signup", map[string]interface{}{
+		"user":     user,
+		"sessions": []interface{}{map[string]interface{}{"id": 1, "access_token": "abc"}},
+	})
+
+	custom := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})["custom"].(map[string]interface{})
+	scrubbedUser := custom["user"].(map[string]interface{})
+	if scrubbedUser["password"] != FILTERED || scrubbedUser["name"] != "jane" {
+		t.Error("nested password should be scrubbed, got:", scrubbedUser)
+	}
+	session := custom["sessions"].([]interface{})[0].(map[string]interface{})
+	if session["access_token"] != FILTERED || session["id"] != 1 {
+		t.Error("fields in slices should be scrubbed, got:", session)
+	}
+	if user["password"] != "x" {
+		t.Error("the extras should not be modified, got:", user)
+	}
+}
+
+func TestScrubExemptFields(t *testing.T) {
+	client := testClient()
+	client.SetScrubExemptFields("Token_Type", "tokens")
+	client.MessageWithExtras(INFO, "login", map[string]interface{}{
+		"token_type": "bearer",
+		"tokens":     map[string]interface{}{"access_token": "abc", "count": 2},
+		"password":   "x",
+	})
+
+	custom := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})["custom"].(map[string]interface{})
+	if custom["token_type"] != "bearer" || custom["password"] != FILTERED {
+		t.Error("only the exempt fields should be kept, got:", custom)
+	}
+	tokens := custom["tokens"].(map[string]interface{})
+	if tokens["access_token"] != FILTERED || tokens["count"] != 2 {
+		t.Error("fields nested under exempt fields should be scrubbed, got:", tokens)
+	}
+}
+
+func TestScrubExemptFieldsRequest(t *testing.T) {
+	client := testClient()
+	client.SetScrubExemptFields("token_type")
+	r, _ := http.NewRequest("GET", "http://foo.com/?token_type=bearer&b=%20&access_token=abc", nil)
+
+	request := client.requestDetails(r)
+	if request["url"] != "http://foo.com/?token_type=bearer&b=%20&access_token=[FILTERED]" {
+		t.Error("only the exempt parameter should be kept in the url, got:", request["url"])
+	}
+	get := request["GET"].(map[string]interface{})
+	if get["token_type"] != "bearer" || get["access_token"] != FILTERED {
+		t.Error("only the exempt parameter should be kept in GET, got:", get)
+	}
+}
+
+func TestSetAllowedHeaders(t *testing.T) {
+	client := testClient()
+	client.SetAllowedHeaders("content-type", "X-Request-Id", "Authorization")
+	r, _ := http.NewRequest("GET", "http://foo.com/", nil)
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("X-Request-Id", "42")
+	r.Header.Set("Authorization", "Bearer abc")
+	r.Header.Set("X-Internal-Tenant", "acme")
+
+	headers := client.requestDetails(r)["headers"].(map[string]interface{})
+	expected := map[string]interface{}{"Content-Type": "application/json", "X-Request-Id": "42", "Authorization": FILTERED}
+	if !reflect.DeepEqual(headers, expected) {
+		t.Error("expected only the allowed headers, scrubbed, got:", headers)
+	}
+	if len(r.Header) != 4 {
+		t.Error("the headers of the request should not be modified, got:", r.Header)
+	}
+
+	client.SetTelemetry(EnableNetworkTelemetryRequestHeaders())
+	data := client.Telemetry.populateTransporterBody(r, nil)
+	requestHeaders := data["body"].(map[string]interface{})["request_headers"].(map[string]interface{})
+	if _, ok := requestHeaders["X-Internal-Tenant"]; ok || requestHeaders["X-Request-Id"] != "42" {
+		t.Error("expected only the allowed headers in telemetry, got:", requestHeaders)
+	}
+}
+
+func TestSetContextName(t *testing.T) {
+	client := testClient()
+	client.SetContextName("billing#charge")
+
+	client.ErrorWithLevel(ERR, errors.New("boom"))
+	data := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	if data["context"] != "billing#charge" {
+		t.Error("expected the context name of the client, got:", data["context"])
+	}
+
+	ctx := NewContextNameContext(context.Background(), "GET /users/{id}")
+	client.ErrorWithExtrasAndContext(ctx, ERR, errors.New("boom"), noExtras)
+	data = client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	if data["context"] != "GET /users/{id}" {
+		t.Error("expected the context name of the context, got:", data["context"])
+	}
+	if client.Config().ContextName != "billing#charge" {
+		t.Error("expected the context name in the configuration, got:", client.Config().ContextName)
+	}
+}
+
+func TestSetPersonProvider(t *testing.T) {
+	client := testClient()
+	client.SetPerson("default", "", "")
+	client.SetPersonProvider(func(ctx context.Context, r *http.Request) *Person {
+		if r == nil {
+			return nil
+		}
+		return &Person{Id: r.Header.Get("X-User-Id")}
+	})
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("X-User-Id", "42")
+	client.RequestError(ERR, r, errors.New("boom"))
+	person := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})["person"].(map[string]string)
+	if person["id"] != "42" {
+		t.Error("expected the person of the provider, got:", person)
+	}
+
+	client.ErrorWithLevel(ERR, errors.New("boom"))
+	person = client.Transport.(*TestTransport).Body["data"].(map[string]interface{})["person"].(map[string]string)
+	if person["id"] != "default" {
+		t.Error("expected the person of the client, got:", person)
+	}
+
+	ctx := NewPersonContext(context.Background(), &Person{Id: "7"})
+	client.RequestErrorWithStackSkipWithExtrasAndContext(ctx, ERR, r, errors.New("boom"), 0, noExtras)
+	person = client.Transport.(*TestTransport).Body["data"].(map[string]interface{})["person"].(map[string]string)
+	if person["id"] != "7" {
+		t.Error("expected the person of the context, got:", person)
+	}
+}
+
+func TestSetPersonScrubPolicy(t *testing.T) {
+	client := testClient()
+	client.SetPerson("42", "jane", "jane@example.com", WithPersonExtra(map[string]string{"username": "extra"}))
+
+	client.SetPersonScrubPolicy(HashEmail | OmitUsername)
+	client.ErrorWithLevel(ERR, errors.New("boom"))
+	person := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})["person"].(map[string]string)
+	if person["id"] != "42" {
+		t.Error("expected the id to be kept, got:", person)
+	}
+	if person["email"] != "8c87b489ce35cf2e2f39f80e282cb2e804932a56a213983eeeb428407d43b52d" {
+		t.Error("expected the email to be hashed, got:", person)
+	}
+	if _, ok := person["username"]; ok {
+		t.Error("expected the username to be omitted, got:", person)
+	}
+	diagnostic := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})["notifier"].(map[string]interface{})["diagnostic"].(map[string]interface{})
+	configured := diagnostic["configuredOptions"].(map[string]interface{})["person"].(map[string]string)
+	if configured["Id"] != "42" || configured["Email"] != person["email"] {
+		t.Error("expected the email of the configured options to be hashed, got:", configured)
+	}
+	if _, ok := configured["Username"]; ok {
+		t.Error("expected the username of the configured options to be omitted, got:", configured)
+	}
+
+	client.SetPersonScrubPolicy(HashEmail | OmitEmail | HashUsername)
+	ctx := NewPersonContext(context.Background(), &Person{Id: "7", Username: "joe"})
+	client.ErrorWithExtrasAndContext(ctx, ERR, errors.New("boom"), noExtras)
+	person = client.Transport.(*TestTransport).Body["data"].(map[string]interface{})["person"].(map[string]string)
+	if _, ok := person["email"]; ok {
+		t.Error("expected OmitEmail to take precedence, got:", person)
+	}
+	if person["id"] != "7" || person["username"] == "joe" || len(person["username"]) != 64 {
+		t.Error("expected the username of the context to be hashed, got:", person)
+	}
+	if client.PersonScrubPolicy() != HashEmail|OmitEmail|HashUsername {
+		t.Error("wrong policy:", client.PersonScrubPolicy())
+	}
+}
+
+func TestEnvironmentContext(t *testing.T) {
+	client := testClient()
+	ctx := NewEnvironmentContext(context.Background(), "preview-42")
+
+	client.ErrorWithExtrasAndContext(ctx, ERR, errors.New("boom"), noExtras)
+	data := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	if data["environment"] != "preview-42" {
+		t.Error("expected the environment of the context, got:", data["environment"])
+	}
+
+	client.ErrorWithLevel(ERR, errors.New("boom"))
+	data = client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	if data["environment"] != "test" {
+		t.Error("expected the environment of the client, got:", data["environment"])
+	}
+}
+
+func TestCustomContext(t *testing.T) {
+	client := testClient()
+	client.SetCustom(map[string]interface{}{"service": "api", "tenant": "none"})
+	ctx := NewCustomContext(context.Background(), map[string]interface{}{"tenant": "acme", "job": "import"})
+	ctx = NewCustomContext(ctx, map[string]interface{}{"job": "export"})
+
+	client.MessageWithExtrasAndContext(ctx, INFO, "done", map[string]interface{}{"rows": 3})
+	custom := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})["custom"].(map[string]interface{})
+	if custom["service"] != "api" || custom["tenant"] != "acme" || custom["job"] != "export" || custom["rows"] != 3 {
+		t.Error("wrong custom data, got:", custom)
+	}
+}
+
+func TestRequestContext(t *testing.T) {
+	client := testClient()
+	r := httptest.NewRequest("GET", "/orders?page=2", nil)
+	ctx := NewRequestInfoContext(context.Background(), &RequestInfo{URL: "http://example.com/info"})
+	ctx = NewRequestContext(ctx, r)
+
+	client.ErrorWithExtrasAndContext(ctx, ERR, errors.New("boom"), noExtras)
+	request := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})["request"].(map[string]interface{})
+	if request["url"] != "/orders?page=2" || request["method"] != "GET" {
+		t.Error("expected the request of the context, got:", request)
+	}
+}
+
+func TestReportingReturnsUUID(t *testing.T) {
+	client := testClient()
+	uuid := client.ErrorWithExtrasCtx(context.Background(), ERR, errors.New("failed"), nil)
+	data := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	if uuid == "" || data["uuid"] != uuid {
+		t.Error("expected the UUID of the item, got:", uuid, data["uuid"])
+	}
+	traceChain := data["body"].(map[string]interface{})["trace_chain"].([]map[string]interface{})
+	if frames := traceChain[0]["frames"].(stack); frames[0].Method != "rollbar-go.TestReportingReturnsUUID" {
+		t.Error("expected the trace to start at the caller, got:", frames[0])
+	}
+
+	r := httptest.NewRequest("GET", "/orders", nil)
+	uuid = client.RequestMessageWithExtrasCtx(context.Background(), INFO, r, "hello", nil)
+	data = client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	if uuid == "" || data["uuid"] != uuid || data["request"] == nil {
+		t.Error("expected the UUID of the request item, got:", uuid, data["uuid"])
+	}
+
+	client.SetEnabled(false)
+	if uuid := client.MessageWithExtrasCtx(context.Background(), INFO, "disabled", nil); uuid != "" {
+		t.Error("expected no UUID when disabled, got:", uuid)
+	}
+}
+
+func TestCaptureCookies(t *testing.T) {
+	client := testClient()
+	r := httptest.NewRequest("GET", "/cart", nil)
+	r.Header.Set("Cookie", "session_id=abc123; theme=dark; csrftoken=xyz")
+
+	client.RequestError(ERR, r, errors.New("boom"))
+	request := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})["request"].(map[string]interface{})
+	if _, ok := request["cookies"]; ok {
+		t.Error("cookies should not be captured by default, got:", request["cookies"])
+	}
+
+	client.SetCaptureCookies(true)
+	client.RequestError(ERR, r, errors.New("boom"))
+	request = client.Transport.(*TestTransport).Body["data"].(map[string]interface{})["request"].(map[string]interface{})
+	cookies := request["cookies"].(map[string]string)
+	if cookies["theme"] != "dark" || cookies["session_id"] != FILTERED || cookies["csrftoken"] != FILTERED {
+		t.Error("wrong cookies, got:", cookies)
+	}
+	header := request["headers"].(map[string]interface{})["Cookie"]
+	if header != "session_id="+FILTERED+"; theme=dark; csrftoken="+FILTERED {
+		t.Error("the Cookie header should be scrubbed, got:", header)
+	}
+}
+
+func TestMultipartRequest(t *testing.T) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	writer.WriteField("name", "jane")
+	writer.WriteField("password", "hunter2")
+	part, _ := writer.CreateFormFile("avatar", "me.png")
+	part.Write([]byte("not really a png"))
+	part, _ = writer.CreateFormFile("secret_key", "id_rsa")
+	part.Write([]byte("not really a key"))
+	part, _ = writer.CreateFormFile("token_list", "tokens.csv")
+	part.Write([]byte("not really tokens"))
+	writer.Close()
+	r := httptest.NewRequest("POST", "/profile", &buf)
+	r.Header.Set("Content-Type", writer.FormDataContentType())
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		t.Fatal(err)
+	}
+
+	client := testClient()
+	client.SetScrubExemptFields("token_list")
+	client.RequestError(ERR, r, errors.New("boom"))
+
+	request := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})["request"].(map[string]interface{})
+	post := request["POST"].(map[string]interface{})
+	if post["name"] != "jane" || post["password"] != FILTERED {
+		t.Error("wrong form values, got:", post)
+	}
+	files := request["files"].(map[string]interface{})["avatar"].([]map[string]interface{})
+	if len(files) != 1 || files[0]["filename"] != "me.png" || files[0]["size"] != int64(16) {
+		t.Error("wrong files, got:", files)
+	}
+	if _, ok := files[0]["content"]; ok {
+		t.Error("file contents should never be reported")
+	}
+	if key := request["files"].(map[string]interface{})["secret_key"]; key != FILTERED {
+		t.Error("expected the file field to be scrubbed, got:", key)
+	}
+	if _, ok := request["files"].(map[string]interface{})["token_list"].([]map[string]interface{}); !ok {
+		t.Error("expected the exempt file field to be described")
+	}
+}
+
+func TestRegisterContextValue(t *testing.T) {
+	client := testClient()
+	client.RegisterContextValue("claims", testContextKey("claims"))
+	client.RegisterContextValue("request_id", testContextKey("request_id"))
+	client.RegisterContextValue("broken", testContextKey("broken"))
+	client.RegisterContextValue("channel", testContextKey("channel"))
+
+	ctx := context.WithValue(context.Background(), testContextKey("claims"), map[string]interface{}{"sub": "42"})
+	ctx = context.WithValue(ctx, testContextKey("broken"), panickyValue{})
+	ctx = context.WithValue(ctx, testContextKey("channel"), make(chan int))
+	r, _ := http.NewRequest("GET", "http://foo.com/", nil)
+	r = r.WithContext(context.WithValue(r.Context(), testContextKey("request_id"), "abc"))
+
+	client.RequestErrorWithExtrasAndContext(ctx, ERR, r, errors.New("boom"), nil)
+	data := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	values := data["custom"].(map[string]interface{})["context_values"].(map[string]interface{})
+	if values["claims"].(map[string]interface{})["sub"] != "42" {
+		t.Error("expected claims, got:", values["claims"])
+	}
+	if values["request_id"] != "abc" {
+		t.Error("expected the request ID from the request context, got:", values["request_id"])
+	}
+	if !strings.Contains(values["broken"].(string), "panic while encoding") {
+		t.Error("expected the panicking value as text, got:", values["broken"])
+	}
+	if _, ok := values["channel"].(string); !ok {
+		t.Error("expected the unencodable value as text, got:", values["channel"])
+	}
+
+	client.Message(INFO, "no values")
+	data = client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	if _, ok := data["custom"]; ok {
+		t.Error("expected no custom data without context values, got:", data["custom"])
+	}
+}
+
 func configuredOptionsFromData(data map[string]interface{}) map[string]interface{} {
 	notifier := data["notifier"].(map[string]interface{})
 	diagnostic := notifier["diagnostic"].(map[string]interface{})
@@ -0,0 +1,90 @@
+package rollbar
+
+import (
+	"crypto/rand"
+	"fmt"
+	"time"
+)
+
+// A Clock tells the time. The client uses it for the timestamps of items and telemetry events and
+// for rate limiting, so that tests can control time or simulate clock skew. See Client.SetClock.
+type Clock interface {
+	Now() time.Time
+}
+
+// An IDGenerator generates the unique ID reported as data.uuid with every item. See
+// Client.SetIDGenerator.
+type IDGenerator interface {
+	NewID() string
+}
+
+// SystemClock is the default Clock, which uses time.Now.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// clockSetter is implemented by the transports rate limiting items according to a clock.
+type clockSetter interface {
+	SetClock(clock Clock)
+}
+
+// UUIDGenerator is the default IDGenerator, which generates random (version 4) UUIDs.
+var UUIDGenerator IDGenerator = uuidGenerator{}
+
+type uuidGenerator struct{}
+
+func (uuidGenerator) NewID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return ""
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// now returns the current time according to the clock of the transport.
+func (t *baseTransport) now() time.Time {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	return t.nowLocked()
+}
+
+// nowLocked is now for the callers already holding the lock of the transport.
+func (t *baseTransport) nowLocked() time.Time {
+	if t.clock == nil {
+		return time.Now()
+	}
+	return t.clock.Now()
+}
+
+// now returns the current time according to the clock of the telemetry.
+func (t *Telemetry) now() time.Time {
+	t.lock.RLock()
+	clock := t.clock
+	t.lock.RUnlock()
+	if clock == nil {
+		return time.Now()
+	}
+	return clock.Now()
+}
+
+// SetClock sets the clock used for the timestamps of the events captured from the logger and the
+// HTTP client. The default is SystemClock.
+func (t *Telemetry) SetClock(clock Clock) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.clock = clock
+}
+
+// SetClock sets the clock used for rate limiting, so that the limits set with SetItemsPerMinute
+// and SetItemsPerMinuteByLevel can be tested without waiting. The default is SystemClock.
+func (t *baseTransport) SetClock(clock Clock) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.clock = clock
+}
@@ -0,0 +1,136 @@
+package rollbar
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+type counterIDGenerator struct {
+	count int
+}
+
+func (g *counterIDGenerator) NewID() string {
+	g.count++
+	return fmt.Sprintf("item-%d", g.count)
+}
+
+func TestSetClockAndIDGenerator(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)}
+	client := testClient()
+	client.SetClock(clock)
+	client.SetIDGenerator(&counterIDGenerator{})
+
+	client.CaptureTelemetryEvent("manual", "info", map[string]interface{}{})
+	client.Message(INFO, "first")
+	data := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	if data["timestamp"] != clock.now.Unix() {
+		t.Error("expected the timestamp of the clock, got:", data["timestamp"])
+	}
+	if data["uuid"] != "item-1" {
+		t.Error("expected the generated ID, got:", data["uuid"])
+	}
+	telemetry := data["body"].(map[string]interface{})["telemetry"].([]interface{})
+	event := telemetry[0].(map[string]interface{})
+	if event["timestamp_ms"] != clock.now.UnixNano()/int64(time.Millisecond) {
+		t.Error("expected the telemetry timestamp of the clock, got:", event["timestamp_ms"])
+	}
+
+	client.SetClock(nil)
+	client.SetIDGenerator(nil)
+	client.Message(INFO, "second")
+	data = client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	if data["timestamp"] == clock.now.Unix() {
+		t.Error("expected the system clock to be restored")
+	}
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if !uuid.MatchString(data["uuid"].(string)) {
+		t.Error("expected a random UUID, got:", data["uuid"])
+	}
+}
+
+func TestSetClockRateLimiting(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	client := NewSync("", "test", "", "", "")
+	client.SetLogger(&SilentClientLogger{})
+	client.SetItemsPerMinute(1)
+	client.SetClock(clock)
+	transport := client.Transport.(*SyncTransport)
+
+	client.Message(INFO, "first")
+	client.Message(INFO, "second")
+	if transport.perMinCounter != 1 {
+		t.Error("expected the second item to be rate limited, got:", transport.perMinCounter)
+	}
+	clock.now = clock.now.Add(61 * time.Second)
+	client.Message(INFO, "third")
+	if transport.perMinCounter != 1 || !transport.startTime.Equal(clock.now) {
+		t.Error("expected the rate limit to be reset by the clock, got:", transport.perMinCounter, transport.startTime)
+	}
+}
+
+func TestTransportSetClock(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	transport := NewSyncTransport("", "")
+	transport.SetLogger(&SilentClientLogger{})
+	transport.SetItemsPerMinute(1)
+	transport.SetClock(clock)
+	body := map[string]interface{}{"data": map[string]interface{}{"level": "info"}}
+
+	transport.Send(body)
+	if transport.shouldSend(body) {
+		t.Error("expected the item to be rate limited")
+	}
+	clock.now = clock.now.Add(61 * time.Second)
+	transport.Send(body)
+	if !transport.startTime.Equal(clock.now) || transport.perMinCounter != 1 {
+		t.Error("expected the rate limit to be reset by the clock, got:", transport.startTime, transport.perMinCounter)
+	}
+}
+
+func TestTransportSetClockRetryAfter(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", clock.now.Add(90*time.Second).Format(http.TimeFormat))
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	transport := NewSyncTransport("token", ts.URL)
+	transport.SetLogger(&SilentClientLogger{})
+	transport.SetRetryAttempts(0)
+	transport.SetClock(clock)
+
+	err := transport.Send(map[string]interface{}{"hello": "world"})
+	var rateLimited ErrRateLimited
+	if !errors.As(err, &rateLimited) || rateLimited.RetryAfter != 90*time.Second {
+		t.Error("expected RetryAfter relative to the clock, got:", err)
+	}
+}
+
+func TestSetClockTelemetry(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)}
+	client := testClient()
+	client.SetClock(clock)
+
+	expected := clock.now.UnixNano() / int64(time.Millisecond)
+	if data := client.Telemetry.populateLoggerBody([]byte("hello")); data["timestamp_ms"] != expected {
+		t.Error("expected the timestamp of the clock, got:", data["timestamp_ms"])
+	}
+	client.SetTelemetry()
+	if data := client.Telemetry.populateLoggerBody([]byte("hello")); data["timestamp_ms"] != expected {
+		t.Error("expected the clock to be kept by new telemetry, got:", data["timestamp_ms"])
+	}
+}
@@ -0,0 +1,256 @@
+package rollbar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ConfigSnapshot is a read-only copy of the effective configuration of a Client, as returned by
+// Client.Config. It is meant for logging or verifying the configuration, e.g. at startup, so the
+// access token is redacted. Its slices and maps are copies too, so that modifying them does not
+// change the configuration of the client.
+type ConfigSnapshot struct {
+	Enabled bool
+	// Token is the access token with all but its last four characters masked.
+	Token          string
+	Environment    string
+	ContextName    string
+	Platform       string
+	CodeVersion    string
+	ServerHost     string
+	ServerRoot     string
+	Endpoint       string
+	Custom         map[string]interface{}
+	Person         Person
+	Fingerprint    bool
+	CaptureIp      captureIp
+	ItemsPerMinute int
+	// ScrubHeaders and ScrubFields are the source text of the scrubbing regular expressions.
+	ScrubHeaders string
+	ScrubFields  string
+	ScrubSecrets bool
+	ScrubPII     bool
+	// TrustedProxies and ClientIPHeaders are set by SetTrustedProxies and SetClientIPHeaders.
+	TrustedProxies  []string
+	ClientIPHeaders []string
+	// IPv4MaskBits and IPv6MaskBits are set by SetIPAnonymizationMask.
+	IPv4MaskBits int
+	IPv6MaskBits int
+	// TrustForwardedHeaders is set by SetTrustForwardedHeaders.
+	TrustForwardedHeaders bool
+	// CorrelationHeaders are set by SetCorrelationHeaders.
+	CorrelationHeaders []string
+	// EmptyItemPolicy is set by SetEmptyItemPolicy.
+	EmptyItemPolicy emptyItemPolicy
+	// CaptureCookies is set by SetCaptureCookies, and ScrubCookies is the source text of the
+	// pattern set by SetScrubCookies.
+	CaptureCookies bool
+	ScrubCookies   string
+	// SkipPresets is the number of stack frame skip presets set with SetSkipPresets.
+	SkipPresets int
+	// ContextValues are the names of the context values registered with RegisterContextValue.
+	ContextValues []string
+	// EscalationThreshold and EscalationWindow describe the policy set with SetEscalationPolicy.
+	EscalationThreshold int
+	EscalationWindow    time.Duration
+	// CustomDigestThreshold is the length above which values in custom data are digested.
+	CustomDigestThreshold int
+	// TestMode and TestName are set by SetTestMode and StartTest.
+	TestMode bool
+	TestName string
+	// Enrichers is the number of enrichers added with AddEnricher.
+	Enrichers int
+	// CustomRequestExtractor is true when a RequestExtractorFunc has been set.
+	CustomRequestExtractor bool
+	// ErrorTagger is true when an ErrorTaggerFunc has been set.
+	ErrorTagger bool
+	// LevelMapper is true when a LevelMapperFunc has been set.
+	LevelMapper bool
+	// PersonProvider is true when a PersonProviderFunc has been set.
+	PersonProvider bool
+	// MessageScrubber is true when a MessageScrubberFunc has been set.
+	MessageScrubber bool
+	// Scrubber is true when a Scrubber has been set.
+	Scrubber bool
+	// AttachTelemetry, TelemetryMaxEvents and TelemetryLevel are set by SetAttachTelemetry,
+	// SetTelemetryMaxEvents and SetTelemetryLevel.
+	AttachTelemetry    bool
+	TelemetryMaxEvents int
+	TelemetryLevel     string
+	// EnabledEnvironments is set by SetEnabledEnvironments.
+	EnabledEnvironments []string
+	// DiagnosticModules is set by SetDiagnosticModules.
+	DiagnosticModules []string
+	// MaxItems is set by SetMaxItems.
+	MaxItems int
+	// ItemsPerMinuteByLevel is set by SetItemsPerMinuteByLevel.
+	ItemsPerMinuteByLevel map[string]int
+	// ScrubExemptFields and ScrubPaths are set by SetScrubExemptFields and SetScrubPaths.
+	ScrubExemptFields []string
+	ScrubPaths        []string
+	// AllowedHeaders is set by SetAllowedHeaders.
+	AllowedHeaders []string
+	// PersonScrubPolicy is set by SetPersonScrubPolicy.
+	PersonScrubPolicy PersonScrubPolicy
+	// MetricsRecorder is true when a MetricsRecorder has been set.
+	MetricsRecorder bool
+	Transport       TransportConfig
+}
+
+// TransportConfig describes the settings of the transport of a Client. Only Type is known for
+// transports which are not implemented by this package.
+type TransportConfig struct {
+	// Type is "async", "sync" or "spool" for the transports of this package, and the Go type of
+	// the transport otherwise.
+	Type     string
+	Endpoint string
+	// Buffer is the size of the queue of the asynchronous transport.
+	Buffer int
+	// PriorityReserve and PriorityLevel are set by SetQueuePriority.
+	PriorityReserve int
+	PriorityLevel   string
+	// DropPolicy and DropTimeout are set by SetDropPolicy.
+	DropPolicy          DropPolicy
+	DropTimeout         time.Duration
+	RetryAttempts       int
+	PrintPayloadOnError bool
+	// CompressPayloadOnError is set by SetCompressPayloadOnError.
+	CompressPayloadOnError bool
+	ItemsPerMinute         int
+	// CustomHTTPClient is true when an HTTP client has been set with SetHTTPClient.
+	CustomHTTPClient bool
+	// Signed is true when payloads are signed, see SetSigningKey.
+	Signed bool
+	// Interceptors is the number of interceptors the transport was wrapped with by WrapTransport.
+	Interceptors int
+}
+
+// Config returns a snapshot of the effective configuration of the client and its transport.
+func (c *Client) Config() ConfigSnapshot {
+	conf := c.configuration()
+	snapshot := ConfigSnapshot{
+		Enabled:                conf.enabled,
+		Token:                  redactToken(conf.token),
+		Environment:            conf.environment,
+		EnabledEnvironments:    append([]string(nil), conf.environments...),
+		DiagnosticModules:      append([]string(nil), conf.modules...),
+		MaxItems:               conf.maxItems,
+		ItemsPerMinuteByLevel:  copyLevelLimits(conf.levelLimits),
+		ScrubExemptFields:      append([]string(nil), conf.scrubExempt...),
+		ScrubPaths:             append([]string(nil), conf.scrubPaths...),
+		AllowedHeaders:         append([]string(nil), conf.allowHeaders...),
+		PersonScrubPolicy:      conf.personScrub,
+		ContextName:            conf.contextName,
+		Platform:               conf.platform,
+		CodeVersion:            conf.codeVersion,
+		ServerHost:             conf.host(),
+		ServerRoot:             conf.serverRoot,
+		Endpoint:               conf.endpoint,
+		Custom:                 buildCustom(conf.custom, nil),
+		Person:                 copyPerson(conf.person),
+		Fingerprint:            conf.fingerprint,
+		CaptureIp:              conf.captureIp,
+		IPv4MaskBits:           conf.ipv4Mask,
+		IPv6MaskBits:           conf.ipv6Mask,
+		ItemsPerMinute:         conf.itemsPerMinute,
+		ScrubSecrets:           conf.scrubSecrets,
+		ScrubPII:               conf.scrubPII,
+		SkipPresets:            len(conf.skipPresets),
+		Enrichers:              len(conf.enrichers),
+		CustomDigestThreshold:  conf.customDigest,
+		TestMode:               conf.testMode,
+		TestName:               conf.testName,
+		CustomRequestExtractor: conf.requestInfo != nil,
+		ErrorTagger:            conf.errorTagger != nil,
+		LevelMapper:            conf.levelMapper != nil,
+		PersonProvider:         conf.personProvider != nil,
+		MessageScrubber:        conf.msgScrubber != nil,
+		Scrubber:               conf.scrubber != nil,
+		MetricsRecorder:        conf.metrics != nil,
+		AttachTelemetry:        conf.telemetry,
+		TelemetryMaxEvents:     conf.telemetryMax,
+		TelemetryLevel:         conf.telemetryLevel,
+	}
+	if conf.scrubHeaders != nil {
+		snapshot.ScrubHeaders = conf.scrubHeaders.String()
+	}
+	if conf.scrubFields != nil {
+		snapshot.ScrubFields = conf.scrubFields.String()
+	}
+	snapshot.CaptureCookies = conf.captureCookies
+	snapshot.EmptyItemPolicy = conf.emptyItems
+	snapshot.TrustedProxies = c.TrustedProxies()
+	snapshot.ClientIPHeaders = append([]string(nil), conf.ipHeaders...)
+	snapshot.TrustForwardedHeaders = conf.trustForwarded
+	snapshot.CorrelationHeaders = append([]string(nil), conf.correlation...)
+	if conf.scrubCookies != nil {
+		snapshot.ScrubCookies = conf.scrubCookies.String()
+	}
+	for _, cv := range conf.contextValues {
+		snapshot.ContextValues = append(snapshot.ContextValues, cv.name)
+	}
+	snapshot.EscalationThreshold, snapshot.EscalationWindow = c.EscalationPolicy()
+
+	snapshot.Transport = transportConfig(c.Transport)
+	return snapshot
+}
+
+// copyPerson returns a copy of person which does not share its extra data.
+func copyPerson(person Person) Person {
+	if person.Extra != nil {
+		extra := make(map[string]string, len(person.Extra))
+		for k, v := range person.Extra {
+			extra[k] = v
+		}
+		person.Extra = extra
+	}
+	return person
+}
+
+func transportConfig(transport Transport) TransportConfig {
+	switch t := transport.(type) {
+	case *AsyncTransport:
+		config := t.config("async")
+		config.Buffer = t.Buffer
+		t.lock.RLock()
+		config.PriorityReserve, config.PriorityLevel = t.priorityReserve, t.priorityLevel
+		config.DropPolicy, config.DropTimeout = t.dropPolicy, t.dropTimeout
+		t.lock.RUnlock()
+		return config
+	case *SyncTransport:
+		return t.config("sync")
+	case *SpoolTransport:
+		return TransportConfig{Type: "spool"}
+	case *interceptedTransport:
+		config := transportConfig(t.Transport)
+		config.Interceptors += len(t.interceptors)
+		return config
+	default:
+		return TransportConfig{Type: fmt.Sprintf("%T", t)}
+	}
+}
+
+func (t *baseTransport) config(kind string) TransportConfig {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	return TransportConfig{
+		Type:                   kind,
+		Endpoint:               t.Endpoint,
+		RetryAttempts:          t.RetryAttempts,
+		PrintPayloadOnError:    t.PrintPayloadOnError,
+		CompressPayloadOnError: t.CompressPayloadOnError,
+		ItemsPerMinute:         t.ItemsPerMinute,
+		CustomHTTPClient:       t.httpClient != nil,
+		Signed:                 len(t.signingKey) > 0,
+	}
+}
+
+// redactToken masks all but the last four characters of an access token, which is enough to tell
+// tokens apart without disclosing them.
+func redactToken(token string) string {
+	if len(token) <= 4 {
+		return strings.Repeat("*", len(token))
+	}
+	return strings.Repeat("*", len(token)-4) + token[len(token)-4:]
+}
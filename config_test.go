@@ -0,0 +1,69 @@
+package rollbar
+
+import (
+	"net/http"
+	"regexp"
+	"testing"
+)
+
+func TestConfig(t *testing.T) {
+	client := NewSync("0123456789abcdef", "production", "abc123", "host", "/root")
+	client.SetScrubFields(regexp.MustCompile("password"))
+	client.SetRetryAttempts(5)
+	client.SetHTTPClient(&http.Client{})
+	client.RegisterContextValue("request_id", testContextKey("request_id"))
+
+	config := client.Config()
+	if config.Token != "************cdef" {
+		t.Error("expected the token to be redacted, got:", config.Token)
+	}
+	if config.Environment != "production" || config.CodeVersion != "abc123" || config.ServerHost != "host" {
+		t.Error("unexpected configuration:", config)
+	}
+	if config.ScrubFields != "password" || config.ScrubHeaders != "Authorization" {
+		t.Error("unexpected scrubbing configuration:", config.ScrubFields, config.ScrubHeaders)
+	}
+	if len(config.ContextValues) != 1 || config.ContextValues[0] != "request_id" {
+		t.Error("unexpected context values:", config.ContextValues)
+	}
+	transport := config.Transport
+	if transport.Type != "sync" || transport.RetryAttempts != 5 || !transport.CustomHTTPClient {
+		t.Error("unexpected transport configuration:", transport)
+	}
+
+	config = New("abc", "test", "", "", "").Config()
+	if config.Token != "***" {
+		t.Error("expected a short token to be masked entirely, got:", config.Token)
+	}
+	if config.Transport.Type != "async" || config.Transport.Buffer != DefaultBuffer {
+		t.Error("unexpected transport configuration:", config.Transport)
+	}
+	if testClient().Config().Transport.Type != "*rollbar.TestTransport" {
+		t.Error("expected the type of custom transports")
+	}
+}
+
+func TestConfigCopies(t *testing.T) {
+	client := NewSync("token", "production", "", "", "")
+	client.SetEnabledEnvironments([]string{"production"})
+	client.SetScrubExemptFields("token_type")
+	client.SetCorrelationHeaders("X-Request-Id")
+	limits := map[string]int{"debug": 10}
+	client.SetItemsPerMinuteByLevel(limits)
+	limits["debug"] = 20
+
+	config := client.Config()
+	config.EnabledEnvironments[0] = "staging"
+	config.ScrubExemptFields[0] = "password"
+	config.CorrelationHeaders[0] = "X-Trace-Id"
+	config.ItemsPerMinuteByLevel["debug"] = 30
+
+	config = client.Config()
+	if config.EnabledEnvironments[0] != "production" || config.ScrubExemptFields[0] != "token_type" ||
+		config.CorrelationHeaders[0] != "X-Request-Id" {
+		t.Error("modifying the snapshot should not change the configuration, got:", config)
+	}
+	if config.ItemsPerMinuteByLevel["debug"] != 10 {
+		t.Error("expected the limits to be copied, got:", config.ItemsPerMinuteByLevel)
+	}
+}
@@ -0,0 +1,205 @@
+package rollbar
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ConfigDecoderFunc decodes the content of a configuration file into v, like json.Unmarshal.
+type ConfigDecoderFunc func(data []byte, v interface{}) error
+
+var (
+	configDecodersLock sync.RWMutex
+	configDecoders     = map[string]ConfigDecoderFunc{
+		".json": json.Unmarshal,
+	}
+)
+
+// RegisterConfigFormat registers the decoder used by LoadConfig for the files with the given
+// extension, e.g. ".yaml". Only JSON files are supported by default, so that this package does not
+// depend on a YAML library; YAML files are supported by registering the Unmarshal function of
+// gopkg.in/yaml.v3 or a compatible package for the ".yaml" and ".yml" extensions.
+func RegisterConfigFormat(ext string, decode ConfigDecoderFunc) {
+	configDecodersLock.Lock()
+	defer configDecodersLock.Unlock()
+	configDecoders[strings.ToLower(ext)] = decode
+}
+
+// FileConfig holds the configuration loaded from a file by LoadConfig. Fields that are not set in
+// the file are left unchanged when the configuration is applied to a Client.
+type FileConfig struct {
+	Token       string `json:"token,omitempty" yaml:"token,omitempty"`
+	Environment string `json:"environment,omitempty" yaml:"environment,omitempty"`
+	CodeVersion string `json:"code_version,omitempty" yaml:"code_version,omitempty"`
+	ServerHost  string `json:"server_host,omitempty" yaml:"server_host,omitempty"`
+	ServerRoot  string `json:"server_root,omitempty" yaml:"server_root,omitempty"`
+	Endpoint    string `json:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+	Platform    string `json:"platform,omitempty" yaml:"platform,omitempty"`
+	Enabled     *bool  `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+
+	EnabledEnvironments []string `json:"enabled_environments,omitempty" yaml:"enabled_environments,omitempty"`
+
+	// ScrubFields, ScrubHeaders and ScrubCookies are regular expressions scrubbed in addition to
+	// DefaultScrubFields, DefaultScrubHeaders and DefaultScrubCookies respectively.
+	ScrubFields  []string `json:"scrub_fields,omitempty" yaml:"scrub_fields,omitempty"`
+	ScrubHeaders []string `json:"scrub_headers,omitempty" yaml:"scrub_headers,omitempty"`
+	ScrubCookies []string `json:"scrub_cookies,omitempty" yaml:"scrub_cookies,omitempty"`
+
+	ItemsPerMinute *int `json:"items_per_minute,omitempty" yaml:"items_per_minute,omitempty"`
+
+	// EscalationWindow is a duration such as "5m", see SetEscalationPolicy.
+	EscalationThreshold *int   `json:"escalation_threshold,omitempty" yaml:"escalation_threshold,omitempty"`
+	EscalationWindow    string `json:"escalation_window,omitempty" yaml:"escalation_window,omitempty"`
+
+	AttachTelemetry    *bool   `json:"attach_telemetry,omitempty" yaml:"attach_telemetry,omitempty"`
+	TelemetryMaxEvents *int    `json:"telemetry_max_events,omitempty" yaml:"telemetry_max_events,omitempty"`
+	TelemetryLevel     *string `json:"telemetry_level,omitempty" yaml:"telemetry_level,omitempty"`
+}
+
+// LoadConfig reads the configuration file at path, decoded according to its extension. Only JSON
+// files are supported unless a decoder is registered for the extension with RegisterConfigFormat,
+// e.g. for YAML files. The returned configuration is validated, so that applying it cannot fail
+// half-way:
+//
+//	config, err := rollbar.LoadConfig("/etc/myapp/rollbar.json")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	config.Apply(client)
+//
+// Settings made afterwards, such as InitFromEnv, override those of the file.
+func LoadConfig(path string) (*FileConfig, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	configDecodersLock.RLock()
+	decode, ok := configDecoders[ext]
+	configDecodersLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("rollbar: unsupported configuration file format %q", ext)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := &FileConfig{}
+	if err := decode(data, config); err != nil {
+		return nil, fmt.Errorf("rollbar: invalid configuration file %s: %v", path, err)
+	}
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("rollbar: invalid configuration file %s: %v", path, err)
+	}
+	return config, nil
+}
+
+// validate returns an error if a value of the configuration cannot be applied.
+func (config *FileConfig) validate() error {
+	for _, patterns := range [][]string{config.ScrubFields, config.ScrubHeaders, config.ScrubCookies} {
+		for _, pattern := range patterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return err
+			}
+		}
+	}
+	if config.ItemsPerMinute != nil && *config.ItemsPerMinute < 0 {
+		return fmt.Errorf("items_per_minute must not be negative")
+	}
+	if config.TelemetryMaxEvents != nil && *config.TelemetryMaxEvents < 0 {
+		return fmt.Errorf("telemetry_max_events must not be negative")
+	}
+	if config.TelemetryLevel != nil && *config.TelemetryLevel != "" {
+		if _, ok := telemetryLevels[*config.TelemetryLevel]; !ok {
+			return fmt.Errorf("unknown telemetry_level %q", *config.TelemetryLevel)
+		}
+	}
+	var window time.Duration
+	if config.EscalationWindow != "" {
+		var err error
+		if window, err = time.ParseDuration(config.EscalationWindow); err != nil {
+			return err
+		}
+	}
+	if config.EscalationThreshold != nil && *config.EscalationThreshold > 0 && window <= 0 {
+		return fmt.Errorf("escalation_threshold requires a positive escalation_window")
+	}
+	return nil
+}
+
+// Apply applies the configuration to the client in a single step, see Client.ApplyOptions. It
+// returns an error, and applies nothing, if the configuration was not returned by LoadConfig and
+// holds an invalid value.
+func (config *FileConfig) Apply(c *Client) error {
+	opts, err := config.Options()
+	if err != nil {
+		return err
+	}
+	c.ApplyOptions(opts...)
+	return nil
+}
+
+// Options returns the options applying the configuration, see Client.ApplyOptions.
+func (config *FileConfig) Options() ([]Option, error) {
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("rollbar: invalid configuration: %v", err)
+	}
+
+	var opts []Option
+	if config.Token != "" {
+		opts = append(opts, WithToken(config.Token))
+	}
+	if config.Environment != "" {
+		opts = append(opts, WithEnvironment(config.Environment))
+	}
+	if config.CodeVersion != "" {
+		opts = append(opts, WithCodeVersion(config.CodeVersion))
+	}
+	if config.ServerHost != "" {
+		opts = append(opts, WithServerHost(config.ServerHost))
+	}
+	if config.ServerRoot != "" {
+		opts = append(opts, WithServerRoot(config.ServerRoot))
+	}
+	if config.Endpoint != "" {
+		opts = append(opts, WithEndpoint(config.Endpoint))
+	}
+	if config.Platform != "" {
+		opts = append(opts, WithPlatform(config.Platform))
+	}
+	if config.Enabled != nil {
+		opts = append(opts, WithEnabled(*config.Enabled))
+	}
+	if config.EnabledEnvironments != nil {
+		opts = append(opts, WithEnabledEnvironments(config.EnabledEnvironments))
+	}
+	if len(config.ScrubFields) > 0 {
+		opts = append(opts, WithScrubFields(CombineScrubPatterns(append([]string{DefaultScrubFields}, config.ScrubFields...)...)))
+	}
+	if len(config.ScrubHeaders) > 0 {
+		opts = append(opts, WithScrubHeaders(CombineScrubPatterns(append([]string{DefaultScrubHeaders}, config.ScrubHeaders...)...)))
+	}
+	if len(config.ScrubCookies) > 0 {
+		opts = append(opts, WithScrubCookies(CombineScrubPatterns(append([]string{DefaultScrubCookies}, config.ScrubCookies...)...)))
+	}
+	if config.ItemsPerMinute != nil {
+		opts = append(opts, WithItemsPerMinute(*config.ItemsPerMinute))
+	}
+	if config.EscalationThreshold != nil {
+		window, _ := time.ParseDuration(config.EscalationWindow)
+		opts = append(opts, WithEscalationPolicy(*config.EscalationThreshold, window))
+	}
+	if config.AttachTelemetry != nil {
+		opts = append(opts, WithAttachTelemetry(*config.AttachTelemetry))
+	}
+	if config.TelemetryMaxEvents != nil {
+		opts = append(opts, WithTelemetryMaxEvents(*config.TelemetryMaxEvents))
+	}
+	if config.TelemetryLevel != nil {
+		opts = append(opts, WithTelemetryLevel(*config.TelemetryLevel))
+	}
+	return opts, nil
+}
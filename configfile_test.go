@@ -0,0 +1,108 @@
+package rollbar
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// writeTestConfig writes a configuration file in a temporary directory and returns its path and a
+// function removing the directory.
+func writeTestConfig(t *testing.T, name, content string) (string, func()) {
+	dir, err := ioutil.TempDir("", "rollbar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path, func() { os.RemoveAll(dir) }
+}
+
+func TestLoadConfig(t *testing.T) {
+	path, remove := writeTestConfig(t, "rollbar.json", `{
+		"environment": "staging",
+		"endpoint": "https://rollbar.example.com/api/1/item/",
+		"enabled": false,
+		"scrub_fields": ["ssn", "(?i)card_number"],
+		"items_per_minute": 30,
+		"escalation_threshold": 5,
+		"escalation_window": "2m",
+		"telemetry_level": "warning"
+	}`)
+	defer remove()
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := testClient()
+	client.SetEnvironment("production")
+	client.SetCodeVersion("abc123")
+	if err := config.Apply(client); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot := client.Config()
+	if snapshot.Environment != "staging" || snapshot.CodeVersion != "abc123" || snapshot.Enabled {
+		t.Error("wrong configuration, got:", snapshot.Environment, snapshot.CodeVersion, snapshot.Enabled)
+	}
+	if snapshot.Endpoint != "https://rollbar.example.com/api/1/item/" || snapshot.ItemsPerMinute != 30 {
+		t.Error("wrong configuration, got:", snapshot.Endpoint, snapshot.ItemsPerMinute)
+	}
+	if snapshot.EscalationThreshold != 5 || snapshot.EscalationWindow != 2*time.Minute || client.TelemetryLevel() != "warning" {
+		t.Error("wrong configuration, got:", snapshot.EscalationThreshold, snapshot.EscalationWindow, client.TelemetryLevel())
+	}
+	fields := regexp.MustCompile(snapshot.ScrubFields)
+	for _, field := range []string{"password", "ssn", "Card_Number"} {
+		if !fields.MatchString(field) {
+			t.Error("expected the field to be scrubbed:", field)
+		}
+	}
+}
+
+func TestLoadConfigFormats(t *testing.T) {
+	path, remove := writeTestConfig(t, "rollbar.yaml", `{"environment": "staging"}`)
+	defer remove()
+
+	if _, err := LoadConfig(path); err == nil {
+		t.Error("expected an error for an unregistered format")
+	}
+
+	RegisterConfigFormat(".yaml", json.Unmarshal)
+	defer func() {
+		configDecodersLock.Lock()
+		delete(configDecoders, ".yaml")
+		configDecodersLock.Unlock()
+	}()
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Environment != "staging" {
+		t.Error("wrong environment, got:", config.Environment)
+	}
+}
+
+func TestLoadConfigInvalid(t *testing.T) {
+	for _, content := range []string{
+		`{"environment": `,
+		`{"scrub_fields": ["(unclosed"]}`,
+		`{"items_per_minute": -1}`,
+		`{"telemetry_level": "verbose"}`,
+		`{"escalation_window": "soon"}`,
+		`{"escalation_threshold": 3}`,
+		`{"escalation_threshold": 3, "escalation_window": "0s"}`,
+	} {
+		path, remove := writeTestConfig(t, "rollbar.json", content)
+		if _, err := LoadConfig(path); err == nil {
+			t.Error("expected an error, got none for:", content)
+		}
+		remove()
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/rollbar/rollbar-go/contrib/chi
+
+go 1.14
+
+require (
+	github.com/go-chi/chi/v5 v5.1.0
+	github.com/rollbar/rollbar-go v1.3.0
+)
+
+replace github.com/rollbar/rollbar-go => ../..
@@ -0,0 +1,67 @@
+// Package rollbarchi provides a chi middleware which reports panics to Rollbar along with the
+// matched route pattern, e.g. "GET /users/{id}", as data.context so that occurrences are grouped
+// per route instead of per concrete URL.
+package rollbarchi
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rollbar/rollbar-go"
+)
+
+// An Option configures the middleware returned by Middleware. Besides the options of this package,
+// any rollbar.MiddlewareOption can be given, e.g. rollbar.WithMiddlewareAbandonment.
+type Option = rollbar.MiddlewareOption
+
+// WithClient sets the Client used to report items. By default the managed Client instance of the
+// rollbar package is used.
+func WithClient(client *rollbar.Client) Option {
+	return rollbar.WithMiddlewareClient(client)
+}
+
+// WithPanicLevel sets the level at which recovered panics are reported. The default is
+// rollbar.CRIT.
+func WithPanicLevel(level string) Option {
+	return rollbar.WithMiddlewarePanicLevel(level)
+}
+
+// WithStatusLevels makes the middleware report the responses of the next handlers at the level
+// mapped to their status code by levels, e.g. rollbar.DefaultStatusLevels to report server errors
+// as errors and throttled requests as warnings. By default only panics are reported.
+func WithStatusLevels(levels rollbar.StatusLevels) Option {
+	return rollbar.WithMiddlewareStatusLevels(levels)
+}
+
+// WithRepanic sets whether a recovered panic is panicked again after it has been reported, leaving
+// it to the http.Server. By default the middleware responds with a 500 status instead.
+func WithRepanic(repanic bool) Option {
+	return rollbar.WithMiddlewareRepanic(repanic)
+}
+
+// Middleware returns the net/http middleware of the rollbar package configured to resolve routes
+// with RouteResolver. It must be installed with the Use method of the chi router, as the route
+// context of chi is not available outside of the router.
+func Middleware(opts ...Option) func(http.Handler) http.Handler {
+	return rollbar.Middleware(append([]Option{rollbar.WithRouteResolver(RouteResolver)}, opts...)...)
+}
+
+// RouteResolver is a rollbar.RouteResolverFunc returning the route pattern matched by chi for the
+// request, including the patterns of mounted sub-routers, and the values of its URL parameters.
+func RouteResolver(r *http.Request) *rollbar.Route {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil {
+		return nil
+	}
+	pattern := rctx.RoutePattern()
+	if pattern == "" {
+		return nil
+	}
+	params := make(map[string]string, len(rctx.URLParams.Keys))
+	for i, key := range rctx.URLParams.Keys {
+		if i < len(rctx.URLParams.Values) {
+			params[key] = rctx.URLParams.Values[i]
+		}
+	}
+	return &rollbar.Route{Pattern: pattern, Params: params}
+}
@@ -0,0 +1,48 @@
+package rollbarchi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rollbar/rollbar-go/rollbartest"
+)
+
+func TestMiddleware(t *testing.T) {
+	client, rec := rollbartest.NewRecorderClient(t)
+	users := chi.NewRouter()
+	users.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+	r := chi.NewRouter()
+	r.Use(Middleware(WithClient(client)))
+	r.Mount("/users", users)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/users/42", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Error("expected status 500, got:", w.Code)
+	}
+	if rec.Len() != 1 {
+		t.Fatal("expected one item, got:", rec.Len())
+	}
+	data := rec.Items()[0]
+	if data["context"] != "GET /users/{id}" {
+		t.Error("wrong context, got:", data["context"])
+	}
+	request := data["request"].(map[string]interface{})
+	if request["route"] != "/users/{id}" {
+		t.Error("wrong route, got:", request["route"])
+	}
+	if request["params"].(map[string]interface{})["id"] != "42" {
+		t.Error("wrong params, got:", request["params"])
+	}
+}
+
+func TestRouteResolverWithoutRouter(t *testing.T) {
+	if route := RouteResolver(httptest.NewRequest("GET", "/users/42", nil)); route != nil {
+		t.Error("expected no route outside of a chi router, got:", route)
+	}
+}
@@ -0,0 +1,195 @@
+// Package rollbarecho provides an Echo v4 middleware which reports panics and errors returned by
+// handlers to Rollbar. Items include the request, the client IP as resolved by Echo, the person
+// associated with the request and the matched route as data.context.
+package rollbarecho
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rollbar/rollbar-go"
+)
+
+type config struct {
+	client      *rollbar.Client
+	panicLevel  string
+	errorLevel  string
+	repanic     bool
+	shouldError func(err error) bool
+	person      func(c echo.Context) *rollbar.Person
+	// statusLevels, when set, replaces errorLevel and shouldError.
+	statusLevels rollbar.StatusLevels
+}
+
+// An Option configures the middleware returned by Middleware.
+type Option func(*config)
+
+// WithClient sets the Client used to report items. By default the managed Client instance of the
+// rollbar package is used.
+func WithClient(client *rollbar.Client) Option {
+	return func(cfg *config) {
+		cfg.client = client
+	}
+}
+
+// WithPanicLevel sets the level at which recovered panics are reported. The default is
+// rollbar.CRIT.
+func WithPanicLevel(level string) Option {
+	return func(cfg *config) {
+		cfg.panicLevel = level
+	}
+}
+
+// WithErrorLevel sets the level at which errors returned by handlers are reported. The default is
+// rollbar.ERR. An empty level disables the reporting of returned errors.
+func WithErrorLevel(level string) Option {
+	return func(cfg *config) {
+		cfg.errorLevel = level
+	}
+}
+
+// WithErrorFilter sets the function deciding whether an error returned by a handler is reported.
+// By default all errors are reported except *echo.HTTPError values with a status code below 500.
+func WithErrorFilter(shouldReport func(err error) bool) Option {
+	return func(cfg *config) {
+		cfg.shouldError = shouldReport
+	}
+}
+
+// WithStatusLevels makes the middleware report the errors returned by handlers at the level mapped
+// by levels to the status code of their response: the code of *echo.HTTPError values, and 500 for
+// the other errors. E.g. rollbar.DefaultStatusLevels reports server errors as errors and
+// echo.ErrTooManyRequests as warnings, so that the services of all frameworks are tuned alike. It
+// takes precedence over WithErrorLevel and WithErrorFilter.
+func WithStatusLevels(levels rollbar.StatusLevels) Option {
+	return func(cfg *config) {
+		cfg.statusLevels = levels
+	}
+}
+
+// WithPersonFunc sets a function which returns the person associated with a request, e.g. from a
+// value stored in the echo.Context by an authentication middleware. A nil result leaves the
+// person unchanged. A person stored in the request context with rollbar.NewPersonContext is used
+// when this is not set.
+func WithPersonFunc(person func(c echo.Context) *rollbar.Person) Option {
+	return func(cfg *config) {
+		cfg.person = person
+	}
+}
+
+// WithRepanic sets whether a recovered panic is panicked again after it has been reported. By
+// default the middleware returns the panic as an error to be handled by the HTTPErrorHandler of
+// Echo instead.
+func WithRepanic(repanic bool) Option {
+	return func(cfg *config) {
+		cfg.repanic = repanic
+	}
+}
+
+// DefaultErrorFilter reports every error except *echo.HTTPError values with a status code below
+// 500, which usually describe problems with the request rather than the server.
+func DefaultErrorFilter(err error) bool {
+	return errorStatus(err) >= http.StatusInternalServerError
+}
+
+// errorStatus returns the status code of the response to err: the code of *echo.HTTPError values,
+// and 500 for the other errors.
+func errorStatus(err error) int {
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Code
+	}
+	return http.StatusInternalServerError
+}
+
+// Middleware returns an echo.MiddlewareFunc which recovers and reports panics of the next handler,
+// and reports the errors it returns. Panics with the value http.ErrAbortHandler are panicked again
+// without being reported.
+func Middleware(opts ...Option) echo.MiddlewareFunc {
+	cfg := &config{
+		panicLevel:  rollbar.CRIT,
+		errorLevel:  rollbar.ERR,
+		shouldError: DefaultErrorFilter,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (returnErr error) {
+			defer func() {
+				if rec := recover(); rec != nil {
+					if rec == http.ErrAbortHandler {
+						panic(rec)
+					}
+					err, ok := rec.(error)
+					if !ok {
+						err = errors.New(fmt.Sprint(rec))
+					}
+					r, extras := cfg.requestWithDetails(c)
+					rollbar.ReportHandlerError(r.Context(), cfg.client, cfg.panicLevel, r, err, 2, extras)
+					if cfg.repanic {
+						panic(rec)
+					}
+					returnErr = err
+				}
+			}()
+
+			err := next(c)
+			if level := cfg.errorLevelFor(err); level != "" {
+				r, extras := cfg.requestWithDetails(c)
+				rollbar.ReportHandlerError(r.Context(), cfg.client, level, r, err, 0, extras)
+			}
+			return err
+		}
+	}
+}
+
+// errorLevelFor returns the level at which err, returned by a handler, is reported, or an empty
+// string if it is not.
+func (cfg *config) errorLevelFor(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case cfg.statusLevels != nil:
+		return cfg.statusLevels.Level(errorStatus(err))
+	case cfg.shouldError(err):
+		return cfg.errorLevel
+	default:
+		return ""
+	}
+}
+
+// requestWithDetails returns the request of the echo.Context with a context carrying the client IP,
+// route and person, along with the extra custom data describing the matched route.
+func (cfg *config) requestWithDetails(c echo.Context) (*http.Request, map[string]interface{}) {
+	r := c.Request()
+	ctx := rollbar.NewClientIPContext(r.Context(), c.RealIP())
+	route := c.Path()
+	if route != "" {
+		ctx = rollbar.NewContextNameContext(ctx, r.Method+" "+route)
+	}
+	if cfg.person != nil {
+		if person := cfg.person(c); person != nil {
+			ctx = rollbar.NewPersonContext(ctx, person)
+		}
+	}
+
+	names := c.ParamNames()
+	values := c.ParamValues()
+	params := make(map[string]string, len(names))
+	for i, name := range names {
+		if i < len(values) {
+			params[name] = values[i]
+		}
+	}
+	extras := map[string]interface{}{
+		"echo": map[string]interface{}{
+			"route":  route,
+			"params": params,
+		},
+	}
+	return r.WithContext(ctx), extras
+}
@@ -0,0 +1,146 @@
+package rollbarecho
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rollbar/rollbar-go"
+	"github.com/rollbar/rollbar-go/rollbartest"
+)
+
+func testServer(opts ...Option) *echo.Echo {
+	e := echo.New()
+	e.Use(Middleware(opts...))
+	e.GET("/users/:id", func(c echo.Context) error {
+		panic("boom")
+	})
+	e.GET("/abort/:id", func(c echo.Context) error {
+		panic(http.ErrAbortHandler)
+	})
+	e.GET("/fail/:id", func(c echo.Context) error {
+		return errors.New("handler failed")
+	})
+	e.GET("/missing/:id", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusNotFound, "no such thing")
+	})
+	return e
+}
+
+func TestMiddlewarePanic(t *testing.T) {
+	client, rec := rollbartest.NewRecorderClient(t)
+	e := testServer(WithClient(client), WithPersonFunc(func(c echo.Context) *rollbar.Person {
+		return &rollbar.Person{Id: "7", Username: "echo"}
+	}))
+
+	req := httptest.NewRequest("GET", "/users/42", nil)
+	req.Header.Set(echo.HeaderXRealIP, "8.8.8.8")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Error("expected status 500, got:", w.Code)
+	}
+	if rec.Len() != 1 {
+		t.Fatal("expected one item, got:", rec.Len())
+	}
+	data := rec.Items()[0]
+	if data["level"] != rollbar.CRIT {
+		t.Error("wrong level, got:", data["level"])
+	}
+	if data["context"] != "GET /users/:id" {
+		t.Error("wrong context, got:", data["context"])
+	}
+	if data["request"].(map[string]interface{})["user_ip"] != "8.8.8.8" {
+		t.Error("wrong user_ip, got:", data["request"])
+	}
+	if data["person"].(map[string]interface{})["id"] != "7" {
+		t.Error("wrong person, got:", data["person"])
+	}
+	custom := data["custom"].(map[string]interface{})["echo"].(map[string]interface{})
+	if custom["params"].(map[string]interface{})["id"] != "42" {
+		t.Error("wrong params, got:", custom["params"])
+	}
+	chain := data["body"].(map[string]interface{})["trace_chain"].([]interface{})
+	frames := chain[0].(map[string]interface{})["frames"].([]interface{})
+	method := frames[0].(map[string]interface{})["method"].(string)
+	if !strings.Contains(method, "testServer") {
+		t.Error("expected stack to start in the panicking handler, got:", method)
+	}
+}
+
+func TestMiddlewareErrors(t *testing.T) {
+	client, rec := rollbartest.NewRecorderClient(t)
+	e := testServer(WithClient(client), WithErrorLevel(rollbar.WARN))
+
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/missing/1", nil))
+	if rec.Len() != 0 {
+		t.Fatal("expected client errors to be ignored, got:", rec.Items())
+	}
+
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/fail/1", nil))
+	if rec.Len() != 1 {
+		t.Fatal("expected one item, got:", rec.Len())
+	}
+	data := rec.Items()[0]
+	if data["level"] != rollbar.WARN {
+		t.Error("wrong level, got:", data["level"])
+	}
+	if data["title"] != "handler failed" {
+		t.Error("wrong title, got:", data["title"])
+	}
+	if data["context"] != "GET /fail/:id" {
+		t.Error("wrong context, got:", data["context"])
+	}
+}
+
+func TestMiddlewareRepanic(t *testing.T) {
+	client, rec := rollbartest.NewRecorderClient(t)
+	e := testServer(WithClient(client), WithRepanic(true))
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected the panic to be propagated")
+		}
+		if rec.Len() != 1 {
+			t.Error("expected one item, got:", rec.Len())
+		}
+	}()
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/42", nil))
+}
+
+func TestMiddlewareAbortHandler(t *testing.T) {
+	client, rec := rollbartest.NewRecorderClient(t)
+	e := testServer(WithClient(client))
+
+	defer func() {
+		if recover() != http.ErrAbortHandler {
+			t.Error("expected http.ErrAbortHandler to be propagated")
+		}
+		if rec.Len() != 0 {
+			t.Error("expected no item, got:", rec.Len())
+		}
+	}()
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/abort/42", nil))
+}
+
+func TestMiddlewareStatusLevels(t *testing.T) {
+	client, rec := rollbartest.NewRecorderClient(t)
+	e := testServer(WithClient(client), WithStatusLevels(rollbar.DefaultStatusLevels))
+	e.GET("/busy/:id", func(c echo.Context) error {
+		return echo.ErrTooManyRequests
+	})
+
+	for _, path := range []string{"/missing/1", "/busy/1", "/fail/1"} {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+	if rec.Len() != 2 {
+		t.Fatal("expected the 429 and 500 errors to be reported, got:", rec.Items())
+	}
+	if rec.Items()[0]["level"] != rollbar.WARN || rec.Items()[1]["level"] != rollbar.ERR {
+		t.Error("wrong levels, got:", rec.Items()[0]["level"], rec.Items()[1]["level"])
+	}
+}
@@ -0,0 +1,218 @@
+// Package rollbarfiber provides a Fiber middleware which reports panics and errors returned by
+// handlers to Rollbar, along with helpers converting fasthttp requests into rollbar.RequestInfo
+// values. Items include the request, the client IP as resolved by Fiber and the matched route as
+// data.context.
+package rollbarfiber
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rollbar/rollbar-go"
+	"github.com/valyala/fasthttp"
+)
+
+type config struct {
+	client      *rollbar.Client
+	panicLevel  string
+	errorLevel  string
+	repanic     bool
+	shouldError func(err error) bool
+	// statusLevels, when set, replaces errorLevel and shouldError.
+	statusLevels rollbar.StatusLevels
+}
+
+// An Option configures the middleware returned by Middleware.
+type Option func(*config)
+
+// WithClient sets the Client used to report items. By default the managed Client instance of the
+// rollbar package is used.
+func WithClient(client *rollbar.Client) Option {
+	return func(cfg *config) {
+		cfg.client = client
+	}
+}
+
+// WithPanicLevel sets the level at which recovered panics are reported. The default is
+// rollbar.CRIT.
+func WithPanicLevel(level string) Option {
+	return func(cfg *config) {
+		cfg.panicLevel = level
+	}
+}
+
+// WithErrorLevel sets the level at which errors returned by handlers are reported. The default is
+// rollbar.ERR. An empty level disables the reporting of returned errors.
+func WithErrorLevel(level string) Option {
+	return func(cfg *config) {
+		cfg.errorLevel = level
+	}
+}
+
+// WithErrorFilter sets the function deciding whether an error returned by a handler is reported.
+// By default all errors are reported except *fiber.Error values with a status code below 500.
+func WithErrorFilter(shouldReport func(err error) bool) Option {
+	return func(cfg *config) {
+		cfg.shouldError = shouldReport
+	}
+}
+
+// WithStatusLevels makes the middleware report the errors returned by handlers at the level mapped
+// by levels to the status code of their response: the code of *fiber.Error values, and 500 for the
+// other errors. E.g. rollbar.DefaultStatusLevels reports server errors as errors and
+// fiber.ErrTooManyRequests as warnings, so that the services of all frameworks are tuned alike. It
+// takes precedence over WithErrorLevel and WithErrorFilter.
+func WithStatusLevels(levels rollbar.StatusLevels) Option {
+	return func(cfg *config) {
+		cfg.statusLevels = levels
+	}
+}
+
+// WithRepanic sets whether a recovered panic is panicked again after it has been reported. By
+// default the middleware returns the panic as an error to be handled by the ErrorHandler of the
+// Fiber app instead.
+func WithRepanic(repanic bool) Option {
+	return func(cfg *config) {
+		cfg.repanic = repanic
+	}
+}
+
+// DefaultErrorFilter reports every error except *fiber.Error values with a status code below 500,
+// which usually describe problems with the request rather than the server.
+func DefaultErrorFilter(err error) bool {
+	return errorStatus(err) >= http.StatusInternalServerError
+}
+
+// errorStatus returns the status code of the response to err: the code of *fiber.Error values, and
+// 500 for the other errors.
+func errorStatus(err error) int {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return fiberErr.Code
+	}
+	return http.StatusInternalServerError
+}
+
+// Middleware returns a fiber.Handler which recovers and reports panics of the next handlers, and
+// reports the errors they return.
+func Middleware(opts ...Option) fiber.Handler {
+	cfg := &config{
+		panicLevel:  rollbar.CRIT,
+		errorLevel:  rollbar.ERR,
+		shouldError: DefaultErrorFilter,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(c *fiber.Ctx) (returnErr error) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				err, ok := rec.(error)
+				if !ok {
+					err = errors.New(fmt.Sprint(rec))
+				}
+				ctx, extras := contextWithDetails(c)
+				rollbar.ReportHandlerError(ctx, cfg.client, cfg.panicLevel, nil, err, 2, extras)
+				if cfg.repanic {
+					panic(rec)
+				}
+				returnErr = err
+			}
+		}()
+
+		err := c.Next()
+		if level := cfg.errorLevelFor(err); level != "" {
+			ctx, extras := contextWithDetails(c)
+			rollbar.ReportHandlerError(ctx, cfg.client, level, nil, err, 0, extras)
+		}
+		return err
+	}
+}
+
+// errorLevelFor returns the level at which err, returned by a handler, is reported, or an empty
+// string if it is not.
+func (cfg *config) errorLevelFor(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case cfg.statusLevels != nil:
+		return cfg.statusLevels.Level(errorStatus(err))
+	case cfg.shouldError(err):
+		return cfg.errorLevel
+	default:
+		return ""
+	}
+}
+
+// contextWithDetails returns the user context of the fiber.Ctx carrying the request info and route,
+// along with the extra custom data describing the matched route.
+func contextWithDetails(c *fiber.Ctx) (context.Context, map[string]interface{}) {
+	ctx := rollbar.NewRequestInfoContext(c.UserContext(), RequestInfo(c))
+	route := ""
+	if r := c.Route(); r != nil {
+		route = r.Path
+	}
+	if route != "" {
+		ctx = rollbar.NewContextNameContext(ctx, c.Method()+" "+route)
+	}
+	extras := map[string]interface{}{
+		"fiber": map[string]interface{}{
+			"route":  route,
+			"params": c.AllParams(),
+		},
+	}
+	return ctx, extras
+}
+
+// RequestInfo converts the request of the fiber.Ctx into a rollbar.RequestInfo. The client IP is
+// the one resolved by Fiber, which honours the ProxyHeader setting of the app.
+func RequestInfo(c *fiber.Ctx) *rollbar.RequestInfo {
+	info := requestInfo(c.Request())
+	info.URL = c.BaseURL() + c.OriginalURL()
+	info.UserIP = c.IP()
+	return info
+}
+
+// Extractor is a rollbar.RequestExtractorFunc handling *fiber.Ctx and *fasthttp.RequestCtx values.
+// Install it with rollbar.SetRequestExtractor to pass these values to rollbar.Error and the other
+// level functions in place of an *http.Request.
+func Extractor(v interface{}) (*rollbar.RequestInfo, bool) {
+	switch c := v.(type) {
+	case *fiber.Ctx:
+		return RequestInfo(c), true
+	case *fasthttp.RequestCtx:
+		info := requestInfo(&c.Request)
+		info.UserIP = c.RemoteIP().String()
+		return info, true
+	}
+	return nil, false
+}
+
+// requestInfo copies the details of a fasthttp request. The values are copied because fasthttp
+// reuses its buffers once the handler returns.
+func requestInfo(req *fasthttp.Request) *rollbar.RequestInfo {
+	info := &rollbar.RequestInfo{
+		URL:     req.URI().String(),
+		Method:  string(req.Header.Method()),
+		Headers: map[string][]string{},
+		Query:   map[string][]string{},
+		Form:    map[string][]string{},
+	}
+	req.Header.VisitAll(func(key, value []byte) {
+		k := string(key)
+		info.Headers[k] = append(info.Headers[k], string(value))
+	})
+	req.URI().QueryArgs().VisitAll(func(key, value []byte) {
+		k := string(key)
+		info.Query[k] = append(info.Query[k], string(value))
+	})
+	req.PostArgs().VisitAll(func(key, value []byte) {
+		k := string(key)
+		info.Form[k] = append(info.Form[k], string(value))
+	})
+	return info
+}
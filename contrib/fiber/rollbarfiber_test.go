@@ -0,0 +1,161 @@
+package rollbarfiber
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rollbar/rollbar-go"
+	"github.com/rollbar/rollbar-go/rollbartest"
+	"github.com/valyala/fasthttp"
+)
+
+func testApp(opts ...Option) *fiber.App {
+	app := fiber.New()
+	app.Use(Middleware(opts...))
+	app.Get("/users/:id", func(c *fiber.Ctx) error {
+		panic("boom")
+	})
+	app.Get("/fail/:id", func(c *fiber.Ctx) error {
+		return errors.New("handler failed")
+	})
+	app.Get("/missing/:id", func(c *fiber.Ctx) error {
+		return fiber.NewError(fiber.StatusNotFound, "no such thing")
+	})
+	return app
+}
+
+func TestMiddlewarePanic(t *testing.T) {
+	client, rec := rollbartest.NewRecorderClient(t)
+	app := testApp(WithClient(client))
+
+	req := httptest.NewRequest("GET", "/users/42?password=secret&ok=1", nil)
+	req.Header.Set("Authorization", "Bearer abc")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Error("expected status 500, got:", resp.StatusCode)
+	}
+	if rec.Len() != 1 {
+		t.Fatal("expected one item, got:", rec.Len())
+	}
+	data := rec.Items()[0]
+	if data["level"] != rollbar.CRIT {
+		t.Error("wrong level, got:", data["level"])
+	}
+	if data["context"] != "GET /users/:id" {
+		t.Error("wrong context, got:", data["context"])
+	}
+	request := data["request"].(map[string]interface{})
+	if request["method"] != "GET" {
+		t.Error("wrong method, got:", request["method"])
+	}
+	if !strings.HasSuffix(request["url"].(string), "/users/42?password=[FILTERED]&ok=1") {
+		t.Error("wrong url, got:", request["url"])
+	}
+	get := request["GET"].(map[string]interface{})
+	if get["password"] != rollbar.FILTERED || get["ok"] != "1" {
+		t.Error("wrong GET params, got:", get)
+	}
+	if request["headers"].(map[string]interface{})["Authorization"] != rollbar.FILTERED {
+		t.Error("expected Authorization to be scrubbed, got:", request["headers"])
+	}
+	custom := data["custom"].(map[string]interface{})["fiber"].(map[string]interface{})
+	if custom["params"].(map[string]interface{})["id"] != "42" {
+		t.Error("wrong params, got:", custom["params"])
+	}
+	chain := data["body"].(map[string]interface{})["trace_chain"].([]interface{})
+	frames := chain[0].(map[string]interface{})["frames"].([]interface{})
+	method := frames[0].(map[string]interface{})["method"].(string)
+	if !strings.Contains(method, "testApp") {
+		t.Error("expected stack to start in the panicking handler, got:", method)
+	}
+}
+
+func TestMiddlewareErrors(t *testing.T) {
+	client, rec := rollbartest.NewRecorderClient(t)
+	app := testApp(WithClient(client), WithErrorLevel(rollbar.WARN))
+
+	if _, err := app.Test(httptest.NewRequest("GET", "/missing/1", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Len() != 0 {
+		t.Fatal("expected client errors to be ignored, got:", rec.Items())
+	}
+
+	if _, err := app.Test(httptest.NewRequest("GET", "/fail/1", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Len() != 1 {
+		t.Fatal("expected one item, got:", rec.Len())
+	}
+	data := rec.Items()[0]
+	if data["level"] != rollbar.WARN {
+		t.Error("wrong level, got:", data["level"])
+	}
+	if data["title"] != "handler failed" {
+		t.Error("wrong title, got:", data["title"])
+	}
+	if data["context"] != "GET /fail/:id" {
+		t.Error("wrong context, got:", data["context"])
+	}
+}
+
+func TestExtractor(t *testing.T) {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("http://example.com/things?a=1&a=2")
+	ctx.Request.Header.SetMethod("POST")
+	ctx.Request.Header.SetContentType("application/x-www-form-urlencoded")
+	ctx.Request.Header.Set("X-Foo", "bar")
+	ctx.Request.SetBodyString("name=foo")
+
+	info, ok := Extractor(ctx)
+	if !ok {
+		t.Fatal("expected *fasthttp.RequestCtx to be handled")
+	}
+	if info.Method != "POST" {
+		t.Error("wrong method, got:", info.Method)
+	}
+	if info.URL != "http://example.com/things?a=1&a=2" {
+		t.Error("wrong url, got:", info.URL)
+	}
+	if len(info.Query["a"]) != 2 {
+		t.Error("wrong query, got:", info.Query)
+	}
+	if info.Headers["X-Foo"][0] != "bar" {
+		t.Error("wrong headers, got:", info.Headers)
+	}
+	if info.Form["name"][0] != "foo" {
+		t.Error("wrong form, got:", info.Form)
+	}
+
+	if _, ok := Extractor("not a request"); ok {
+		t.Error("expected other values to be ignored")
+	}
+}
+
+func TestMiddlewareStatusLevels(t *testing.T) {
+	client, rec := rollbartest.NewRecorderClient(t)
+	app := testApp(WithClient(client), WithStatusLevels(rollbar.DefaultStatusLevels))
+	app.Get("/busy/:id", func(c *fiber.Ctx) error {
+		return fiber.ErrTooManyRequests
+	})
+
+	for _, path := range []string{"/missing/1", "/busy/1", "/fail/1"} {
+		if _, err := app.Test(httptest.NewRequest("GET", path, nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if rec.Len() != 2 {
+		t.Fatal("expected the 429 and 500 errors to be reported, got:", rec.Items())
+	}
+	if rec.Items()[0]["level"] != rollbar.WARN || rec.Items()[1]["level"] != rollbar.ERR {
+		t.Error("wrong levels, got:", rec.Items()[0]["level"], rec.Items()[1]["level"])
+	}
+}
@@ -0,0 +1,167 @@
+// Package rollbargin provides a gin middleware which reports panics and the errors attached to the
+// gin.Context to Rollbar. Items include the request, the client IP as resolved by gin (honoring
+// its trusted proxy configuration), the matched route template as data.context and the route
+// parameters.
+package rollbargin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rollbar/rollbar-go"
+)
+
+type config struct {
+	client     *rollbar.Client
+	panicLevel string
+	errorLevel string
+	typeLevels map[gin.ErrorType]string
+	repanic    bool
+	// statusLevels, when set, replaces errorLevel.
+	statusLevels rollbar.StatusLevels
+}
+
+// An Option configures the middleware returned by Middleware.
+type Option func(*config)
+
+// WithClient sets the Client used to report items. By default the managed Client instance of the
+// rollbar package is used.
+func WithClient(client *rollbar.Client) Option {
+	return func(cfg *config) {
+		cfg.client = client
+	}
+}
+
+// WithPanicLevel sets the level at which recovered panics are reported. The default is
+// rollbar.CRIT.
+func WithPanicLevel(level string) Option {
+	return func(cfg *config) {
+		cfg.panicLevel = level
+	}
+}
+
+// WithErrorLevel sets the level at which the errors attached to the gin.Context with c.Error are
+// reported. The default is rollbar.ERR. An empty level disables the reporting of these errors.
+func WithErrorLevel(level string) Option {
+	return func(cfg *config) {
+		cfg.errorLevel = level
+	}
+}
+
+// WithErrorTypeLevel sets the level at which errors of the given gin.ErrorType are reported,
+// taking precedence over WithErrorLevel. An empty level disables the reporting of errors of
+// this type, e.g. WithErrorTypeLevel(gin.ErrorTypeBind, "") ignores binding errors.
+func WithErrorTypeLevel(errorType gin.ErrorType, level string) Option {
+	return func(cfg *config) {
+		cfg.typeLevels[errorType] = level
+	}
+}
+
+// WithStatusLevels makes the middleware report the errors attached to the gin.Context at the level
+// mapped by levels to the status code of the response, e.g. rollbar.DefaultStatusLevels to report
+// the errors of server errors as errors and of throttled requests as warnings, so that the
+// services of all frameworks are tuned alike. It takes precedence over WithErrorLevel, while
+// WithErrorTypeLevel still takes precedence over it.
+func WithStatusLevels(levels rollbar.StatusLevels) Option {
+	return func(cfg *config) {
+		cfg.statusLevels = levels
+	}
+}
+
+// WithRepanic sets whether a recovered panic is panicked again after it has been reported, e.g.
+// to let gin.Recovery or another outer middleware handle it. By default the middleware aborts the
+// request with http.StatusInternalServerError instead.
+func WithRepanic(repanic bool) Option {
+	return func(cfg *config) {
+		cfg.repanic = repanic
+	}
+}
+
+// Middleware returns a gin.HandlerFunc which recovers and reports panics of the handlers that
+// follow it, and reports the errors attached to the gin.Context once they have returned. Panics
+// with the value http.ErrAbortHandler are panicked again without being reported.
+func Middleware(opts ...Option) gin.HandlerFunc {
+	cfg := &config{
+		panicLevel: rollbar.CRIT,
+		errorLevel: rollbar.ERR,
+		typeLevels: map[gin.ErrorType]string{},
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(c *gin.Context) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+				err, ok := rec.(error)
+				if !ok {
+					err = errors.New(fmt.Sprint(rec))
+				}
+				r, extras := requestWithDetails(c)
+				rollbar.ReportHandlerError(r.Context(), cfg.client, cfg.panicLevel, r, err, 2, extras)
+				if cfg.repanic {
+					panic(rec)
+				}
+				c.AbortWithStatus(http.StatusInternalServerError)
+			}
+		}()
+
+		c.Next()
+
+		if len(c.Errors) == 0 {
+			return
+		}
+		r, extras := requestWithDetails(c)
+		for _, ginErr := range c.Errors {
+			level := cfg.levelFor(ginErr.Type, c.Writer.Status())
+			if level == "" {
+				continue
+			}
+			errExtras := extras
+			if ginErr.Meta != nil {
+				errExtras = map[string]interface{}{"gin": extras["gin"], "meta": ginErr.Meta}
+			}
+			rollbar.ReportHandlerError(r.Context(), cfg.client, level, r, ginErr.Err, 0, errExtras)
+		}
+	}
+}
+
+// levelFor returns the level at which an error of the given type is reported, status being the
+// status code of the response, or an empty string if it is not.
+func (cfg *config) levelFor(errorType gin.ErrorType, status int) string {
+	if level, ok := cfg.typeLevels[errorType]; ok {
+		return level
+	}
+	if cfg.statusLevels != nil {
+		return cfg.statusLevels.Level(status)
+	}
+	return cfg.errorLevel
+}
+
+// requestWithDetails returns the request of the gin.Context with a context carrying the client IP
+// and route template, along with the extra custom data describing the matched route.
+func requestWithDetails(c *gin.Context) (*http.Request, map[string]interface{}) {
+	ctx := rollbar.NewClientIPContext(c.Request.Context(), c.ClientIP())
+	route := c.FullPath()
+	if route != "" {
+		ctx = rollbar.NewContextNameContext(ctx, c.Request.Method+" "+route)
+	}
+
+	params := make(map[string]string, len(c.Params))
+	for _, param := range c.Params {
+		params[param.Key] = param.Value
+	}
+	extras := map[string]interface{}{
+		"gin": map[string]interface{}{
+			"route":   route,
+			"params":  params,
+			"handler": c.HandlerName(),
+		},
+	}
+	return c.Request.WithContext(ctx), extras
+}
@@ -0,0 +1,160 @@
+package rollbargin
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rollbar/rollbar-go"
+	"github.com/rollbar/rollbar-go/rollbartest"
+)
+
+func testRouter(opts ...Option) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Middleware(opts...))
+	router.GET("/users/:id", func(c *gin.Context) {
+		panic("boom")
+	})
+	router.GET("/abort/:id", func(c *gin.Context) {
+		panic(http.ErrAbortHandler)
+	})
+	router.GET("/errors/:id", func(c *gin.Context) {
+		c.Error(errors.New("private failure"))
+		c.Error(errors.New("bad input")).SetType(gin.ErrorTypeBind)
+		c.Status(http.StatusBadRequest)
+	})
+	return router
+}
+
+func TestMiddlewarePanic(t *testing.T) {
+	client, rec := rollbartest.NewRecorderClient(t)
+	router := testRouter(WithClient(client))
+
+	req := httptest.NewRequest("GET", "/users/42", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("X-Forwarded-For", "8.8.8.8")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Error("expected status 500, got:", w.Code)
+	}
+	if rec.Len() != 1 {
+		t.Fatal("expected one item, got:", rec.Len())
+	}
+	data := rec.Items()[0]
+	if data["level"] != rollbar.CRIT {
+		t.Error("wrong level, got:", data["level"])
+	}
+	if data["context"] != "GET /users/:id" {
+		t.Error("wrong context, got:", data["context"])
+	}
+	request := data["request"].(map[string]interface{})
+	if request["user_ip"] != "8.8.8.8" {
+		t.Error("wrong user_ip, got:", request["user_ip"])
+	}
+	custom := data["custom"].(map[string]interface{})["gin"].(map[string]interface{})
+	if custom["route"] != "/users/:id" {
+		t.Error("wrong route, got:", custom["route"])
+	}
+	if custom["params"].(map[string]interface{})["id"] != "42" {
+		t.Error("wrong params, got:", custom["params"])
+	}
+	chain := data["body"].(map[string]interface{})["trace_chain"].([]interface{})
+	frames := chain[0].(map[string]interface{})["frames"].([]interface{})
+	method := frames[0].(map[string]interface{})["method"].(string)
+	if !strings.Contains(method, "testRouter") {
+		t.Error("expected stack to start in the panicking handler, got:", method)
+	}
+}
+
+func TestMiddlewareUntrustedProxy(t *testing.T) {
+	client, rec := rollbartest.NewRecorderClient(t)
+	router := testRouter(WithClient(client))
+	router.SetTrustedProxies(nil)
+
+	req := httptest.NewRequest("GET", "/users/42", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("X-Forwarded-For", "8.8.8.8")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	request := rec.Items()[0]["request"].(map[string]interface{})
+	if request["user_ip"] != "10.0.0.1" {
+		t.Error("wrong user_ip, got:", request["user_ip"])
+	}
+}
+
+func TestMiddlewareRepanic(t *testing.T) {
+	client, rec := rollbartest.NewRecorderClient(t)
+	router := testRouter(WithClient(client), WithRepanic(true))
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected the panic to be propagated")
+		}
+		if rec.Len() != 1 {
+			t.Error("expected one item, got:", rec.Len())
+		}
+	}()
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/42", nil))
+}
+
+func TestMiddlewareAbortHandler(t *testing.T) {
+	client, rec := rollbartest.NewRecorderClient(t)
+	router := testRouter(WithClient(client))
+
+	defer func() {
+		if recover() != http.ErrAbortHandler {
+			t.Error("expected http.ErrAbortHandler to be propagated")
+		}
+		if rec.Len() != 0 {
+			t.Error("expected no item, got:", rec.Len())
+		}
+	}()
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/abort/42", nil))
+}
+
+func TestMiddlewareErrors(t *testing.T) {
+	client, rec := rollbartest.NewRecorderClient(t)
+	router := testRouter(WithClient(client), WithErrorLevel(rollbar.WARN),
+		WithErrorTypeLevel(gin.ErrorTypeBind, ""))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/errors/1", nil))
+
+	if w.Code != http.StatusBadRequest {
+		t.Error("expected status 400, got:", w.Code)
+	}
+	if rec.Len() != 1 {
+		t.Fatal("expected one item, got:", rec.Len())
+	}
+	data := rec.Items()[0]
+	if data["level"] != rollbar.WARN {
+		t.Error("wrong level, got:", data["level"])
+	}
+	if data["title"] != "private failure" {
+		t.Error("wrong title, got:", data["title"])
+	}
+}
+
+func TestMiddlewareStatusLevels(t *testing.T) {
+	client, rec := rollbartest.NewRecorderClient(t)
+	router := testRouter(WithClient(client), WithStatusLevels(rollbar.DefaultStatusLevels))
+	router.GET("/busy/:id", func(c *gin.Context) {
+		c.AbortWithError(http.StatusTooManyRequests, errors.New("slow down"))
+	})
+
+	for _, path := range []string{"/errors/1", "/busy/1"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+	if rec.Len() != 1 {
+		t.Fatal("expected only the error of the 429 response to be reported, got:", rec.Items())
+	}
+	if rec.Items()[0]["level"] != rollbar.WARN || rec.Items()[0]["title"] != "slow down" {
+		t.Error("wrong item, got:", rec.Items()[0]["level"], rec.Items()[0]["title"])
+	}
+}
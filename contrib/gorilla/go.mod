@@ -0,0 +1,10 @@
+module github.com/rollbar/rollbar-go/contrib/gorilla
+
+go 1.20
+
+require (
+	github.com/gorilla/mux v1.8.1
+	github.com/rollbar/rollbar-go v1.3.0
+)
+
+replace github.com/rollbar/rollbar-go => ../..
@@ -0,0 +1,61 @@
+// Package rollbargorilla provides a gorilla/mux middleware which reports panics to Rollbar along
+// with the matched path template, e.g. "GET /users/{id}", as data.context so that occurrences are
+// grouped per route instead of per concrete URL.
+package rollbargorilla
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/rollbar/rollbar-go"
+)
+
+// An Option configures the middleware returned by Middleware. Besides the options of this package,
+// any rollbar.MiddlewareOption can be given, e.g. rollbar.WithMiddlewareAbandonment.
+type Option = rollbar.MiddlewareOption
+
+// WithClient sets the Client used to report items. By default the managed Client instance of the
+// rollbar package is used.
+func WithClient(client *rollbar.Client) Option {
+	return rollbar.WithMiddlewareClient(client)
+}
+
+// WithPanicLevel sets the level at which recovered panics are reported. The default is
+// rollbar.CRIT.
+func WithPanicLevel(level string) Option {
+	return rollbar.WithMiddlewarePanicLevel(level)
+}
+
+// WithStatusLevels makes the middleware report the responses of the next handlers at the level
+// mapped to their status code by levels, e.g. rollbar.DefaultStatusLevels to report server errors
+// as errors and throttled requests as warnings. By default only panics are reported.
+func WithStatusLevels(levels rollbar.StatusLevels) Option {
+	return rollbar.WithMiddlewareStatusLevels(levels)
+}
+
+// WithRepanic sets whether a recovered panic is panicked again after it has been reported, leaving
+// it to the http.Server. By default the middleware responds with a 500 status instead.
+func WithRepanic(repanic bool) Option {
+	return rollbar.WithMiddlewareRepanic(repanic)
+}
+
+// Middleware returns the net/http middleware of the rollbar package configured to resolve routes
+// with RouteResolver. It must be installed with the Use method of the mux.Router, which runs
+// middlewares once a route has been matched.
+func Middleware(opts ...Option) mux.MiddlewareFunc {
+	return rollbar.Middleware(append([]Option{rollbar.WithRouteResolver(RouteResolver)}, opts...)...)
+}
+
+// RouteResolver is a rollbar.RouteResolverFunc returning the path template of the route matched by
+// gorilla/mux for the request and the values of its variables.
+func RouteResolver(r *http.Request) *rollbar.Route {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return nil
+	}
+	pattern, err := route.GetPathTemplate()
+	if err != nil || pattern == "" {
+		return nil
+	}
+	return &rollbar.Route{Pattern: pattern, Params: mux.Vars(r)}
+}
@@ -0,0 +1,105 @@
+package rollbargrpc
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/rollbar/rollbar-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryClientInterceptor returns a grpc.UnaryClientInterceptor which records every outgoing call
+// as a network telemetry event with the method, status code and duration of the call, like the
+// http.RoundTripper installed by rollbar.EnableNetworkTelemetry does for HTTP requests. Only the
+// WithClient option applies to it.
+func UnaryClientInterceptor(opts ...Option) grpc.UnaryClientInterceptor {
+	cfg := newConfig(opts)
+
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, callOpts...)
+		cfg.recordCall(cc.Target(), method, status.Code(err), start)
+		return err
+	}
+}
+
+// StreamClientInterceptor returns a grpc.StreamClientInterceptor which records every outgoing
+// stream as a network telemetry event once it ends, with the method, status code and duration of
+// the stream. Only the WithClient option applies to it.
+func StreamClientInterceptor(opts ...Option) grpc.StreamClientInterceptor {
+	cfg := newConfig(opts)
+
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		cs, err := streamer(ctx, desc, cc, method, callOpts...)
+		if err != nil {
+			cfg.recordCall(cc.Target(), method, status.Code(err), start)
+			return nil, err
+		}
+		return &clientStream{ClientStream: cs, cfg: cfg, target: cc.Target(), method: method, start: start}, nil
+	}
+}
+
+// clientStream wraps a grpc.ClientStream to record it once it ends, which is when receiving a
+// message fails, with io.EOF when the stream ended successfully.
+type clientStream struct {
+	grpc.ClientStream
+	cfg    *config
+	target string
+	method string
+	start  time.Time
+	once   sync.Once
+}
+
+// RecvMsg implements grpc.ClientStream.
+func (s *clientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		code := codes.OK
+		if err != io.EOF {
+			code = status.Code(err)
+		}
+		s.once.Do(func() {
+			s.cfg.recordCall(s.target, s.method, code, s.start)
+		})
+	}
+	return err
+}
+
+// recordCall captures a network telemetry event describing a call.
+func (cfg *config) recordCall(target, method string, code codes.Code, start time.Time) {
+	end := time.Now()
+	body := map[string]interface{}{
+		"subtype":            "grpc",
+		"method":             method,
+		"url":                target + method,
+		"status_code":        int(code),
+		"status":             code.String(),
+		"start_timestamp_ms": start.UnixNano() / int64(time.Millisecond),
+		"end_timestamp_ms":   end.UnixNano() / int64(time.Millisecond),
+		"duration_ms":        end.Sub(start).Milliseconds(),
+	}
+	level := telemetryLevel(code)
+	if cfg.client != nil {
+		cfg.client.CaptureTelemetryEvent("network", level, body)
+		return
+	}
+	rollbar.CaptureTelemetryEvent("network", level, body)
+}
+
+// telemetryLevel returns the level of the telemetry event of a call, mirroring the levels of HTTP
+// network events: critical for failures of the server and error for those of the call.
+func telemetryLevel(code codes.Code) string {
+	switch code {
+	case codes.OK:
+		return "info"
+	case codes.Unknown, codes.Internal, codes.DataLoss, codes.Unavailable, codes.Unimplemented:
+		return "critical"
+	default:
+		return "error"
+	}
+}
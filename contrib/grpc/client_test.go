@@ -0,0 +1,110 @@
+package rollbargrpc
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/rollbar/rollbar-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+func testConn(t *testing.T) *grpc.ClientConn {
+	cc, err := grpc.NewClient("passthrough:///backend:443", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cc.Close() })
+	return cc
+}
+
+func telemetryBody(t *testing.T, client *rollbar.Client, i int) (map[string]interface{}, string) {
+	items := client.Telemetry.GetQueueItems()
+	if len(items) <= i {
+		t.Fatalf("expected at least %d telemetry events, got: %d", i+1, len(items))
+	}
+	event := items[i].(map[string]interface{})
+	if event["type"] != "network" {
+		t.Error("wrong type, got:", event["type"])
+	}
+	return event["body"].(map[string]interface{}), event["level"].(string)
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	client := rollbar.NewSync("token", "test", "", "", "")
+	interceptor := UnaryClientInterceptor(WithClient(client))
+	cc := testConn(t)
+
+	interceptor(context.Background(), "/pkg.Service/Get", nil, nil, cc,
+		func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			return nil
+		})
+	interceptor(context.Background(), "/pkg.Service/Get", nil, nil, cc,
+		func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			return status.Error(codes.Unavailable, "down")
+		})
+
+	body, level := telemetryBody(t, client, 0)
+	if level != "info" || body["status"] != "OK" || body["method"] != "/pkg.Service/Get" {
+		t.Error("wrong event, got:", level, body)
+	}
+	if body["url"] != "passthrough:///backend:443/pkg.Service/Get" || body["subtype"] != "grpc" {
+		t.Error("wrong event, got:", body)
+	}
+	if _, ok := body["duration_ms"]; !ok {
+		t.Error("expected a duration, got:", body)
+	}
+	body, level = telemetryBody(t, client, 1)
+	if level != "critical" || body["status_code"] != int(codes.Unavailable) {
+		t.Error("wrong event, got:", level, body)
+	}
+}
+
+type fakeClientStream struct {
+	grpc.ClientStream
+	messages int
+}
+
+func (s *fakeClientStream) RecvMsg(m interface{}) error {
+	s.messages--
+	if s.messages < 0 {
+		return io.EOF
+	}
+	return nil
+}
+
+func TestStreamClientInterceptor(t *testing.T) {
+	client := rollbar.NewSync("token", "test", "", "", "")
+	interceptor := StreamClientInterceptor(WithClient(client))
+	cc := testConn(t)
+
+	cs, err := interceptor(context.Background(), &grpc.StreamDesc{ServerStreams: true}, cc, "/pkg.Service/Watch",
+		func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return &fakeClientStream{messages: 2}, nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for cs.RecvMsg(nil) == nil {
+	}
+	cs.RecvMsg(nil)
+	if n := len(client.Telemetry.GetQueueItems()); n != 1 {
+		t.Fatal("expected the stream to be recorded once, got:", n)
+	}
+	body, level := telemetryBody(t, client, 0)
+	if level != "info" || body["method"] != "/pkg.Service/Watch" {
+		t.Error("wrong event, got:", level, body)
+	}
+
+	_, err = interceptor(context.Background(), &grpc.StreamDesc{}, cc, "/pkg.Service/Watch",
+		func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return nil, status.Error(codes.PermissionDenied, "nope")
+		})
+	body, level = telemetryBody(t, client, 1)
+	if err == nil || level != "error" || body["status"] != "PermissionDenied" {
+		t.Error("wrong event, got:", level, body)
+	}
+}
@@ -0,0 +1,283 @@
+// Package rollbargrpc provides gRPC server interceptors which report panics and failed calls to
+// Rollbar, and client interceptors which record outgoing calls as network telemetry events. Items
+// reported by the server interceptors include the full method of the call as data.context and the
+// incoming metadata.
+package rollbargrpc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/rollbar/rollbar-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultErrorCodes are the status codes of the errors returned by handlers which are reported by
+// default, as they usually indicate a problem with the server rather than with the call.
+var DefaultErrorCodes = []codes.Code{codes.Unknown, codes.Internal, codes.DataLoss}
+
+type config struct {
+	client     *rollbar.Client
+	panicLevel string
+	errorLevel string
+	errorCodes map[codes.Code]bool
+	// statusLevels, when set, replaces errorLevel and errorCodes.
+	statusLevels rollbar.StatusLevels
+}
+
+// An Option configures the interceptors.
+type Option func(*config)
+
+// WithClient sets the Client used to report items. By default the managed Client instance of the
+// rollbar package is used.
+func WithClient(client *rollbar.Client) Option {
+	return func(cfg *config) {
+		cfg.client = client
+	}
+}
+
+// WithPanicLevel sets the level at which recovered panics are reported. The default is
+// rollbar.CRIT.
+func WithPanicLevel(level string) Option {
+	return func(cfg *config) {
+		cfg.panicLevel = level
+	}
+}
+
+// WithErrorLevel sets the level at which errors returned by handlers are reported. The default is
+// rollbar.ERR. An empty level disables the reporting of returned errors.
+func WithErrorLevel(level string) Option {
+	return func(cfg *config) {
+		cfg.errorLevel = level
+	}
+}
+
+// WithErrorCodes sets the status codes of the errors returned by handlers which are reported. The
+// default is DefaultErrorCodes.
+func WithErrorCodes(errorCodes ...codes.Code) Option {
+	return func(cfg *config) {
+		cfg.errorCodes = codeSet(errorCodes)
+	}
+}
+
+// WithStatusLevels makes the interceptors report the errors returned by handlers at the level
+// mapped by levels to the HTTP status code matching their status code, e.g.
+// rollbar.DefaultStatusLevels to report Internal errors as errors and ResourceExhausted errors as
+// warnings while ignoring NotFound errors, so that HTTP and gRPC services are tuned alike. It takes
+// precedence over WithErrorLevel and WithErrorCodes.
+func WithStatusLevels(levels rollbar.StatusLevels) Option {
+	return func(cfg *config) {
+		cfg.statusLevels = levels
+	}
+}
+
+func newConfig(opts []Option) *config {
+	cfg := &config{
+		panicLevel: rollbar.CRIT,
+		errorLevel: rollbar.ERR,
+		errorCodes: codeSet(DefaultErrorCodes),
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+func codeSet(errorCodes []codes.Code) map[codes.Code]bool {
+	set := make(map[codes.Code]bool, len(errorCodes))
+	for _, code := range errorCodes {
+		set[code] = true
+	}
+	return set
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor which recovers and reports the
+// panics of handlers, and reports the errors they return. A recovered panic is returned to the
+// caller as an error with the Internal status code.
+func UnaryServerInterceptor(opts ...Option) grpc.UnaryServerInterceptor {
+	cfg := newConfig(opts)
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, returnErr error) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				returnErr = cfg.reportPanic(ctx, rec, info.FullMethod, nil)
+			}
+		}()
+
+		resp, err := handler(ctx, req)
+		cfg.reportError(ctx, err, info.FullMethod, nil)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a grpc.StreamServerInterceptor which recovers and reports the
+// panics of handlers, including those raised while receiving or sending messages, and reports the
+// errors they return. A recovered panic is returned to the caller as an error with the Internal
+// status code. Items include the number of messages received and sent on the stream.
+func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
+	cfg := newConfig(opts)
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (returnErr error) {
+		stream := &serverStream{ServerStream: ss, cfg: cfg, info: info}
+		defer func() {
+			if rec := recover(); rec != nil {
+				returnErr = cfg.reportPanic(ss.Context(), rec, info.FullMethod, stream)
+			}
+		}()
+
+		err := handler(srv, stream)
+		cfg.reportError(ss.Context(), err, info.FullMethod, stream)
+		return err
+	}
+}
+
+// serverStream wraps a grpc.ServerStream to count messages and to recover the panics raised while
+// receiving or sending them, e.g. by a codec.
+type serverStream struct {
+	grpc.ServerStream
+	cfg      *config
+	info     *grpc.StreamServerInfo
+	received int64
+	sent     int64
+	// panicked is set once a panic raised while receiving or sending has been reported, so that
+	// the error returned in its place is not reported again by the interceptor.
+	panicked int32
+}
+
+// RecvMsg implements grpc.ServerStream.
+func (s *serverStream) RecvMsg(m interface{}) (returnErr error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			returnErr = s.cfg.reportPanic(s.Context(), rec, s.info.FullMethod, s)
+			atomic.StoreInt32(&s.panicked, 1)
+		}
+	}()
+
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		atomic.AddInt64(&s.received, 1)
+	}
+	return err
+}
+
+// SendMsg implements grpc.ServerStream.
+func (s *serverStream) SendMsg(m interface{}) (returnErr error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			returnErr = s.cfg.reportPanic(s.Context(), rec, s.info.FullMethod, s)
+			atomic.StoreInt32(&s.panicked, 1)
+		}
+	}()
+
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		atomic.AddInt64(&s.sent, 1)
+	}
+	return err
+}
+
+// reportPanic reports a recovered panic and returns the error to return to the caller. It must be
+// called from the deferred function recovering the panic.
+func (cfg *config) reportPanic(ctx context.Context, rec interface{}, method string, stream *serverStream) error {
+	err, ok := rec.(error)
+	if !ok {
+		err = fmt.Errorf("%v", rec)
+	}
+	cfg.report(ctx, cfg.panicLevel, err, 2, method, stream)
+	return status.Errorf(codes.Internal, "panic: %v", rec)
+}
+
+// reportError reports err if it is not nil and its status code is one of the reported codes.
+func (cfg *config) reportError(ctx context.Context, err error, method string, stream *serverStream) {
+	if err == nil {
+		return
+	}
+	level := cfg.errorLevel
+	if cfg.statusLevels != nil {
+		level = cfg.statusLevels.Level(httpStatus(status.Code(err)))
+	} else if !cfg.errorCodes[status.Code(err)] {
+		return
+	}
+	if level == "" {
+		return
+	}
+	if stream != nil && atomic.LoadInt32(&stream.panicked) == 1 {
+		return
+	}
+	cfg.report(ctx, level, err, 0, method, stream)
+}
+
+// httpStatuses are the HTTP status codes matching the gRPC status codes, as mapped by grpc-gateway.
+var httpStatuses = map[codes.Code]int{
+	codes.OK:                 http.StatusOK,
+	codes.Canceled:           499,
+	codes.Unknown:            http.StatusInternalServerError,
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+	codes.NotFound:           http.StatusNotFound,
+	codes.AlreadyExists:      http.StatusConflict,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+	codes.ResourceExhausted:  http.StatusTooManyRequests,
+	codes.FailedPrecondition: http.StatusBadRequest,
+	codes.Aborted:            http.StatusConflict,
+	codes.OutOfRange:         http.StatusBadRequest,
+	codes.Unimplemented:      http.StatusNotImplemented,
+	codes.Internal:           http.StatusInternalServerError,
+	codes.Unavailable:        http.StatusServiceUnavailable,
+	codes.DataLoss:           http.StatusInternalServerError,
+}
+
+// httpStatus returns the HTTP status code matching a gRPC status code.
+func httpStatus(code codes.Code) int {
+	if status, ok := httpStatuses[code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// report reports err with the stack trace starting skip frames above the function calling
+// reportPanic or reportError, as rollbar.ReportHandlerError does for its caller.
+func (cfg *config) report(ctx context.Context, level string, err error, skip int, method string, stream *serverStream) {
+	ctx = rollbar.NewContextNameContext(ctx, method)
+	extras := map[string]interface{}{
+		"grpc": cfg.details(ctx, method, stream),
+	}
+	rollbar.ReportHandlerError(ctx, cfg.client, level, nil, err, skip+2, extras)
+}
+
+// details describes the call, scrubbing the incoming metadata with the scrub headers of the Client.
+func (cfg *config) details(ctx context.Context, method string, stream *serverStream) map[string]interface{} {
+	details := map[string]interface{}{
+		"method": method,
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		scrubHeaders := rollbar.ScrubHeaders()
+		if cfg.client != nil {
+			scrubHeaders = cfg.client.ScrubHeaders()
+		}
+		values := make(map[string]interface{}, len(md))
+		for key, value := range md {
+			if scrubHeaders != nil && scrubHeaders.MatchString(http.CanonicalHeaderKey(key)) {
+				values[key] = rollbar.FILTERED
+			} else if len(value) == 1 {
+				values[key] = value[0]
+			} else {
+				values[key] = value
+			}
+		}
+		details["metadata"] = values
+	}
+	if stream != nil {
+		details["client_stream"] = stream.info.IsClientStream
+		details["server_stream"] = stream.info.IsServerStream
+		details["messages_received"] = atomic.LoadInt64(&stream.received)
+		details["messages_sent"] = atomic.LoadInt64(&stream.sent)
+	}
+	return details
+}
@@ -0,0 +1,160 @@
+package rollbargrpc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rollbar/rollbar-go"
+	"github.com/rollbar/rollbar-go/rollbartest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type fakeStream struct {
+	grpc.ServerStream
+	ctx      context.Context
+	messages int
+	panicOn  int
+}
+
+func (s *fakeStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeStream) RecvMsg(m interface{}) error {
+	s.messages++
+	if s.messages == s.panicOn {
+		panic("codec exploded")
+	}
+	return nil
+}
+
+func (s *fakeStream) SendMsg(m interface{}) error {
+	return nil
+}
+
+func testContext() context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		"authorization", "Bearer secret",
+		"x-request-id", "abc",
+	))
+}
+
+func streamPanicHandler(srv interface{}, stream grpc.ServerStream) error {
+	for i := 0; i < 2; i++ {
+		if err := stream.RecvMsg(nil); err != nil {
+			return err
+		}
+		stream.SendMsg(nil)
+	}
+	panic("stream handler exploded")
+}
+
+func TestStreamServerInterceptorPanic(t *testing.T) {
+	client, rec := rollbartest.NewRecorderClient(t)
+	interceptor := StreamServerInterceptor(WithClient(client))
+	info := &grpc.StreamServerInfo{FullMethod: "/pkg.Service/Watch", IsServerStream: true}
+
+	err := interceptor(nil, &fakeStream{ctx: testContext()}, info, streamPanicHandler)
+	if status.Code(err) != codes.Internal {
+		t.Error("expected an Internal error, got:", err)
+	}
+	if rec.Len() != 1 {
+		t.Fatal("expected one item, got:", rec.Len())
+	}
+	data := rec.Items()[0]
+	if data["level"] != rollbar.CRIT {
+		t.Error("wrong level, got:", data["level"])
+	}
+	if data["context"] != "/pkg.Service/Watch" {
+		t.Error("wrong context, got:", data["context"])
+	}
+	details := data["custom"].(map[string]interface{})["grpc"].(map[string]interface{})
+	if details["messages_received"] != float64(2) || details["messages_sent"] != float64(2) {
+		t.Error("wrong message counts, got:", details)
+	}
+	if details["server_stream"] != true {
+		t.Error("wrong stream kind, got:", details)
+	}
+	md := details["metadata"].(map[string]interface{})
+	if md["authorization"] != rollbar.FILTERED || md["x-request-id"] != "abc" {
+		t.Error("wrong metadata, got:", md)
+	}
+	chain := data["body"].(map[string]interface{})["trace_chain"].([]interface{})
+	frames := chain[0].(map[string]interface{})["frames"].([]interface{})
+	method := frames[0].(map[string]interface{})["method"].(string)
+	if !strings.Contains(method, "streamPanicHandler") {
+		t.Error("expected stack to start in the panicking handler, got:", method)
+	}
+}
+
+func TestStreamServerInterceptorRecvPanic(t *testing.T) {
+	client, rec := rollbartest.NewRecorderClient(t)
+	interceptor := StreamServerInterceptor(WithClient(client))
+	info := &grpc.StreamServerInfo{FullMethod: "/pkg.Service/Upload", IsClientStream: true}
+
+	err := interceptor(nil, &fakeStream{ctx: testContext(), panicOn: 2}, info, streamPanicHandler)
+	if status.Code(err) != codes.Internal {
+		t.Error("expected an Internal error, got:", err)
+	}
+	if rec.Len() != 1 {
+		t.Fatal("expected one item, got:", rec.Len())
+	}
+	details := rec.Items()[0]["custom"].(map[string]interface{})["grpc"].(map[string]interface{})
+	if details["messages_received"] != float64(1) {
+		t.Error("wrong message count, got:", details)
+	}
+}
+
+func TestUnaryServerInterceptorStatusLevels(t *testing.T) {
+	client, rec := rollbartest.NewRecorderClient(t)
+	interceptor := UnaryServerInterceptor(WithClient(client), WithStatusLevels(rollbar.DefaultStatusLevels))
+	info := &grpc.UnaryServerInfo{FullMethod: "/pkg.Service/Get"}
+
+	for _, code := range []codes.Code{codes.NotFound, codes.ResourceExhausted, codes.Unavailable} {
+		interceptor(testContext(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, status.Error(code, "failed")
+		})
+	}
+	if rec.Len() != 2 {
+		t.Fatal("expected NotFound to be ignored, got:", rec.Items())
+	}
+	if rec.Items()[0]["level"] != rollbar.WARN || rec.Items()[1]["level"] != rollbar.ERR {
+		t.Error("wrong levels, got:", rec.Items()[0]["level"], rec.Items()[1]["level"])
+	}
+}
+
+func TestUnaryServerInterceptorErrors(t *testing.T) {
+	client, rec := rollbartest.NewRecorderClient(t)
+	interceptor := UnaryServerInterceptor(WithClient(client), WithErrorLevel(rollbar.WARN))
+	info := &grpc.UnaryServerInfo{FullMethod: "/pkg.Service/Get"}
+
+	interceptor(testContext(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "no such thing")
+	})
+	if rec.Len() != 0 {
+		t.Fatal("expected NotFound to be ignored, got:", rec.Items())
+	}
+
+	_, err := interceptor(testContext(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, errors.New("database unreachable")
+	})
+	if err == nil || rec.Len() != 1 {
+		t.Fatal("expected one item, got:", rec.Len())
+	}
+	data := rec.Items()[0]
+	if data["level"] != rollbar.WARN || data["title"] != "database unreachable" {
+		t.Error("wrong item, got:", data["level"], data["title"])
+	}
+
+	_, err = interceptor(testContext(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic("boom")
+	})
+	if status.Code(err) != codes.Internal || rec.Len() != 2 {
+		t.Error("expected the panic to be reported and returned, got:", err)
+	}
+}
@@ -0,0 +1,144 @@
+// Package rollbargrpcgateway reports the panics and failed calls of a grpc-gateway reverse proxy to
+// Rollbar along with both the HTTP route and the gRPC method it maps to, e.g.
+// "/pkg.Users/GetUser (GET /v1/users/{id})", as data.context, so that occurrences are grouped by
+// API operation rather than by concrete URL:
+//
+//	mux := runtime.NewServeMux(
+//		rollbargrpcgateway.ServeMuxOption(),
+//		runtime.WithErrorHandler(rollbargrpcgateway.ErrorHandler(runtime.DefaultHTTPErrorHandler)),
+//	)
+//	http.ListenAndServe(":8080", rollbargrpcgateway.Middleware()(mux))
+package rollbargrpcgateway
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/rollbar/rollbar-go"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultErrorCodes are the status codes of the errors returned by the gRPC server which are
+// reported by default, as they usually indicate a problem with the server rather than with the
+// request.
+var DefaultErrorCodes = []codes.Code{codes.Unknown, codes.Internal, codes.DataLoss, codes.Unavailable}
+
+type operationKey struct{}
+
+// operation records the gRPC method and HTTP path pattern of a request once grpc-gateway has
+// matched it, which happens after middlewares are called.
+type operation struct {
+	method  string
+	pattern string
+}
+
+// Middleware returns the net/http middleware of the rollbar package configured to resolve routes
+// with RouteResolver. It must wrap the grpc-gateway ServeMux, which must be created with
+// ServeMuxOption.
+func Middleware(opts ...rollbar.MiddlewareOption) func(http.Handler) http.Handler {
+	middleware := rollbar.Middleware(append([]rollbar.MiddlewareOption{rollbar.WithRouteResolver(RouteResolver)}, opts...)...)
+	return func(next http.Handler) http.Handler {
+		handler := middleware(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), operationKey{}, &operation{})
+			handler.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// ServeMuxOption returns the option of runtime.NewServeMux which records the gRPC method and HTTP
+// path pattern matched for requests going through Middleware.
+func ServeMuxOption() runtime.ServeMuxOption {
+	return runtime.WithMetadata(func(ctx context.Context, r *http.Request) metadata.MD {
+		if op, ok := r.Context().Value(operationKey{}).(*operation); ok {
+			op.method, _ = runtime.RPCMethod(ctx)
+			op.pattern, _ = runtime.HTTPPathPattern(ctx)
+		}
+		return nil
+	})
+}
+
+// RouteResolver is a rollbar.RouteResolverFunc returning the HTTP path pattern matched by
+// grpc-gateway for the request, with the gRPC method it maps to as the operation.
+func RouteResolver(r *http.Request) *rollbar.Route {
+	op, ok := r.Context().Value(operationKey{}).(*operation)
+	if !ok || op.pattern == "" {
+		return nil
+	}
+	return &rollbar.Route{Pattern: op.pattern, Operation: op.method}
+}
+
+type config struct {
+	client     *rollbar.Client
+	errorLevel string
+	errorCodes map[codes.Code]bool
+}
+
+// An Option configures ErrorHandler.
+type Option func(*config)
+
+// WithClient sets the Client used to report items. By default the managed Client instance of the
+// rollbar package is used.
+func WithClient(client *rollbar.Client) Option {
+	return func(cfg *config) {
+		cfg.client = client
+	}
+}
+
+// WithErrorLevel sets the level at which errors are reported. The default is rollbar.ERR.
+func WithErrorLevel(level string) Option {
+	return func(cfg *config) {
+		cfg.errorLevel = level
+	}
+}
+
+// WithErrorCodes sets the status codes of the errors which are reported. The default is
+// DefaultErrorCodes.
+func WithErrorCodes(errorCodes ...codes.Code) Option {
+	return func(cfg *config) {
+		cfg.errorCodes = make(map[codes.Code]bool, len(errorCodes))
+		for _, code := range errorCodes {
+			cfg.errorCodes[code] = true
+		}
+	}
+}
+
+// ErrorHandler returns a grpc-gateway error handler which reports the errors returned by the gRPC
+// server with the status codes given by WithErrorCodes, and then calls next, which is usually
+// runtime.DefaultHTTPErrorHandler, to write the response.
+func ErrorHandler(next runtime.ErrorHandlerFunc, opts ...Option) runtime.ErrorHandlerFunc {
+	cfg := &config{
+		errorLevel: rollbar.ERR,
+	}
+	WithErrorCodes(DefaultErrorCodes...)(cfg)
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+		if code := status.Code(err); cfg.errorCodes[code] {
+			cfg.report(ctx, r, err, code)
+		}
+		next(ctx, mux, marshaler, w, r, err)
+	}
+}
+
+func (cfg *config) report(ctx context.Context, r *http.Request, err error, code codes.Code) {
+	route := &rollbar.Route{}
+	route.Operation, _ = runtime.RPCMethod(ctx)
+	route.Pattern, _ = runtime.HTTPPathPattern(ctx)
+	rctx := r.Context()
+	if route.Pattern != "" {
+		rctx = rollbar.NewRouteContext(rctx, route)
+		rctx = rollbar.NewContextNameContext(rctx, route.Name(r.Method))
+	}
+	extras := map[string]interface{}{
+		"grpc": map[string]interface{}{
+			"code": code.String(),
+		},
+	}
+	rollbar.ReportHandlerError(rctx, cfg.client, cfg.errorLevel, r.WithContext(rctx), err, 1, extras)
+}
@@ -0,0 +1,80 @@
+package rollbargrpcgateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/rollbar/rollbar-go"
+	"github.com/rollbar/rollbar-go/rollbartest"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// testMux returns a ServeMux with a handler behaving like the ones generated by grpc-gateway for
+// the GetUser method, which fails with err or panics if err is nil.
+func testMux(client *rollbar.Client, err error) http.Handler {
+	mux := runtime.NewServeMux(
+		ServeMuxOption(),
+		runtime.WithErrorHandler(ErrorHandler(runtime.DefaultHTTPErrorHandler, WithClient(client))),
+	)
+	mux.HandlePath("GET", "/v1/users/{id}", func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		ctx, annotateErr := runtime.AnnotateContext(r.Context(), mux, r, "/pkg.Users/GetUser", runtime.WithHTTPPathPattern("/v1/users/{id}"))
+		if annotateErr != nil {
+			panic(annotateErr)
+		}
+		if err == nil {
+			panic("backend exploded")
+		}
+		_, marshaler := runtime.MarshalerForRequest(mux, r)
+		runtime.HTTPError(ctx, mux, marshaler, w, r, err)
+	})
+	return Middleware(rollbar.WithMiddlewareClient(client))(mux)
+}
+
+func TestMiddlewarePanic(t *testing.T) {
+	client, rec := rollbartest.NewRecorderClient(t)
+	w := httptest.NewRecorder()
+	testMux(client, nil).ServeHTTP(w, httptest.NewRequest("GET", "/v1/users/42", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Error("expected status 500, got:", w.Code)
+	}
+	if rec.Len() != 1 {
+		t.Fatal("expected one item, got:", rec.Len())
+	}
+	data := rec.Items()[0]
+	if data["context"] != "/pkg.Users/GetUser (GET /v1/users/{id})" {
+		t.Error("wrong context, got:", data["context"])
+	}
+	request := data["request"].(map[string]interface{})
+	if request["route"] != "/v1/users/{id}" || request["operation"] != "/pkg.Users/GetUser" {
+		t.Error("wrong route, got:", request["route"], request["operation"])
+	}
+}
+
+func TestErrorHandler(t *testing.T) {
+	client, rec := rollbartest.NewRecorderClient(t)
+	w := httptest.NewRecorder()
+	testMux(client, status.Error(codes.NotFound, "no such user")).ServeHTTP(w, httptest.NewRequest("GET", "/v1/users/42", nil))
+	if w.Code != http.StatusNotFound || rec.Len() != 0 {
+		t.Fatal("expected NotFound to be ignored, got:", w.Code, rec.Items())
+	}
+
+	w = httptest.NewRecorder()
+	testMux(client, status.Error(codes.Unavailable, "database down")).ServeHTTP(w, httptest.NewRequest("GET", "/v1/users/42", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Error("expected the response of the next error handler, got:", w.Code)
+	}
+	if rec.Len() != 1 {
+		t.Fatal("expected one item, got:", rec.Len())
+	}
+	data := rec.Items()[0]
+	if data["level"] != rollbar.ERR || data["context"] != "/pkg.Users/GetUser (GET /v1/users/{id})" {
+		t.Error("wrong item, got:", data["level"], data["context"])
+	}
+	if data["custom"].(map[string]interface{})["grpc"].(map[string]interface{})["code"] != "Unavailable" {
+		t.Error("wrong code, got:", data["custom"])
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/rollbar/rollbar-go/contrib/lambda
+
+go 1.18
+
+require (
+	github.com/aws/aws-lambda-go v1.47.0
+	github.com/rollbar/rollbar-go v1.3.0
+)
+
+replace github.com/rollbar/rollbar-go => ../..
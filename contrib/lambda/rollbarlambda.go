@@ -0,0 +1,25 @@
+// Package rollbarlambda reads the details of an AWS Lambda invocation from lambdacontext so that
+// rollbar.LambdaWrapper reports the request ID and invoked function ARN along with panics and
+// deadline warnings:
+//
+//	lambda.Start(rollbar.LambdaWrapper(handler, rollbar.WithLambdaContext(rollbarlambda.FromContext)))
+package rollbarlambda
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/rollbar/rollbar-go"
+)
+
+var _ rollbar.LambdaContextFunc = FromContext
+
+// FromContext returns the AWS request ID and invoked function ARN stored in ctx by the Lambda
+// runtime. It is a rollbar.LambdaContextFunc.
+func FromContext(ctx context.Context) (requestID, functionARN string) {
+	lc, ok := lambdacontext.FromContext(ctx)
+	if !ok {
+		return "", ""
+	}
+	return lc.AwsRequestID, lc.InvokedFunctionArn
+}
@@ -0,0 +1,26 @@
+package rollbarlambda
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+)
+
+func TestFromContext(t *testing.T) {
+	ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{
+		AwsRequestID:       "c6af9ac6-7b61-11e6-9a41-93e812345678",
+		InvokedFunctionArn: "arn:aws:lambda:us-east-1:123456789012:function:handler",
+	})
+	requestID, arn := FromContext(ctx)
+	if requestID != "c6af9ac6-7b61-11e6-9a41-93e812345678" {
+		t.Error("wrong request ID, got:", requestID)
+	}
+	if arn != "arn:aws:lambda:us-east-1:123456789012:function:handler" {
+		t.Error("wrong ARN, got:", arn)
+	}
+
+	if requestID, arn := FromContext(context.Background()); requestID != "" || arn != "" {
+		t.Error("expected no details outside of Lambda, got:", requestID, arn)
+	}
+}
@@ -0,0 +1,7 @@
+module github.com/rollbar/rollbar-go/contrib/slog
+
+go 1.21
+
+require github.com/rollbar/rollbar-go v1.3.0
+
+replace github.com/rollbar/rollbar-go => ../..
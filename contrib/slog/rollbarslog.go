@@ -0,0 +1,269 @@
+// Package rollbarslog provides a log/slog Handler which reports records at the warning level and
+// above to Rollbar as items, and records lower levels as telemetry events so that they are sent
+// along with the next item as breadcrumbs:
+//
+//	logger := slog.New(rollbarslog.NewHandler(client, nil))
+//	logger.Error("payment failed", "err", err, slog.Group("order", "id", 42))
+//
+// The attributes of records are flattened into custom data, with the names of groups joined to
+// the keys by dots, e.g. "order.id". The first attribute holding an error is reported as the error
+// of the item, with the stack trace starting at the logging call.
+package rollbarslog
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"time"
+
+	"github.com/rollbar/rollbar-go"
+)
+
+// HandlerOptions are the options of a Handler.
+type HandlerOptions struct {
+	// Level is the minimum level of the records handled. The default is slog.LevelInfo.
+	Level slog.Leveler
+	// ReportLevel is the minimum level of the records reported as items. Records of lower levels
+	// are recorded as telemetry events. The default is slog.LevelWarn.
+	ReportLevel slog.Leveler
+}
+
+// Handler is a slog.Handler reporting records to Rollbar.
+type Handler struct {
+	client *rollbar.Client
+	opts   HandlerOptions
+	prefix string
+	attrs  map[string]interface{}
+}
+
+var _ slog.Handler = (*Handler)(nil)
+
+// NewHandler returns a Handler reporting records with client, or with the managed Client instance
+// of the rollbar package if client is nil. A nil opts is equivalent to the zero HandlerOptions.
+func NewHandler(client *rollbar.Client, opts *HandlerOptions) *Handler {
+	h := &Handler{client: client}
+	if opts != nil {
+		h.opts = *opts
+	}
+	if h.opts.Level == nil {
+		h.opts.Level = slog.LevelInfo
+	}
+	if h.opts.ReportLevel == nil {
+		h.opts.ReportLevel = slog.LevelWarn
+	}
+	return h
+}
+
+// Enabled reports whether records of the given level are handled.
+func (h *Handler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.opts.Level.Level()
+}
+
+// WithAttrs returns a Handler adding attrs to every record.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := h.clone()
+	for _, attr := range attrs {
+		flatten(h2.attrs, h2.prefix, attr)
+	}
+	return h2
+}
+
+// WithGroup returns a Handler qualifying the keys of the attributes of subsequent records with
+// the group name.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := h.clone()
+	h2.prefix += name + "."
+	return h2
+}
+
+func (h *Handler) clone() *Handler {
+	h2 := *h
+	h2.attrs = make(map[string]interface{}, len(h.attrs))
+	for k, v := range h.attrs {
+		h2.attrs[k] = v
+	}
+	return &h2
+}
+
+// Handle reports the record as an item if its level is at least the report level, or records it
+// as a telemetry event otherwise.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	custom, err := h.custom(r)
+	level := Level(r.Level)
+
+	if r.Level < h.opts.ReportLevel.Level() {
+		h.captureTelemetry(ctx, level, r.Message, custom)
+		return nil
+	}
+
+	if err == nil {
+		if h.client != nil {
+			h.client.MessageWithExtrasAndContext(ctx, level, r.Message, custom)
+		} else {
+			rollbar.MessageWithExtrasAndContext(ctx, level, r.Message, custom)
+		}
+		return nil
+	}
+	custom["message"] = r.Message
+	h.report(ctx, level, err, r.PC, custom)
+	return nil
+}
+
+// custom returns the attributes of the handler and of the record flattened into custom data, and
+// the first attribute holding an error, if any.
+func (h *Handler) custom(r slog.Record) (map[string]interface{}, error) {
+	custom := make(map[string]interface{}, len(h.attrs)+r.NumAttrs())
+	for k, v := range h.attrs {
+		custom[k] = v
+	}
+	var err error
+	r.Attrs(func(attr slog.Attr) bool {
+		if e, ok := attr.Value.Resolve().Any().(error); ok && err == nil {
+			err = e
+		}
+		flatten(custom, h.prefix, attr)
+		return true
+	})
+	return custom, err
+}
+
+// captureTelemetry records a log telemetry event with the message and custom data of a record,
+// within ctx so that the event goes to the telemetry queue of the request if there is one.
+func (h *Handler) captureTelemetry(ctx context.Context, level, message string, custom map[string]interface{}) {
+	custom["message"] = message
+	if h.client != nil {
+		h.client.CaptureTelemetryEventWithContext(ctx, "log", level, custom)
+	} else {
+		rollbar.CaptureTelemetryEventWithContext(ctx, "log", level, custom)
+	}
+}
+
+// report reports err with the stack trace starting at the logging call identified by pc, as
+// recorded by slog.
+func (h *Handler) report(ctx context.Context, level string, err error, pc uintptr, custom map[string]interface{}) {
+	// Without the logging call in the stack, start the stack above Handle.
+	skip := 1
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(1, pcs)
+	for i, p := range pcs[:n] {
+		if p == pc {
+			skip = i - 1
+			break
+		}
+	}
+	rollbar.ReportHandlerError(ctx, h.client, level, nil, err, skip+1, custom)
+}
+
+// Level returns the Rollbar level corresponding to a slog level. Levels above slog.LevelError,
+// such as slog.LevelError+4, are reported as critical.
+func Level(level slog.Level) string {
+	switch {
+	case level > slog.LevelError:
+		return rollbar.CRIT
+	case level >= slog.LevelError:
+		return rollbar.ERR
+	case level >= slog.LevelWarn:
+		return rollbar.WARN
+	case level >= slog.LevelInfo:
+		return rollbar.INFO
+	default:
+		return rollbar.DEBUG
+	}
+}
+
+// flatten adds attr to custom, with the names of groups joined to the keys by dots.
+func flatten(custom map[string]interface{}, prefix string, attr slog.Attr) {
+	value := attr.Value.Resolve()
+	if value.Kind() == slog.KindGroup {
+		group := value.Group()
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+		for _, a := range group {
+			flatten(custom, prefix, a)
+		}
+		return
+	}
+	if attr.Key == "" {
+		return
+	}
+	custom[prefix+attr.Key] = jsonValue(value)
+}
+
+func jsonValue(value slog.Value) interface{} {
+	switch value.Kind() {
+	case slog.KindTime:
+		return value.Time().Format(time.RFC3339Nano)
+	case slog.KindDuration:
+		return value.Duration().String()
+	case slog.KindAny:
+		if err, ok := value.Any().(error); ok {
+			return err.Error()
+		}
+	}
+	return value.Any()
+}
+
+// TeeHandler is a slog.Handler passing records to another handler, such as the handler of the
+// application logger, while recording them as telemetry events, so that logs become breadcrumbs
+// without being reported as items and without changing the global log output:
+//
+//	logger := slog.New(rollbarslog.NewTeeHandler(slog.NewJSONHandler(os.Stderr, nil), client, nil))
+type TeeHandler struct {
+	next      slog.Handler
+	telemetry *Handler
+}
+
+var _ slog.Handler = (*TeeHandler)(nil)
+
+// NewTeeHandler returns a TeeHandler passing records to next and recording those of at least the
+// given level as telemetry events of client, or of the managed Client instance of the rollbar
+// package if client is nil. A nil level is equivalent to slog.LevelInfo.
+func NewTeeHandler(next slog.Handler, client *rollbar.Client, level slog.Leveler) *TeeHandler {
+	return &TeeHandler{
+		next:      next,
+		telemetry: NewHandler(client, &HandlerOptions{Level: level}),
+	}
+}
+
+// Enabled reports whether records of the given level are handled by the next handler or recorded
+// as telemetry events.
+func (h *TeeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.telemetry.Enabled(ctx, level) || h.next.Enabled(ctx, level)
+}
+
+// Handle records the record as a telemetry event if its level is enabled for telemetry, and passes
+// it to the next handler if its level is enabled there.
+func (h *TeeHandler) Handle(ctx context.Context, r slog.Record) error {
+	if h.telemetry.Enabled(ctx, r.Level) {
+		custom, _ := h.telemetry.custom(r)
+		h.telemetry.captureTelemetry(ctx, Level(r.Level), r.Message, custom)
+	}
+	if !h.next.Enabled(ctx, r.Level) {
+		return nil
+	}
+	return h.next.Handle(ctx, r)
+}
+
+// WithAttrs returns a TeeHandler adding attrs to every record.
+func (h *TeeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &TeeHandler{
+		next:      h.next.WithAttrs(attrs),
+		telemetry: h.telemetry.WithAttrs(attrs).(*Handler),
+	}
+}
+
+// WithGroup returns a TeeHandler qualifying the keys of the attributes of subsequent records with
+// the group name.
+func (h *TeeHandler) WithGroup(name string) slog.Handler {
+	return &TeeHandler{
+		next:      h.next.WithGroup(name),
+		telemetry: h.telemetry.WithGroup(name).(*Handler),
+	}
+}
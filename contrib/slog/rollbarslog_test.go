@@ -0,0 +1,104 @@
+package rollbarslog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/rollbar/rollbar-go"
+	"github.com/rollbar/rollbar-go/rollbartest"
+)
+
+func logPaymentFailure(logger *slog.Logger) {
+	logger.Error("payment failed", "err", errors.New("card declined"), slog.Group("order", "id", 42))
+}
+
+func TestHandlerError(t *testing.T) {
+	client, rec := rollbartest.NewRecorderClient(t)
+	logger := slog.New(NewHandler(client, nil)).With("service", "billing").WithGroup("req")
+
+	logger.Debug("ignored")
+	logger.Info("charging card", "amount", 1299)
+	logPaymentFailure(logger)
+
+	if rec.Len() != 1 {
+		t.Fatal("expected one item, got:", rec.Len())
+	}
+	data := rec.Items()[0]
+	if data["level"] != rollbar.ERR || data["title"] != "card declined" {
+		t.Error("wrong item, got:", data["level"], data["title"])
+	}
+	custom := data["custom"].(map[string]interface{})
+	if custom["service"] != "billing" || custom["req.order.id"] != float64(42) || custom["req.err"] != "card declined" {
+		t.Error("wrong custom data, got:", custom)
+	}
+	if custom["message"] != "payment failed" {
+		t.Error("expected the message in custom data, got:", custom["message"])
+	}
+
+	body := data["body"].(map[string]interface{})
+	frames := body["trace_chain"].([]interface{})[0].(map[string]interface{})["frames"].([]interface{})
+	method := frames[0].(map[string]interface{})["method"].(string)
+	if !strings.Contains(method, "logPaymentFailure") {
+		t.Error("expected the stack to start at the logging call, got:", method)
+	}
+	telemetry := body["telemetry"].([]interface{})
+	if len(telemetry) != 1 {
+		t.Fatal("expected one telemetry event, got:", telemetry)
+	}
+	event := telemetry[0].(map[string]interface{})
+	eventBody := event["body"].(map[string]interface{})
+	if event["type"] != "log" || event["level"] != rollbar.INFO || eventBody["message"] != "charging card" || eventBody["req.amount"] != float64(1299) {
+		t.Error("wrong telemetry event, got:", event)
+	}
+}
+
+func TestHandlerMessage(t *testing.T) {
+	client, rec := rollbartest.NewRecorderClient(t)
+	logger := slog.New(NewHandler(client, &HandlerOptions{ReportLevel: slog.LevelError}))
+
+	logger.Warn("slow query", "duration_ms", 1200)
+	if rec.Len() != 0 {
+		t.Fatal("expected warnings to be recorded as telemetry, got:", rec.Items())
+	}
+	logger.Log(context.Background(), slog.LevelError+4, "disk full", "free", 0)
+	if rec.Len() != 1 {
+		t.Fatal("expected one item, got:", rec.Len())
+	}
+	data := rec.Items()[0]
+	if data["level"] != rollbar.CRIT {
+		t.Error("wrong level, got:", data["level"])
+	}
+	message := data["body"].(map[string]interface{})["message"].(map[string]interface{})
+	if message["body"] != "disk full" {
+		t.Error("wrong message, got:", message)
+	}
+}
+
+func TestTeeHandler(t *testing.T) {
+	client, rec := rollbartest.NewRecorderClient(t)
+	var out strings.Builder
+	next := slog.NewTextHandler(&out, &slog.HandlerOptions{Level: slog.LevelWarn})
+	logger := slog.New(NewTeeHandler(next, client, nil)).WithGroup("req")
+
+	logger.Debug("cache lookup")
+	logger.Info("cache miss", "key", "user:42")
+	logger.Error("query failed", "table", "users")
+
+	if rec.Len() != 0 {
+		t.Fatal("expected no item to be reported, got:", rec.Items())
+	}
+	if !strings.Contains(out.String(), "query failed") || strings.Contains(out.String(), "cache miss") {
+		t.Error("expected the records to be passed to the next handler at its level, got:", out.String())
+	}
+	events := client.Telemetry.GetQueueItems()
+	if len(events) != 2 {
+		t.Fatal("expected 2 telemetry events, got:", events)
+	}
+	body := events[0].(map[string]interface{})["body"].(map[string]interface{})
+	if body["message"] != "cache miss" || body["req.key"] != "user:42" {
+		t.Error("wrong telemetry event, got:", body)
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/rollbar/rollbar-go/contrib/sync
+
+go 1.18
+
+require (
+	github.com/rollbar/rollbar-go v1.3.0
+	golang.org/x/sync v0.7.0
+)
+
+replace github.com/rollbar/rollbar-go => ../..
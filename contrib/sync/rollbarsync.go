@@ -0,0 +1,113 @@
+// Package rollbarsync provides a Group of goroutines working on subtasks of a common task, like
+// golang.org/x/sync/errgroup whose Group it wraps, which reports the panics and errors of the
+// subtasks to Rollbar, so that background jobs and worker pools are covered uniformly:
+//
+//	g, ctx := rollbarsync.WithContext(ctx, client)
+//	for _, id := range ids {
+//		id := id
+//		g.GoWithExtras(func() error {
+//			return process(ctx, id)
+//		}, map[string]interface{}{"job_id": id})
+//	}
+//	err := g.Wait()
+//
+// A panic of a subtask is recovered, reported as a critical item and returned as its error. The
+// other errors returned by subtasks are reported as errors, except for context.Canceled and
+// context.DeadlineExceeded, which mostly result from the cancellation of the group.
+package rollbarsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rollbar/rollbar-go"
+	"golang.org/x/sync/errgroup"
+)
+
+// Group is a collection of goroutines working on subtasks of a common task, reporting their panics
+// and errors to Rollbar. The zero Group is not valid, use New or WithContext.
+type Group struct {
+	client *rollbar.Client
+	ctx    context.Context
+	group  *errgroup.Group
+}
+
+// New returns a Group reporting with client, or with the managed Client instance of the rollbar
+// package if client is nil. Like the zero errgroup.Group, it does not cancel on error.
+func New(client *rollbar.Client) *Group {
+	return &Group{client: client, ctx: context.Background(), group: &errgroup.Group{}}
+}
+
+// WithContext returns a Group reporting with client, or with the managed Client instance of the
+// rollbar package if client is nil, and a context derived from ctx which is canceled the first
+// time a subtask returns an error or panics, or when Wait returns, see errgroup.WithContext. Items
+// are reported within ctx, so that the person, custom data and other values attached to it are
+// reported with them.
+func WithContext(ctx context.Context, client *rollbar.Client) (*Group, context.Context) {
+	group, groupCtx := errgroup.WithContext(ctx)
+	return &Group{client: client, ctx: ctx, group: group}, groupCtx
+}
+
+// Go calls f in a new goroutine, reporting its panic or error.
+func (g *Group) Go(f func() error) {
+	g.group.Go(g.task(f, nil))
+}
+
+// GoWithExtras calls f in a new goroutine, reporting its panic or error with the extra custom data
+// of the subtask, such as the ID of the job it processes.
+func (g *Group) GoWithExtras(f func() error, extras map[string]interface{}) {
+	g.group.Go(g.task(f, extras))
+}
+
+// TryGo calls f in a new goroutine only if the number of active goroutines is below the limit set
+// with SetLimit, reporting its panic or error. It returns whether f was started.
+func (g *Group) TryGo(f func() error, extras map[string]interface{}) bool {
+	return g.group.TryGo(g.task(f, extras))
+}
+
+// SetLimit limits the number of active goroutines of the group to at most n, see
+// errgroup.Group.SetLimit. A negative value indicates no limit.
+func (g *Group) SetLimit(n int) {
+	g.group.SetLimit(n)
+}
+
+// Wait blocks until all the subtasks have returned, and returns the first error returned by one of
+// them, or the error of the first panic.
+func (g *Group) Wait() error {
+	return g.group.Wait()
+}
+
+// task returns f recovering and reporting its panic, and reporting its error.
+func (g *Group) task(f func() error, extras map[string]interface{}) func() error {
+	return func() (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = panicError(p)
+				g.report(rollbar.CRIT, err, extras)
+			}
+		}()
+		err = f()
+		if err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+			g.report(rollbar.ERR, err, extras)
+		}
+		return err
+	}
+}
+
+// report reports err at the given level with the extra custom data of a subtask.
+func (g *Group) report(level string, err error, extras map[string]interface{}) {
+	if g.client != nil {
+		g.client.ErrorWithStackSkipWithExtrasAndContext(g.ctx, level, err, 2, extras)
+		return
+	}
+	rollbar.ErrorWithStackSkipWithExtrasAndContext(g.ctx, level, err, 3, extras)
+}
+
+// panicError returns the error of a panic value, as reported by rollbar.LogPanic.
+func panicError(p interface{}) error {
+	if err, ok := p.(error); ok {
+		return err
+	}
+	return errors.New(fmt.Sprint(p))
+}
@@ -0,0 +1,80 @@
+package rollbarsync
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rollbar/rollbar-go/rollbartest"
+)
+
+func trace(item map[string]interface{}) map[string]interface{} {
+	return item["body"].(map[string]interface{})["trace_chain"].([]interface{})[0].(map[string]interface{})
+}
+
+func traceMessage(item map[string]interface{}) interface{} {
+	return trace(item)["exception"].(map[string]interface{})["message"]
+}
+
+func TestGroupReportsPanics(t *testing.T) {
+	client, rec := rollbartest.NewRecorderClient(t)
+	g, ctx := WithContext(context.Background(), client)
+	g.GoWithExtras(func() error {
+		panic("job failed")
+	}, map[string]interface{}{"job_id": 42})
+
+	err := g.Wait()
+	if err == nil || err.Error() != "job failed" {
+		t.Fatal("expected the panic to be returned, got:", err)
+	}
+	if ctx.Err() == nil {
+		t.Error("expected the context of the group to be canceled")
+	}
+	if rec.Len() != 1 {
+		t.Fatal("expected 1 item, got:", rec.Len())
+	}
+	item := rec.Items()[0]
+	if item["level"] != "critical" {
+		t.Error("expected a critical item, got:", item["level"])
+	}
+	if msg := traceMessage(item); msg != "job failed" {
+		t.Error("unexpected message:", msg)
+	}
+	frames := trace(item)["frames"].([]interface{})
+	if method := frames[0].(map[string]interface{})["method"].(string); !strings.Contains(method, "TestGroupReportsPanics") {
+		t.Error("expected the stack to start at the panic, got:", method)
+	}
+	if custom := item["custom"].(map[string]interface{}); custom["job_id"] != float64(42) {
+		t.Error("expected the extras of the subtask, got:", custom)
+	}
+}
+
+func TestGroupReportsErrors(t *testing.T) {
+	client, rec := rollbartest.NewRecorderClient(t)
+	g := New(client)
+	g.SetLimit(1)
+	g.Go(func() error {
+		return errors.New("timeout talking to the bank")
+	})
+	g.Go(func() error {
+		return context.Canceled
+	})
+	g.Go(func() error {
+		return nil
+	})
+
+	if err := g.Wait(); err == nil || err.Error() != "timeout talking to the bank" {
+		t.Fatal("expected the first error to be returned, got:", err)
+	}
+	if rec.Len() != 1 {
+		t.Fatal("expected only the error to be reported, got:", rec.Len())
+	}
+	item := rec.Items()[0]
+	if item["level"] != "error" {
+		t.Error("expected an error item, got:", item["level"])
+	}
+	if msg := traceMessage(item); msg != "timeout talking to the bank" {
+		t.Error("unexpected message:", msg)
+	}
+}
@@ -0,0 +1,31 @@
+package rollbar
+
+import (
+	"net/http"
+	"strings"
+)
+
+// addCookies adds the cookies of the request headers to details as cookies, scrubbing the values
+// of those matching the scrub pattern, and replaces the raw Cookie header with its scrubbed form.
+func addCookies(configuration configuration, details map[string]interface{}, headers map[string][]string) {
+	cookies := (&http.Request{Header: http.Header(headers)}).Cookies()
+	if len(cookies) == 0 {
+		return
+	}
+	values := make(map[string]string, len(cookies))
+	pairs := make([]string, 0, len(cookies))
+	for _, cookie := range cookies {
+		value := cookie.Value
+		if configuration.scrubCookies != nil && configuration.scrubCookies.MatchString(cookie.Name) {
+			value = FILTERED
+		}
+		values[cookie.Name] = value
+		pairs = append(pairs, cookie.Name+"="+value)
+	}
+	details["cookies"] = values
+	if filtered, ok := details["headers"].(map[string]interface{}); ok {
+		if _, ok := filtered["Cookie"]; ok && filtered["Cookie"] != FILTERED {
+			filtered["Cookie"] = strings.Join(pairs, "; ")
+		}
+	}
+}
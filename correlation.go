@@ -0,0 +1,97 @@
+package rollbar
+
+import (
+	"net/textproto"
+	"regexp"
+	"strings"
+)
+
+// DefaultCorrelationHeaders are common headers carrying the ID of a request or of its trace, in
+// order of priority, to be given to SetCorrelationHeaders.
+var DefaultCorrelationHeaders = []string{"X-Request-Id", "X-Correlation-Id", "traceparent"}
+
+// hexID matches 32 hexadecimal digits, optionally grouped like a UUID.
+var hexID = regexp.MustCompile(`^[0-9a-fA-F]{8}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{12}$`)
+
+// SetCorrelationHeaders sets the headers from which the correlation ID of requests is taken, in
+// order of priority, e.g. SetCorrelationHeaders(rollbar.DefaultCorrelationHeaders...). The value of
+// the first header present in the request of an item is reported as custom.correlation_id, so that
+// items can be joined with the logs of the request. For a W3C traceparent header, the trace ID is
+// reported. When the ID is made of 32 hexadecimal digits, as UUIDs and trace IDs are, it is also
+// reported formatted as a UUID in custom.correlation_uuid. By default no header is read.
+func (c *Client) SetCorrelationHeaders(headers ...string) {
+	c.ApplyOptions(func(conf *configuration) { conf.correlation = headers })
+}
+
+// CorrelationHeaders are the currently set headers holding the correlation ID of requests.
+func (c *Client) CorrelationHeaders() []string {
+	return c.configuration().correlation
+}
+
+// addCorrelationID adds the correlation ID found in the headers of the request of the item, if
+// any, to its custom data.
+func addCorrelationID(configuration configuration, data map[string]interface{}) {
+	if len(configuration.correlation) == 0 {
+		return
+	}
+	request, _ := data["request"].(map[string]interface{})
+	headers, _ := request["headers"].(map[string]interface{})
+	if len(headers) == 0 {
+		return
+	}
+	for _, name := range configuration.correlation {
+		id := correlationID(name, headerValue(headers, name))
+		if id == "" || id == FILTERED {
+			continue
+		}
+		custom, _ := data["custom"].(map[string]interface{})
+		if custom == nil {
+			custom = map[string]interface{}{}
+		}
+		custom["correlation_id"] = id
+		if hexID.MatchString(id) {
+			hex := strings.ToLower(strings.Replace(id, "-", "", -1))
+			custom["correlation_uuid"] = hex[0:8] + "-" + hex[8:12] + "-" + hex[12:16] + "-" + hex[16:20] + "-" + hex[20:]
+		}
+		data["custom"] = custom
+		return
+	}
+}
+
+// headerValue returns the first value of the header with the given name in the flattened headers
+// of a request, whatever the case of their names.
+func headerValue(headers map[string]interface{}, name string) string {
+	value, ok := headers[textproto.CanonicalMIMEHeaderKey(name)]
+	if !ok {
+		for k, v := range headers {
+			if strings.EqualFold(k, name) {
+				value = v
+				break
+			}
+		}
+	}
+	switch value := value.(type) {
+	case string:
+		return value
+	case []string:
+		if len(value) > 0 {
+			return value[0]
+		}
+	}
+	return ""
+}
+
+// correlationID returns the correlation ID held by the value of the given header: the trace ID of
+// a traceparent header, the trimmed value otherwise.
+func correlationID(name, value string) string {
+	value = strings.TrimSpace(value)
+	if strings.EqualFold(name, "traceparent") {
+		// version-traceid-parentid-flags
+		parts := strings.Split(value, "-")
+		if len(parts) < 4 || len(parts[1]) != 32 {
+			return ""
+		}
+		return parts[1]
+	}
+	return value
+}
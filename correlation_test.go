@@ -0,0 +1,40 @@
+package rollbar
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCorrelationHeaders(t *testing.T) {
+	client := testClient()
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("X-Request-Id", "req-42")
+	r.Header.Set("Traceparent", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01")
+
+	client.RequestError(ERR, r, errors.New("boom"))
+	data := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	if _, ok := data["custom"]; ok {
+		t.Error("the correlation ID should not be captured by default, got:", data["custom"])
+	}
+
+	client.SetCorrelationHeaders("traceparent", "X-Request-Id")
+	client.RequestError(ERR, r, errors.New("boom"))
+	custom := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})["custom"].(map[string]interface{})
+	if custom["correlation_id"] != "4BF92F3577B34DA6A3CE929D0E0E4736" {
+		t.Error("expected the trace ID, got:", custom["correlation_id"])
+	}
+	if custom["correlation_uuid"] != "4bf92f35-77b3-4da6-a3ce-929d0e0e4736" {
+		t.Error("expected the trace ID as a UUID, got:", custom["correlation_uuid"])
+	}
+
+	r.Header.Del("Traceparent")
+	client.RequestError(ERR, r, errors.New("boom"))
+	custom = client.Transport.(*TestTransport).Body["data"].(map[string]interface{})["custom"].(map[string]interface{})
+	if custom["correlation_id"] != "req-42" {
+		t.Error("expected the request ID, got:", custom["correlation_id"])
+	}
+	if _, ok := custom["correlation_uuid"]; ok {
+		t.Error("unexpected UUID, got:", custom["correlation_uuid"])
+	}
+}
@@ -0,0 +1,107 @@
+package rollbar
+
+import (
+	"sync"
+	"time"
+)
+
+// maxOccurrenceEntries bounds the number of distinct items tracked by an occurrenceCache. Expired
+// entries are pruned once it is reached.
+const maxOccurrenceEntries = 1000
+
+// An occurrenceCache counts the occurrences of each distinct item reported within a time window.
+// Items are identified by their fingerprint when one is set (see SetFingerprint), and by their
+// title otherwise.
+type occurrenceCache struct {
+	window  time.Duration
+	entries map[string]*occurrences
+
+	lock sync.Mutex
+}
+
+type occurrences struct {
+	first time.Time
+	count int
+}
+
+func newOccurrenceCache(window time.Duration) *occurrenceCache {
+	return &occurrenceCache{
+		window:  window,
+		entries: map[string]*occurrences{},
+	}
+}
+
+// record counts an occurrence of the item described by data at now and returns the number of
+// occurrences of that item within the current window, including this one.
+func (oc *occurrenceCache) record(data map[string]interface{}, now time.Time) int {
+	key := occurrenceKey(data)
+	oc.lock.Lock()
+	defer oc.lock.Unlock()
+	entry, ok := oc.entries[key]
+	if !ok || now.Sub(entry.first) > oc.window {
+		if !ok && len(oc.entries) >= maxOccurrenceEntries {
+			oc.prune(now)
+		}
+		entry = &occurrences{first: now}
+		oc.entries[key] = entry
+	}
+	entry.count++
+	return entry.count
+}
+
+// prune removes the entries whose window has expired.
+func (oc *occurrenceCache) prune(now time.Time) {
+	for key, entry := range oc.entries {
+		if now.Sub(entry.first) > oc.window {
+			delete(oc.entries, key)
+		}
+	}
+}
+
+func occurrenceKey(data map[string]interface{}) string {
+	if fingerprint, ok := data["fingerprint"].(string); ok && fingerprint != "" {
+		return fingerprint
+	}
+	title, _ := data["title"].(string)
+	return title
+}
+
+// escalationLevels is the order in which levels are promoted.
+var escalationLevels = []string{DEBUG, INFO, WARN, ERR, CRIT}
+
+// escalate promotes the level of the item described by data by one step for every threshold
+// occurrences of the item within the window, up to critical. A promoted item records the number
+// of occurrences and its original level in custom.escalation.
+func (oc *occurrenceCache) escalate(data map[string]interface{}, threshold int, now time.Time) {
+	count := oc.record(data, now)
+	steps := count / threshold
+	level, _ := data["level"].(string)
+	if steps == 0 || level == CRIT {
+		return
+	}
+	promoted := level
+	for i, l := range escalationLevels {
+		if l == level {
+			if i+steps >= len(escalationLevels) {
+				promoted = CRIT
+			} else {
+				promoted = escalationLevels[i+steps]
+			}
+			break
+		}
+	}
+	if promoted == level {
+		return
+	}
+	data["level"] = promoted
+	custom, _ := data["custom"].(map[string]interface{})
+	if custom == nil {
+		custom = map[string]interface{}{}
+	}
+	custom["escalation"] = map[string]interface{}{
+		"count":          count,
+		"original_level": level,
+		"window_seconds": oc.window.Seconds(),
+	}
+	data["custom"] = custom
+}
@@ -0,0 +1,67 @@
+package rollbar
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOccurrenceCacheEscalate(t *testing.T) {
+	oc := newOccurrenceCache(time.Minute)
+	now := time.Now()
+	report := func(at time.Time) map[string]interface{} {
+		data := map[string]interface{}{"title": "disk full", "level": WARN}
+		oc.escalate(data, 2, at)
+		return data
+	}
+
+	levels := []string{WARN, ERR, ERR, CRIT, CRIT, CRIT}
+	for i, expected := range levels {
+		data := report(now.Add(time.Duration(i) * time.Second))
+		if data["level"] != expected {
+			t.Errorf("occurrence %d: expected %s, got: %s", i+1, expected, data["level"])
+		}
+		if i == 0 {
+			if data["custom"] != nil {
+				t.Error("expected no escalation details, got:", data["custom"])
+			}
+			continue
+		}
+		escalation := data["custom"].(map[string]interface{})["escalation"].(map[string]interface{})
+		if escalation["count"] != i+1 || escalation["original_level"] != WARN {
+			t.Error("unexpected escalation details:", escalation)
+		}
+	}
+
+	if data := report(now.Add(2 * time.Minute)); data["level"] != WARN {
+		t.Error("expected the count to restart after the window, got:", data["level"])
+	}
+	other := map[string]interface{}{"title": "other", "level": WARN}
+	oc.escalate(other, 2, now.Add(2*time.Minute))
+	if other["level"] != WARN {
+		t.Error("expected items to be counted separately, got:", other["level"])
+	}
+}
+
+func TestSetEscalationPolicy(t *testing.T) {
+	client := testClient()
+	client.SetEscalationPolicy(3, time.Minute)
+	if threshold, window := client.EscalationPolicy(); threshold != 3 || window != time.Minute {
+		t.Error("unexpected policy:", threshold, window)
+	}
+
+	for i := 0; i < 3; i++ {
+		client.ErrorWithLevel(ERR, errors.New("connection refused"))
+	}
+	data := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	if data["level"] != CRIT {
+		t.Error("expected the third occurrence to be critical, got:", data["level"])
+	}
+
+	client.SetEscalationPolicy(0, 0)
+	client.ErrorWithLevel(ERR, errors.New("connection refused"))
+	data = client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	if data["level"] != ERR {
+		t.Error("expected escalation to be disabled, got:", data["level"])
+	}
+}
@@ -0,0 +1,123 @@
+package rollbar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os/user"
+	"strings"
+	"time"
+)
+
+// DefaultDeployEndpoint is the endpoint of the Rollbar API to which deploys are reported.
+const DefaultDeployEndpoint = "https://api.rollbar.com/api/1/deploy"
+
+// Deploy describes a deploy of an application, as reported to the /deploy endpoint of the Rollbar
+// API. Environment and Revision are required.
+type Deploy struct {
+	Environment string `json:"environment"`
+	// Revision is the revision being deployed, such as a git SHA.
+	Revision string `json:"revision"`
+	// LocalUsername is the name of the user who deployed, on the deploying machine.
+	LocalUsername string `json:"local_username,omitempty"`
+	// RollbarUsername is the Rollbar username of the user who deployed.
+	RollbarUsername string `json:"rollbar_username,omitempty"`
+	Comment         string `json:"comment,omitempty"`
+	// Status is one of "started", "succeeded", "failed" and "timed_out". The API defaults to
+	// "succeeded".
+	Status string `json:"status,omitempty"`
+}
+
+// DeployClient reports deploys to the Rollbar API. Its token needs the "post_server_item" scope.
+type DeployClient struct {
+	Token    string
+	Endpoint string
+	// HTTPClient is the client used for the requests, http.DefaultClient if nil.
+	HTTPClient *http.Client
+}
+
+// NewDeployClient returns a DeployClient reporting deploys with the given access token to
+// DefaultDeployEndpoint.
+func NewDeployClient(token string) *DeployClient {
+	return &DeployClient{Token: token, Endpoint: DefaultDeployEndpoint}
+}
+
+// Report reports the deploy and returns its ID. The errors returned for responses other than
+// 200 OK are the same as those of the transports, such as ErrUnauthorized.
+func (d *DeployClient) Report(ctx context.Context, deploy Deploy) (int64, error) {
+	body, err := json.Marshal(deploy)
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequest("POST", d.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Rollbar-Access-Token", d.Token)
+	client := d.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(ioutil.Discard, resp.Body)
+		return 0, httpError(resp, time.Now())
+	}
+	var result struct {
+		Data struct {
+			DeployID int64 `json:"deploy_id"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, err
+	}
+	return result.Data.DeployID, nil
+}
+
+// httpClientProvider is implemented by the transports with an HTTP client, see SetHTTPClient.
+type httpClientProvider interface {
+	getHTTPClient() *http.Client
+}
+
+func (t *interceptedTransport) getHTTPClient() *http.Client {
+	if inner, ok := t.Transport.(httpClientProvider); ok {
+		return inner.getHTTPClient()
+	}
+	return http.DefaultClient
+}
+
+// ReportDeploy reports a deploy, e.g. at startup, with the token and HTTP client of the client and
+// returns its ID. The environment, the revision and the local username default to the environment
+// and code version of the client and to the name of the current user of the process. The deploy
+// endpoint is derived from the endpoint of the client when it ends with "/item/", as it does by
+// default, and is DefaultDeployEndpoint otherwise.
+func (c *Client) ReportDeploy(ctx context.Context, deploy Deploy) (int64, error) {
+	conf := c.configuration()
+	if deploy.Environment == "" {
+		deploy.Environment = conf.environment
+	}
+	if deploy.Revision == "" {
+		deploy.Revision = conf.codeVersion
+	}
+	if deploy.LocalUsername == "" {
+		if u, err := user.Current(); err == nil {
+			deploy.LocalUsername = u.Username
+		}
+	}
+	d := NewDeployClient(conf.token)
+	if strings.HasSuffix(conf.endpoint, "/item/") {
+		d.Endpoint = strings.TrimSuffix(conf.endpoint, "item/") + "deploy"
+	}
+	if t, ok := c.Transport.(httpClientProvider); ok {
+		d.HTTPClient = t.getHTTPClient()
+	}
+	return d.Report(ctx, deploy)
+}
@@ -0,0 +1,50 @@
+package rollbar
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReportDeploy(t *testing.T) {
+	var path, token string
+	var deploy Deploy
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, token = r.URL.Path, r.Header.Get("X-Rollbar-Access-Token")
+		json.NewDecoder(r.Body).Decode(&deploy)
+		w.Write([]byte(`{"err": 0, "data": {"deploy_id": 42}}`))
+	}))
+	defer ts.Close()
+
+	client := NewSync("token", "production", "abc123", "", "")
+	client.SetEndpoint(ts.URL + "/api/1/item/")
+	id, err := client.ReportDeploy(context.Background(), Deploy{LocalUsername: "ci", Comment: "release"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 42 {
+		t.Error("wrong deploy ID, got:", id)
+	}
+	if path != "/api/1/deploy" || token != "token" {
+		t.Error("wrong request, got:", path, token)
+	}
+	expected := Deploy{Environment: "production", Revision: "abc123", LocalUsername: "ci", Comment: "release"}
+	if deploy != expected {
+		t.Error("wrong deploy, got:", deploy)
+	}
+}
+
+func TestDeployClientError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer ts.Close()
+
+	d := NewDeployClient("bad")
+	d.Endpoint = ts.URL
+	if _, err := d.Report(context.Background(), Deploy{Environment: "production", Revision: "abc123"}); err != (ErrUnauthorized{StatusCode: http.StatusUnauthorized}) {
+		t.Error("expected ErrUnauthorized, got:", err)
+	}
+}
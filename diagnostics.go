@@ -0,0 +1,64 @@
+package rollbar
+
+import (
+	"runtime/debug"
+	"sync"
+)
+
+var (
+	buildModulesOnce sync.Once
+	buildModules     map[string]string
+)
+
+// moduleVersions returns the versions of the modules the program was built with, by module path,
+// including the main module. It is empty when the program was not built with module support.
+func moduleVersions() map[string]string {
+	buildModulesOnce.Do(func() {
+		buildModules = map[string]string{}
+		info, ok := debug.ReadBuildInfo()
+		if !ok {
+			return
+		}
+		buildModules[info.Main.Path] = info.Main.Version
+		for _, module := range info.Deps {
+			version := module.Version
+			if module.Replace != nil {
+				version += " => " + module.Replace.Path
+				if module.Replace.Version != "" {
+					version += " " + module.Replace.Version
+				}
+			}
+			buildModules[module.Path] = version
+		}
+	})
+	return buildModules
+}
+
+// SetDiagnosticModules sets the paths of the modules whose versions are reported in
+// notifier.diagnostic.modules, e.g. "github.com/rollbar/rollbar-go" and the modules of the
+// frameworks in use, which helps diagnosing issues depending on the dependencies of the program.
+// Modules the program was not built with are reported with an empty version. The default value is
+// nil, which reports no module.
+func (c *Client) SetDiagnosticModules(paths ...string) {
+	c.ApplyOptions(func(conf *configuration) { conf.modules = paths })
+}
+
+// DiagnosticModules returns the paths of the modules whose versions are reported, see
+// SetDiagnosticModules.
+func (c *Client) DiagnosticModules() []string {
+	return c.configuration().modules
+}
+
+// moduleDiagnostic returns the versions of the modules with the given paths, or nil if there are
+// none.
+func moduleDiagnostic(paths []string) map[string]interface{} {
+	if len(paths) == 0 {
+		return nil
+	}
+	versions := moduleVersions()
+	modules := make(map[string]interface{}, len(paths))
+	for _, path := range paths {
+		modules[path] = versions[path]
+	}
+	return modules
+}
@@ -0,0 +1,31 @@
+package rollbar
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestDiagnosticModules(t *testing.T) {
+	client := testClient()
+	client.Message(INFO, "diagnostic")
+	data := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	diagnostic := data["notifier"].(map[string]interface{})["diagnostic"].(map[string]interface{})
+	if diagnostic["goos"] != runtime.GOOS || diagnostic["goarch"] != runtime.GOARCH {
+		t.Error("wrong platform, got:", diagnostic["goos"], diagnostic["goarch"])
+	}
+	if _, ok := diagnostic["modules"]; ok {
+		t.Error("expected no modules by default")
+	}
+
+	client.SetDiagnosticModules("github.com/stretchr/testify", "example.com/unknown")
+	client.Message(INFO, "diagnostic")
+	data = client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	diagnostic = data["notifier"].(map[string]interface{})["diagnostic"].(map[string]interface{})
+	modules := diagnostic["modules"].(map[string]interface{})
+	if version, ok := modules["example.com/unknown"]; !ok || version != "" {
+		t.Error("expected an empty version for an unknown module, got:", modules)
+	}
+	if _, ok := modules["github.com/stretchr/testify"]; !ok {
+		t.Error("expected the version of the requested module, got:", modules)
+	}
+}
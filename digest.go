@@ -0,0 +1,100 @@
+package rollbar
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// DefaultCustomDigestThreshold is the default length in bytes above which strings in custom data
+// are replaced by a digest. See SetCustomDigestThreshold.
+const DefaultCustomDigestThreshold = 64 * 1024
+
+// digestSnippetLength is the length in bytes of the head and tail snippets of a digest.
+const digestSnippetLength = 256
+
+// digest summarizes a large string or byte slice: its length, SHA-256 hash and, for strings, its
+// first and last bytes.
+func digest(b []byte, snippets bool) map[string]interface{} {
+	sum := sha256.Sum256(b)
+	d := map[string]interface{}{
+		"digested": true,
+		"length":   len(b),
+		"sha256":   hex.EncodeToString(sum[:]),
+	}
+	if snippets {
+		n := digestSnippetLength
+		if n > len(b)/4 {
+			n = len(b) / 4
+		}
+		// Runes cut at the edges of the snippets are dropped.
+		d["head"] = string(bytes.ToValidUTF8(b[:n], nil))
+		d["tail"] = string(bytes.ToValidUTF8(b[len(b)-n:], nil))
+	}
+	return d
+}
+
+// digestLargeValues replaces the strings and byte slices longer than threshold found in v, within
+// maps and slices, by a digest. Maps and slices holding such values are copied rather than
+// modified, since they may belong to the application. The second return value is false when v is
+// returned unchanged.
+func digestLargeValues(v interface{}, threshold int) (interface{}, bool) {
+	switch val := v.(type) {
+	case string:
+		if len(val) > threshold {
+			return digest([]byte(val), true), true
+		}
+	case []byte:
+		if len(val) > threshold {
+			return digest(val, false), true
+		}
+	case map[string]interface{}:
+		var digested map[string]interface{}
+		for k, item := range val {
+			replaced, ok := digestLargeValues(item, threshold)
+			if !ok {
+				continue
+			}
+			if digested == nil {
+				digested = make(map[string]interface{}, len(val))
+				for k, item := range val {
+					digested[k] = item
+				}
+			}
+			digested[k] = replaced
+		}
+		if digested != nil {
+			return digested, true
+		}
+	case []interface{}:
+		var digested []interface{}
+		for i, item := range val {
+			replaced, ok := digestLargeValues(item, threshold)
+			if !ok {
+				continue
+			}
+			if digested == nil {
+				digested = append([]interface{}(nil), val...)
+			}
+			digested[i] = replaced
+		}
+		if digested != nil {
+			return digested, true
+		}
+	}
+	return v, false
+}
+
+// digestLargeCustomValues replaces the large strings of data.custom by digests.
+func digestLargeCustomValues(data map[string]interface{}, threshold int) {
+	if threshold <= 0 {
+		return
+	}
+	custom, ok := data["custom"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	if digested, ok := digestLargeValues(custom, threshold); ok {
+		data["custom"] = digested
+	}
+}
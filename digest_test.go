@@ -0,0 +1,50 @@
+package rollbar
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestCustomDigest(t *testing.T) {
+	client := testClient()
+	client.SetCustomDigestThreshold(1024)
+	blob := strings.Repeat("é", 300) + strings.Repeat("x", 2000) + "END"
+	nested := map[string]interface{}{"dump": blob, "small": "ok"}
+	client.MessageWithExtras(ERR, "import failed", map[string]interface{}{
+		"request": nested,
+		"chunks":  []interface{}{"short", []byte(blob)},
+		"size":    42,
+	})
+
+	data := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	custom := data["custom"].(map[string]interface{})
+	request := custom["request"].(map[string]interface{})
+	digest := request["dump"].(map[string]interface{})
+	sum := sha256.Sum256([]byte(blob))
+	if digest["length"] != len(blob) || digest["sha256"] != hex.EncodeToString(sum[:]) {
+		t.Error("unexpected digest:", digest)
+	}
+	head, tail := digest["head"].(string), digest["tail"].(string)
+	if !strings.HasPrefix(head, "éé") || !strings.HasSuffix(tail, "xEND") || len(head) > digestSnippetLength {
+		t.Error("unexpected snippets:", head, tail)
+	}
+	if request["small"] != "ok" || custom["size"] != 42 {
+		t.Error("expected small values to be kept, got:", custom)
+	}
+	chunks := custom["chunks"].([]interface{})
+	if chunks[0] != "short" || chunks[1].(map[string]interface{})["head"] != nil {
+		t.Error("expected byte slices to be digested without snippets, got:", chunks)
+	}
+	if nested["dump"] != blob {
+		t.Error("expected the extras not to be modified")
+	}
+
+	client.SetCustomDigestThreshold(0)
+	client.MessageWithExtras(ERR, "import failed", map[string]interface{}{"dump": blob})
+	data = client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	if data["custom"].(map[string]interface{})["dump"] != blob {
+		t.Error("expected digests to be disabled")
+	}
+}
@@ -32,6 +32,18 @@ If you wish for more fine grained control over the client or you wish to have mu
 
 We provide two implementations of the `Transport` interface, `AsyncTransport` and `SyncTransport`. These manage the communication with the network layer. The Async version uses a buffered channel to communicate with the Rollbar API in a separate go routine. The Sync version is fully synchronous. It is possible to create your own `Transport` and configure a Client to use your preferred implementation.
 
+Telemetry
+
+Telemetry events (breadcrumbs) are sent along with every item. They can be captured manually with `CaptureTelemetryEvent` or the typed helpers `RecordLogTelemetry`, `RecordNetworkTelemetry` and `RecordManualTelemetry`, and the managed Client can capture network and log events automatically when it is configured with `SetTelemetry`:
+
+  rollbar.SetTelemetry(
+    rollbar.EnableNetworkTelemetry(http.DefaultClient),
+    rollbar.EnableLoggerTelemetry(),
+    rollbar.SetCustomQueueSize(100),
+  )
+
+The current telemetry settings are available from getters such as `NetworkTelemetryEnabled`, `LoggerTelemetryEnabled` and `CustomQueueSize`.
+
 Handling Panics
 
 Go does not provide a mechanism for handling all panics automatically, therefore we provide two functions `Wrap` and `WrapAndWait` to make working with panics easier. They both take a function with arguments and then report to Rollbar if that function panics. They use the recover mechanism to capture the panic, and therefore if you wish your process to have the normal behaviour on panic (i.e. to crash), you will need to re-panic the result of calling `Wrap`. For example,
@@ -0,0 +1,82 @@
+package rollbar
+
+import "time"
+
+// DropPolicy tells the asynchronous transport what to do with an item sent while its queue is
+// full, see SetDropPolicy.
+type DropPolicy int
+
+const (
+	// DropNewestPolicy drops the item sent, keeping the items already queued.
+	DropNewestPolicy DropPolicy = iota
+	// DropOldestPolicy drops the oldest queued item to make room for the item sent.
+	DropOldestPolicy
+	// BlockPolicy blocks the caller until there is room in the queue, or the timeout expires.
+	BlockPolicy
+)
+
+// dropPolicySetter is implemented by the transports whose queue can be full.
+type dropPolicySetter interface {
+	SetDropPolicy(policy DropPolicy, timeout time.Duration)
+}
+
+// SetDropPolicy sets what Send does when the queue is full: with DropNewestPolicy, the default,
+// the item sent is dropped and ErrBufferFull is returned; with DropOldestPolicy, the oldest queued
+// item is dropped instead; with BlockPolicy, Send waits for room in the queue, for at most timeout
+// if it is positive, and drops the item if the timeout expires. Items below the priority set with
+// SetQueuePriority are dropped whatever the policy once the queue reaches the reserved room.
+func (t *AsyncTransport) SetDropPolicy(policy DropPolicy, timeout time.Duration) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.dropPolicy = policy
+	t.dropTimeout = timeout
+}
+
+func (t *AsyncTransport) getDropPolicy() (DropPolicy, time.Duration) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	return t.dropPolicy, t.dropTimeout
+}
+
+// evictOldest drops the oldest queued item, if any, to make room for another one.
+func (t *AsyncTransport) evictOldest() {
+	select {
+	case p, ok := <-t.bodyChannel:
+		if !ok {
+			return
+		}
+		rollbarError(t.Logger, "queue full, dropping the oldest item")
+		t.dropped(p.body, DropReasonQueueFull)
+		t.waitGroup.Done()
+	default:
+	}
+}
+
+func (t *interceptedTransport) SetDropPolicy(policy DropPolicy, timeout time.Duration) {
+	if inner, ok := t.Transport.(dropPolicySetter); ok {
+		inner.SetDropPolicy(policy, timeout)
+	}
+}
+
+// SetDropPolicy sets what each destination supporting it does with the items sent while its queue
+// is full.
+func (t *FanOutTransport) SetDropPolicy(policy DropPolicy, timeout time.Duration) {
+	for _, d := range t.destinations {
+		if s, ok := d.(dropPolicySetter); ok {
+			s.SetDropPolicy(policy, timeout)
+		}
+	}
+}
+
+// SetDropPolicy sets what the transport does with the items reported while its queue is full, so
+// that services which must not lose errors can block, for at most timeout if it is positive, or
+// drop the oldest queued item instead, see AsyncTransport.SetDropPolicy. Transports without a
+// queue are left unchanged and an error is logged.
+func (c *Client) SetDropPolicy(policy DropPolicy, timeout time.Duration) {
+	s, ok := c.Transport.(dropPolicySetter)
+	if !ok {
+		rollbarError(nil, "transport %T does not support drop policies", c.Transport)
+		return
+	}
+	s.SetDropPolicy(policy, timeout)
+}
@@ -0,0 +1,96 @@
+package rollbar
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+type ecsContainerMetadata struct {
+	DockerID string `json:"DockerId"`
+	Name     string `json:"Name"`
+	Image    string `json:"Image"`
+}
+
+type ecsTaskMetadata struct {
+	Cluster string `json:"Cluster"`
+	TaskARN string `json:"TaskARN"`
+}
+
+// ECSEnricher returns an enricher which adds the details of the Amazon ECS task the application
+// runs in, on EC2 or Fargate, as data.server.ecs: the cluster, the task ARN, and the ID, name and
+// image of the container.
+//
+// The details are requested once in the background, with a timeout of DefaultLookupTimeout, from
+// the task metadata endpoint given by the ECS_CONTAINER_METADATA_URI_V4 (or
+// ECS_CONTAINER_METADATA_URI) environment variable. The enricher adds nothing until they are known,
+// and when the endpoint is not set or cannot be reached.
+func ECSEnricher() EnricherFunc {
+	lookup := startLookup("ecs", DefaultLookupTimeout, func(ctx context.Context) (interface{}, error) {
+		return ecsDetails(ctx)
+	})
+
+	return func(data map[string]interface{}) {
+		result, _ := lookup.result()
+		details, _ := result.(map[string]interface{})
+		if len(details) == 0 {
+			return
+		}
+		ecs := make(map[string]interface{}, len(details))
+		for k, v := range details {
+			ecs[k] = v
+		}
+		serverData(data)["ecs"] = ecs
+	}
+}
+
+// ecsDetails requests the details of the task from the metadata endpoint. It returns no details
+// outside of ECS.
+func ecsDetails(ctx context.Context) (map[string]interface{}, error) {
+	details := map[string]interface{}{}
+	uri := firstNonEmpty(os.Getenv("ECS_CONTAINER_METADATA_URI_V4"), os.Getenv("ECS_CONTAINER_METADATA_URI"))
+	if uri == "" {
+		return details, nil
+	}
+	uri = strings.TrimSuffix(uri, "/")
+	var container ecsContainerMetadata
+	if err := getJSON(ctx, uri, &container); err != nil {
+		return nil, err
+	}
+	addNonEmpty(details, "container_id", container.DockerID)
+	addNonEmpty(details, "container_name", container.Name)
+	addNonEmpty(details, "image", container.Image)
+	var task ecsTaskMetadata
+	if err := getJSON(ctx, uri+"/task", &task); err != nil {
+		return nil, err
+	}
+	addNonEmpty(details, "cluster", task.Cluster)
+	addNonEmpty(details, "task_arn", task.TaskARN)
+	return details, nil
+}
+
+// getJSON decodes the JSON response to a GET request of url into v.
+func getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func addNonEmpty(details map[string]interface{}, key, value string) {
+	if value != "" {
+		details[key] = value
+	}
+}
@@ -0,0 +1,59 @@
+package rollbar
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestECSEnricher(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/v4/abc":
+			w.Write([]byte(`{"DockerId":"cd189a933e5849daa93386466019ab50-2495160603","Name":"web","Image":"registry.example.com/web:1.2.3"}`))
+		case "/v4/abc/task":
+			w.Write([]byte(`{"Cluster":"arn:aws:ecs:us-west-2:111122223333:cluster/default","TaskARN":"arn:aws:ecs:us-west-2:111122223333:task/default/cd189a933e5849daa93386466019ab50"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	os.Setenv("ECS_CONTAINER_METADATA_URI_V4", ts.URL+"/v4/abc")
+	defer os.Unsetenv("ECS_CONTAINER_METADATA_URI_V4")
+
+	client := testClient()
+	client.AddEnricher(ECSEnricher())
+	waitLookups()
+	client.Message(ERR, "first")
+	client.Message(ERR, "second")
+
+	if requests != 2 {
+		t.Error("expected the metadata to be requested once, got requests:", requests)
+	}
+	data := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	ecs := data["server"].(map[string]interface{})["ecs"].(map[string]interface{})
+	expected := map[string]string{
+		"cluster":      "arn:aws:ecs:us-west-2:111122223333:cluster/default",
+		"task_arn":     "arn:aws:ecs:us-west-2:111122223333:task/default/cd189a933e5849daa93386466019ab50",
+		"container_id": "cd189a933e5849daa93386466019ab50-2495160603",
+		"image":        "registry.example.com/web:1.2.3",
+	}
+	for key, value := range expected {
+		if ecs[key] != value {
+			t.Errorf("expected %s to be %q, got: %v", key, value, ecs[key])
+		}
+	}
+}
+
+func TestECSEnricherOutsideECS(t *testing.T) {
+	data := map[string]interface{}{}
+	enrich := ECSEnricher()
+	waitLookups()
+	enrich(data)
+	if _, ok := data["server"]; ok {
+		t.Error("expected no details outside of ECS, got:", data["server"])
+	}
+}
@@ -0,0 +1,103 @@
+package rollbar
+
+import (
+	"fmt"
+	"sync"
+)
+
+type emptyItemPolicy int
+
+const (
+	// EmptyItemReport means report nil errors and empty messages, with the call site that reported
+	// them in data.notifier.diagnostic.empty_item so that it can be found.
+	EmptyItemReport emptyItemPolicy = iota
+	// EmptyItemDrop means drop nil errors and empty messages.
+	EmptyItemDrop
+	// EmptyItemPanic means panic when a nil error or an empty message is reported. It is meant for
+	// development and tests, to find the offending call sites early.
+	EmptyItemPanic
+)
+
+// emptyItemCounter counts the nil errors and empty messages reported per call site.
+type emptyItemCounter struct {
+	counts map[string]int
+	lock   sync.Mutex
+}
+
+// maxEmptyItemCallSites bounds the number of call sites counted by an emptyItemCounter.
+const maxEmptyItemCallSites = 100
+
+func (e *emptyItemCounter) record(site string) int {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if e.counts == nil {
+		e.counts = map[string]int{}
+	}
+	if _, ok := e.counts[site]; !ok && len(e.counts) >= maxEmptyItemCallSites {
+		site = "other"
+	}
+	e.counts[site]++
+	return e.counts[site]
+}
+
+func (e *emptyItemCounter) snapshot() map[string]int {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	counts := make(map[string]int, len(e.counts))
+	for site, count := range e.counts {
+		counts[site] = count
+	}
+	return counts
+}
+
+// SetEmptyItemPolicy sets how nil errors, e.g. from ErrorWithLevel(level, nil), and empty
+// messages, e.g. from Message(level, ""), are handled. The default value is EmptyItemReport.
+// Such calls are counted per call site whatever the policy, see EmptyItemCallSites.
+func (c *Client) SetEmptyItemPolicy(policy emptyItemPolicy) {
+	c.ApplyOptions(func(conf *configuration) { conf.emptyItems = policy })
+}
+
+// EmptyItemPolicy is the currently set policy for nil errors and empty messages.
+func (c *Client) EmptyItemPolicy() emptyItemPolicy {
+	return c.configuration().emptyItems
+}
+
+// EmptyItemCallSites returns the number of nil errors and empty messages reported to the client,
+// by call site, formatted as "file:line function". At most 100 call sites are counted separately;
+// the calls from other sites are counted under "other".
+func (c *Client) EmptyItemCallSites() map[string]int {
+	return c.diagnostic.emptyItems.snapshot()
+}
+
+// emptyItem handles the report of a nil error or an empty message according to the policy. It
+// returns the diagnostic to add to the item, or nil if the item must be dropped.
+func (c *Client) emptyItem(kind string) map[string]interface{} {
+	conf := c.configuration()
+	site := "unknown"
+	if frames := trimFrames(getCallersFrames(0), conf.skipPresets); len(frames) > 0 {
+		site = fmt.Sprintf("%s:%d %s", frames[0].File, frames[0].Line, frames[0].Function)
+	}
+	count := c.diagnostic.emptyItems.record(site)
+	switch conf.emptyItems {
+	case EmptyItemDrop:
+		return nil
+	case EmptyItemPanic:
+		panic(fmt.Sprintf("rollbar: %s reported at %s", kind, site))
+	}
+	return map[string]interface{}{
+		"kind":      kind,
+		"call_site": site,
+		"count":     count,
+	}
+}
+
+// addEmptyItemDiagnostic adds the diagnostic of a nil error or an empty message, if any, to the
+// notifier diagnostic of an item.
+func addEmptyItemDiagnostic(body map[string]interface{}, diagnostic map[string]interface{}) {
+	if diagnostic == nil {
+		return
+	}
+	data := body["data"].(map[string]interface{})
+	notifier := data["notifier"].(map[string]interface{})
+	notifier["diagnostic"].(map[string]interface{})["empty_item"] = diagnostic
+}
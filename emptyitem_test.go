@@ -0,0 +1,65 @@
+package rollbar
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEmptyItemReport(t *testing.T) {
+	client := testClient()
+
+	client.ErrorWithLevel(ERR, nil)
+	data := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	if data["title"] != nilErrTitle {
+		t.Error("wrong title, got:", data["title"])
+	}
+	diagnostic := data["notifier"].(map[string]interface{})["diagnostic"].(map[string]interface{})
+	empty := diagnostic["empty_item"].(map[string]interface{})
+	if empty["kind"] != "nil error" || !strings.Contains(empty["call_site"].(string), "emptyitem_test.go") {
+		t.Error("wrong diagnostic, got:", empty)
+	}
+
+	client.Message(INFO, "")
+	client.Message(INFO, "")
+	data = client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	diagnostic = data["notifier"].(map[string]interface{})["diagnostic"].(map[string]interface{})
+	if empty := diagnostic["empty_item"].(map[string]interface{}); empty["kind"] != "empty message" || empty["count"] != 1 {
+		t.Error("wrong diagnostic, got:", empty)
+	}
+
+	sites := client.EmptyItemCallSites()
+	if len(sites) != 3 {
+		t.Error("expected three call sites, got:", sites)
+	}
+}
+
+func TestEmptyItemDrop(t *testing.T) {
+	client := testClient()
+	client.SetEmptyItemPolicy(EmptyItemDrop)
+
+	client.ErrorWithLevel(ERR, nil)
+	client.Message(INFO, "")
+
+	if client.Transport.(*TestTransport).Body != nil {
+		t.Error("empty items should be dropped")
+	}
+	total := 0
+	for _, count := range client.EmptyItemCallSites() {
+		total += count
+	}
+	if total != 2 {
+		t.Error("dropped items should be counted, got:", client.EmptyItemCallSites())
+	}
+}
+
+func TestEmptyItemPanic(t *testing.T) {
+	client := testClient()
+	client.SetEmptyItemPolicy(EmptyItemPanic)
+
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(r.(string), "nil error") {
+			t.Error("expected a panic, got:", r)
+		}
+	}()
+	client.ErrorWithLevel(ERR, nil)
+}
@@ -0,0 +1,28 @@
+package rollbar
+
+// An EnricherFunc adds metadata to the data of an item before it is sent, e.g. details about the
+// infrastructure the application runs on. Enrichers are called for every item, before scrubbing
+// and the transform, in the order they were added. See Client.AddEnricher.
+type EnricherFunc func(data map[string]interface{})
+
+// AddEnricher adds an enricher called for every item reported by the client.
+func (c *Client) AddEnricher(enricher EnricherFunc) {
+	c.ApplyOptions(func(conf *configuration) {
+		conf.enrichers = append(conf.enrichers[:len(conf.enrichers):len(conf.enrichers)], enricher)
+	})
+}
+
+// ClearEnrichers removes all enrichers added with AddEnricher.
+func (c *Client) ClearEnrichers() {
+	c.ApplyOptions(func(conf *configuration) { conf.enrichers = nil })
+}
+
+// serverData returns data.server, creating it if needed.
+func serverData(data map[string]interface{}) map[string]interface{} {
+	server, _ := data["server"].(map[string]interface{})
+	if server == nil {
+		server = map[string]interface{}{}
+		data["server"] = server
+	}
+	return server
+}
@@ -0,0 +1,95 @@
+package rollbar
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// The environment variables read by NewFromEnv and InitFromEnv, named like in the other Rollbar
+// SDKs.
+const (
+	// EnvToken holds the access token. EnvAccessToken is read when it is not set.
+	EnvToken          = "ROLLBAR_TOKEN"
+	EnvAccessToken    = "ROLLBAR_ACCESS_TOKEN"
+	EnvEnvironment    = "ROLLBAR_ENVIRONMENT"
+	EnvCodeVersion    = "ROLLBAR_CODE_VERSION"
+	EnvServerHost     = "ROLLBAR_SERVER_HOST"
+	EnvServerRoot     = "ROLLBAR_SERVER_ROOT"
+	EnvEndpoint       = "ROLLBAR_ENDPOINT"
+	EnvPlatform       = "ROLLBAR_PLATFORM"
+	EnvEnabled        = "ROLLBAR_ENABLED"
+	EnvItemsPerMinute = "ROLLBAR_ITEMS_PER_MINUTE"
+)
+
+// NewFromEnv returns a Client using the asynchronous transport, configured from the environment
+// variables named by the Env constants, so that deployments following the twelve-factor app
+// methodology need no configuration code. Unset or empty variables leave the defaults of New. An
+// error is returned if ROLLBAR_ENABLED is not a boolean or ROLLBAR_ITEMS_PER_MINUTE not an integer.
+func NewFromEnv(opts ...clientOption) (*Client, error) {
+	c := NewAsync("", "", "", "", "", opts...)
+	if err := c.applyEnv(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// InitFromEnv configures the managed Client instance from the environment variables named by the
+// Env constants. See NewFromEnv.
+func InitFromEnv() error {
+	return std.applyEnv()
+}
+
+// applyEnv applies the configuration held by the environment variables. Nothing is applied when
+// one of them is invalid.
+func (c *Client) applyEnv() error {
+	var enabled *bool
+	if value := os.Getenv(EnvEnabled); value != "" {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("rollbar: invalid %s %q: %v", EnvEnabled, value, err)
+		}
+		enabled = &b
+	}
+	itemsPerMinute := -1
+	if value := os.Getenv(EnvItemsPerMinute); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("rollbar: invalid %s %q", EnvItemsPerMinute, value)
+		}
+		itemsPerMinute = n
+	}
+
+	token := os.Getenv(EnvToken)
+	if token == "" {
+		token = os.Getenv(EnvAccessToken)
+	}
+	if token != "" {
+		c.SetToken(token)
+	}
+	if value := os.Getenv(EnvEnvironment); value != "" {
+		c.SetEnvironment(value)
+	}
+	if value := os.Getenv(EnvCodeVersion); value != "" {
+		c.SetCodeVersion(value)
+	}
+	if value := os.Getenv(EnvServerHost); value != "" {
+		c.SetServerHost(value)
+	}
+	if value := os.Getenv(EnvServerRoot); value != "" {
+		c.SetServerRoot(value)
+	}
+	if value := os.Getenv(EnvEndpoint); value != "" {
+		c.SetEndpoint(value)
+	}
+	if value := os.Getenv(EnvPlatform); value != "" {
+		c.SetPlatform(value)
+	}
+	if enabled != nil {
+		c.SetEnabled(*enabled)
+	}
+	if itemsPerMinute >= 0 {
+		c.SetItemsPerMinute(itemsPerMinute)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package rollbar
+
+import (
+	"os"
+	"testing"
+)
+
+// setTestEnv sets the environment variables and returns a function unsetting them.
+func setTestEnv(env map[string]string) func() {
+	for key, value := range env {
+		os.Setenv(key, value)
+	}
+	return func() {
+		for key := range env {
+			os.Unsetenv(key)
+		}
+	}
+}
+
+func TestNewFromEnv(t *testing.T) {
+	defer setTestEnv(map[string]string{
+		EnvAccessToken:    "token",
+		EnvEnvironment:    "production",
+		EnvCodeVersion:    "abc123",
+		EnvEndpoint:       "https://rollbar.example.com/api/1/item/",
+		EnvEnabled:        "false",
+		EnvItemsPerMinute: "60",
+	})()
+
+	client, err := NewFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	if client.Token() != "token" || client.Environment() != "production" || client.CodeVersion() != "abc123" {
+		t.Error("wrong configuration, got:", client.Token(), client.Environment(), client.CodeVersion())
+	}
+	if client.Endpoint() != "https://rollbar.example.com/api/1/item/" || client.Config().Enabled || client.ItemsPerMinute() != 60 {
+		t.Error("wrong configuration, got:", client.Endpoint(), client.Config().Enabled, client.ItemsPerMinute())
+	}
+}
+
+func TestNewFromEnvInvalid(t *testing.T) {
+	defer setTestEnv(map[string]string{EnvEnabled: "maybe"})()
+
+	if _, err := NewFromEnv(); err == nil {
+		t.Error("expected an error for an invalid boolean")
+	}
+}
@@ -0,0 +1,64 @@
+package rollbar
+
+import "sync/atomic"
+
+// loggerProvider is implemented by the transports with a logger, see SetLogger.
+type loggerProvider interface {
+	getLogger() ClientLogger
+}
+
+func (t *interceptedTransport) getLogger() ClientLogger {
+	if inner, ok := t.Transport.(loggerProvider); ok {
+		return inner.getLogger()
+	}
+	return nil
+}
+
+// SetEnabledEnvironments sets the environments in which items are reported, e.g.
+// []string{"production", "staging"}, so that the client does nothing in development or test
+// environments without the application having to call SetEnabled depending on its environment. A
+// debug message is logged with the logger of the transport the first time an item is suppressed.
+// The default value is nil, which reports items in all environments.
+func (c *Client) SetEnabledEnvironments(environments []string) {
+	c.ApplyOptions(WithEnabledEnvironments(environments))
+	atomic.StoreInt32(c.diagnostic.suppressed, 0)
+}
+
+// EnabledEnvironments returns the environments in which items are reported, see
+// SetEnabledEnvironments.
+func (c *Client) EnabledEnvironments() []string {
+	return c.configuration().environments
+}
+
+// enabled returns whether items are reported, according to SetEnabled and SetEnabledEnvironments.
+func (c *Client) enabled() bool {
+	conf := c.configuration()
+	if !conf.enabled {
+		return false
+	}
+	if conf.environmentEnabled() {
+		return true
+	}
+	if atomic.CompareAndSwapInt32(c.diagnostic.suppressed, 0, 1) {
+		var logger ClientLogger
+		if t, ok := c.Transport.(loggerProvider); ok {
+			logger = t.getLogger()
+		}
+		rollbarDebug(logger, "items are not reported in the %q environment, see SetEnabledEnvironments",
+			conf.environment)
+	}
+	return false
+}
+
+// environmentEnabled returns whether the environment is one of the enabled environments.
+func (conf *configuration) environmentEnabled() bool {
+	if len(conf.environments) == 0 {
+		return true
+	}
+	for _, environment := range conf.environments {
+		if environment == conf.environment {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+package rollbar
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetEnabledEnvironments(t *testing.T) {
+	client := testClient()
+	client.SetEnvironment("production")
+	client.SetEnabledEnvironments([]string{"production", "staging"})
+	client.Message(INFO, "reported")
+	if client.Transport.(*TestTransport).Body == nil {
+		t.Error("expected the item to be reported in production")
+	}
+
+	logger := &bufferLogger{}
+	client = NewSync("token", "development", "", "", "")
+	client.SetLogger(logger)
+	client.SetEnabledEnvironments([]string{"production", "staging"})
+	client.Message(INFO, "suppressed")
+	client.Message(INFO, "suppressed again")
+	if len(logger.lines) != 1 || !strings.HasPrefix(logger.lines[0], "Rollbar debug:") ||
+		!strings.Contains(logger.lines[0], `"development"`) {
+		t.Error("expected a single debug message, got:", logger.lines)
+	}
+	if metrics := client.TransportMetrics(); metrics.Sent != 0 || metrics.Failed != 0 {
+		t.Error("expected no item to be sent, got:", metrics)
+	}
+}
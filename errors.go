@@ -2,10 +2,18 @@ package rollbar
 
 import (
 	"fmt"
+	"net/http"
+	"strconv"
+	"time"
 )
 
 // ErrHTTPError is an HTTP error status code as defined by
 // http://www.w3.org/Protocols/rfc2616/rfc2616-sec10.html
+//
+// The transports return an APIError wrapping the more specific ErrRateLimited, ErrUnauthorized and
+// ErrPayloadTooLarge errors for the corresponding status codes, or an ErrHTTPError for the others.
+// These wrap an ErrHTTPError, so errors.Is and errors.As can be used to match any of them, while
+// type assertions such as err.(ErrHTTPError) do not match the errors of the transports.
 type ErrHTTPError int
 
 // Error implements the error interface.
@@ -13,6 +21,186 @@ func (e ErrHTTPError) Error() string {
 	return fmt.Sprintf("rollbar: service returned status: %d", e)
 }
 
+// APIError is returned by the transports when the Rollbar API does not accept an item. It
+// describes the response and wraps the error of its status: ErrRateLimited, ErrUnauthorized,
+// ErrPayloadTooLarge, ErrRejected in strict mode, see SetStrict, or ErrHTTPError otherwise. The
+// wrapped errors can be matched with errors.Is and errors.As as well:
+//
+//	var apiErr *rollbar.APIError
+//	if errors.As(err, &apiErr) && !apiErr.Retryable {
+//		log.Printf("item rejected with status %d: %s", apiErr.StatusCode, apiErr.Body)
+//	}
+type APIError struct {
+	StatusCode int
+	// Body is the beginning of the body of the response, up to 4 KiB.
+	Body string
+	// RateLimit is the state of the rate limit of the project advertised by the API, if any.
+	RateLimit RateLimit
+	// Retryable is whether sending the item again later may succeed. The transports retry such
+	// items, up to their number of retry attempts.
+	Retryable bool
+	// Err is the error of the status.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error of the status.
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// RateLimit is the state of the rate limit of a project, as advertised by the Rollbar API in the
+// X-Rate-Limit-* headers of its responses. Its fields are zero when the headers are missing.
+type RateLimit struct {
+	// Limit is the number of items accepted per period.
+	Limit int
+	// Remaining is the number of items which can still be sent in the current period.
+	Remaining int
+	// Reset is the end of the current period.
+	Reset time.Time
+}
+
+// rateLimit parses the X-Rate-Limit-* headers of a response of the API.
+func rateLimit(header http.Header) RateLimit {
+	var limit RateLimit
+	limit.Limit, _ = strconv.Atoi(header.Get("X-Rate-Limit-Limit"))
+	limit.Remaining, _ = strconv.Atoi(header.Get("X-Rate-Limit-Remaining"))
+	if reset, err := strconv.ParseInt(header.Get("X-Rate-Limit-Reset"), 10, 64); err == nil && reset > 0 {
+		limit.Reset = time.Unix(reset, 0)
+	}
+	return limit
+}
+
+// ErrRateLimited is returned when the Rollbar API rejects an item because the rate limit of the
+// project or of the access token has been reached.
+type ErrRateLimited struct {
+	// RetryAfter is how long to wait before sending items again, as advertised by the API. It is
+	// zero if the API did not say.
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface.
+func (e ErrRateLimited) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("rollbar: rate limited, retry after %s", e.RetryAfter)
+	}
+	return "rollbar: rate limited"
+}
+
+// Is reports whether target is an ErrRateLimited, regardless of RetryAfter.
+func (e ErrRateLimited) Is(target error) bool {
+	_, ok := target.(ErrRateLimited)
+	return ok
+}
+
+// Unwrap returns the underlying ErrHTTPError.
+func (e ErrRateLimited) Unwrap() error {
+	return ErrHTTPError(http.StatusTooManyRequests)
+}
+
+// ErrUnauthorized is returned when the Rollbar API rejects the access token, either because it is
+// invalid (401) or because it does not have the post_server_item scope (403).
+type ErrUnauthorized struct {
+	StatusCode int
+}
+
+// Error implements the error interface.
+func (e ErrUnauthorized) Error() string {
+	return fmt.Sprintf("rollbar: access token rejected with status: %d", e.StatusCode)
+}
+
+// Is reports whether target is an ErrUnauthorized, regardless of StatusCode.
+func (e ErrUnauthorized) Is(target error) bool {
+	_, ok := target.(ErrUnauthorized)
+	return ok
+}
+
+// Unwrap returns the underlying ErrHTTPError.
+func (e ErrUnauthorized) Unwrap() error {
+	return ErrHTTPError(e.StatusCode)
+}
+
+// ErrPayloadTooLarge is returned when the Rollbar API rejects an item because its payload exceeds
+// the maximum size accepted by the API.
+type ErrPayloadTooLarge struct{}
+
+// Error implements the error interface.
+func (e ErrPayloadTooLarge) Error() string {
+	return "rollbar: payload too large"
+}
+
+// Unwrap returns the underlying ErrHTTPError.
+func (e ErrPayloadTooLarge) Unwrap() error {
+	return ErrHTTPError(http.StatusRequestEntityTooLarge)
+}
+
+// ErrRejected is returned by transports in strict mode, see SetStrict, when the Rollbar API
+// rejects an item for a reason without a more specific error, e.g. with the status 422 when the
+// payload fails validation. It wraps an ErrHTTPError.
+type ErrRejected struct {
+	StatusCode int
+	// Message is the error message returned by the API, if any.
+	Message string
+}
+
+// Error implements the error interface.
+func (e ErrRejected) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("rollbar: item rejected with status %d: %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("rollbar: item rejected with status %d", e.StatusCode)
+}
+
+// Is reports whether target is an ErrRejected, regardless of its fields.
+func (e ErrRejected) Is(target error) bool {
+	_, ok := target.(ErrRejected)
+	return ok
+}
+
+// Unwrap returns the underlying ErrHTTPError.
+func (e ErrRejected) Unwrap() error {
+	return ErrHTTPError(e.StatusCode)
+}
+
+// httpError returns the error describing an unsuccessful response of the Rollbar API received at
+// now.
+func httpError(resp *http.Response, now time.Time) error {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return ErrRateLimited{RetryAfter: retryAfter(resp.Header, now)}
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrUnauthorized{StatusCode: resp.StatusCode}
+	case http.StatusRequestEntityTooLarge:
+		return ErrPayloadTooLarge{}
+	default:
+		return ErrHTTPError(resp.StatusCode)
+	}
+}
+
+// retryAfter parses the Retry-After header, given either in seconds or as an HTTP date, falling
+// back to the X-Rate-Limit-Remaining-Seconds header sent by the Rollbar API. Dates are relative to
+// now.
+func retryAfter(header http.Header, now time.Time) time.Duration {
+	if value := header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+		if date, err := http.ParseTime(value); err == nil {
+			if d := date.Sub(now); d > 0 {
+				return d
+			}
+		}
+	}
+	if seconds, err := strconv.Atoi(header.Get("X-Rate-Limit-Remaining-Seconds")); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return 0
+}
+
 // ErrBufferFull is an error which is returned when the asynchronous transport is used and the
 // channel used for buffering items for sending to Rollbar is full.
 type ErrBufferFull struct{}
@@ -0,0 +1,143 @@
+package rollbar
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultEventsBuffer is the number of events buffered by the channel returned by Client.Events.
+const DefaultEventsBuffer = 100
+
+// An Event describes what happened to an item reported to a Client, see Client.Events. It is one
+// of ItemQueued, ItemSent, ItemDropped and RateLimited.
+type Event interface {
+	isEvent()
+}
+
+// ItemQueued is emitted when an item is handed to the transport of the client.
+type ItemQueued struct {
+	UUID string
+}
+
+// ItemSent is emitted when an item is accepted by the API.
+type ItemSent struct {
+	UUID string
+}
+
+// ItemDropped is emitted when an item is discarded without being accepted by the API.
+type ItemDropped struct {
+	UUID string
+	// Reason is one of the DropReason constants.
+	Reason string
+}
+
+// RateLimited is emitted when the API rejects an item because the rate limit of the project was
+// reached. The item is retried like after any temporary error.
+type RateLimited struct {
+	UUID string
+}
+
+func (ItemQueued) isEvent()  {}
+func (ItemSent) isEvent()    {}
+func (ItemDropped) isEvent() {}
+func (RateLimited) isEvent() {}
+
+// The reasons for which items are dropped, as found in ItemDropped events.
+const (
+	// DropReasonQueueFull is the reason of items dropped because the queue of the asynchronous
+	// transport was full.
+	DropReasonQueueFull = "queue full"
+	// DropReasonItemsPerMinute is the reason of items dropped because the limit set with
+	// SetItemsPerMinute was reached.
+	DropReasonItemsPerMinute = "items per minute limit reached"
+	// DropReasonStopped is the reason of items dropped because the context of the transport was
+	// canceled.
+	DropReasonStopped = "transport stopped"
+	// DropReasonFailed is the reason of items which could not be sent, after all retries.
+	DropReasonFailed = "send failed"
+	// DropReasonMaxItems is the reason of items dropped because the limit set with SetMaxItems was
+	// reached.
+	DropReasonMaxItems = "max items reached"
+)
+
+// eventStream delivers events to the channel returned by Client.Events. Events are only emitted
+// once the channel was requested, and are discarded when it is full so that reporting never
+// blocks on a slow consumer.
+type eventStream struct {
+	once       sync.Once
+	ch         chan Event
+	subscribed int32
+}
+
+func (s *eventStream) subscribe() <-chan Event {
+	s.once.Do(func() {
+		s.ch = make(chan Event, DefaultEventsBuffer)
+		atomic.StoreInt32(&s.subscribed, 1)
+	})
+	return s.ch
+}
+
+func (s *eventStream) emit(e Event) {
+	if s == nil || atomic.LoadInt32(&s.subscribed) == 0 {
+		return
+	}
+	select {
+	case s.ch <- e:
+	default:
+	}
+}
+
+// itemUUID returns the UUID of the item with the given body, if any.
+func itemUUID(body map[string]interface{}) string {
+	data, _ := body["data"].(map[string]interface{})
+	uuid, _ := data["uuid"].(string)
+	return uuid
+}
+
+// ensureItemUUID sets data.uuid on items without one, such as items built by hand or whose UUID
+// was removed by a transform, before they are first sent. The UUID is kept across retries and
+// payloads written on error, so that the API can discard the duplicates of an item.
+func ensureItemUUID(body map[string]interface{}) {
+	data, ok := body["data"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	if uuid, _ := data["uuid"].(string); uuid == "" {
+		data["uuid"] = UUIDGenerator.NewID()
+	}
+}
+
+func (m *transportMetrics) emit(e Event) {
+	m.lock.Lock()
+	events := m.events
+	m.lock.Unlock()
+	events.emit(e)
+}
+
+func (t *baseTransport) setEvents(events *eventStream) {
+	t.metrics.lock.Lock()
+	defer t.metrics.lock.Unlock()
+	t.metrics.events = events
+}
+
+func (t *interceptedTransport) setEvents(events *eventStream) {
+	if inner, ok := t.Transport.(interface{ setEvents(*eventStream) }); ok {
+		inner.setEvents(events)
+	}
+}
+
+// Events returns a channel of the events describing what happens to the items reported by the
+// client: their queueing, sending, dropping and rate limiting by the API. It lets applications
+// raise alerts, feed dashboards or synchronize tests without parsing the output of the logger.
+//
+// The same channel is returned by every call and is never closed. It buffers DefaultEventsBuffer
+// events, beyond which events are discarded until the channel is read from, so it should be
+// consumed continuously. Only ItemQueued events are emitted for transports which are not
+// implemented by this package, and the transport must be set before Events is called.
+func (c *Client) Events() <-chan Event {
+	ch := c.diagnostic.events.subscribe()
+	if t, ok := c.Transport.(interface{ setEvents(*eventStream) }); ok {
+		t.setEvents(c.diagnostic.events)
+	}
+	return ch
+}
@@ -0,0 +1,77 @@
+package rollbar
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientEvents(t *testing.T) {
+	status := http.StatusOK
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer ts.Close()
+
+	client := NewSync("token", "test", "", "", "")
+	client.SetEndpoint(ts.URL)
+	client.SetLogger(&SilentClientLogger{})
+	client.SetRetryAttempts(0)
+	client.Transport.SetPrintPayloadOnError(false)
+	events := client.Events()
+
+	client.Message(INFO, "sent")
+	status = http.StatusTooManyRequests
+	client.Message(INFO, "rate limited")
+
+	expected := []string{"ItemQueued", "ItemSent", "ItemQueued", "RateLimited", "ItemDropped"}
+	for _, kind := range expected {
+		var e Event
+		select {
+		case e = <-events:
+		default:
+			t.Fatal("missing event:", kind)
+		}
+		var uuid string
+		switch e := e.(type) {
+		case ItemQueued:
+			uuid = e.UUID
+		case ItemSent:
+			uuid = e.UUID
+		case RateLimited:
+			uuid = e.UUID
+		case ItemDropped:
+			uuid = e.UUID
+			if e.Reason != DropReasonFailed {
+				t.Error("wrong reason, got:", e.Reason)
+			}
+		}
+		if got := fmt.Sprintf("%T", e); got != "rollbar."+kind {
+			t.Errorf("expected %s, got: %s", kind, got)
+		}
+		if uuid == "" {
+			t.Error("expected the UUID of the item, got:", e)
+		}
+	}
+}
+
+func TestAsyncTransportDropEvents(t *testing.T) {
+	events := &eventStream{}
+	ch := events.subscribe()
+	transport := NewAsyncTransport("", "", 0)
+	transport.SetLogger(&SilentClientLogger{})
+	transport.SetPrintPayloadOnError(false)
+	transport.setEvents(events)
+
+	transport.Send(map[string]interface{}{"data": map[string]interface{}{"uuid": "item-1"}})
+
+	select {
+	case e := <-ch:
+		if e != (ItemDropped{UUID: "item-1", Reason: DropReasonQueueFull}) {
+			t.Error("wrong event, got:", e)
+		}
+	default:
+		t.Error("expected the item to be dropped")
+	}
+}
@@ -0,0 +1,208 @@
+package rollbar
+
+import (
+	"context"
+	"net/http"
+)
+
+// FanOutTransport is a Transport sending each item to several destinations, e.g. the project of a
+// team and a project of the whole organization. Each destination is a Transport of its own, with
+// its own token, endpoint, queue, retries and rate limits, so that a destination which is down or
+// rate limited does not hold back the others:
+//
+//	client.Transport = rollbar.NewFanOutTransport(
+//		rollbar.NewTransport(teamToken, rollbar.DefaultEndpoint),
+//		rollbar.NewTransport(orgToken, rollbar.DefaultEndpoint),
+//	)
+//
+// The first destination is the primary one: SetToken and SetEndpoint only apply to it, so that the
+// configuration of the client does not overwrite the tokens of the others. The other settings
+// apply to all the destinations.
+type FanOutTransport struct {
+	destinations []Transport
+}
+
+// NewFanOutTransport returns a transport sending each item to all the given transports.
+func NewFanOutTransport(destinations ...Transport) *FanOutTransport {
+	return &FanOutTransport{destinations: destinations}
+}
+
+// Destinations returns the transports items are sent to.
+func (t *FanOutTransport) Destinations() []Transport {
+	return t.destinations
+}
+
+// Send sends the body to all the destinations, with the same UUID so that the occurrence can be
+// looked up in any of the projects. It returns the first error returned by a destination, after
+// sending to all of them.
+func (t *FanOutTransport) Send(body map[string]interface{}) error {
+	ensureItemUUID(body)
+	var err error
+	for _, d := range t.destinations {
+		if dErr := d.Send(body); dErr != nil && err == nil {
+			err = dErr
+		}
+	}
+	return err
+}
+
+// Wait blocks until all the items queued by all the destinations have been sent.
+func (t *FanOutTransport) Wait() {
+	for _, d := range t.destinations {
+		d.Wait()
+	}
+}
+
+// Close closes all the destinations and returns the first error, if any.
+func (t *FanOutTransport) Close() error {
+	var err error
+	for _, d := range t.destinations {
+		if dErr := d.Close(); dErr != nil && err == nil {
+			err = dErr
+		}
+	}
+	return err
+}
+
+// primary returns the first destination, or nil if there are none.
+func (t *FanOutTransport) primary() Transport {
+	if len(t.destinations) == 0 {
+		return nil
+	}
+	return t.destinations[0]
+}
+
+// SetToken sets the token of the primary destination.
+func (t *FanOutTransport) SetToken(token string) {
+	if d := t.primary(); d != nil {
+		d.SetToken(token)
+	}
+}
+
+// SetEndpoint sets the endpoint of the primary destination.
+func (t *FanOutTransport) SetEndpoint(endpoint string) {
+	if d := t.primary(); d != nil {
+		d.SetEndpoint(endpoint)
+	}
+}
+
+// SetLogger sets the logger of all the destinations.
+func (t *FanOutTransport) SetLogger(logger ClientLogger) {
+	for _, d := range t.destinations {
+		d.SetLogger(logger)
+	}
+}
+
+// SetRetryAttempts sets the number of retries of all the destinations.
+func (t *FanOutTransport) SetRetryAttempts(retryAttempts int) {
+	for _, d := range t.destinations {
+		d.SetRetryAttempts(retryAttempts)
+	}
+}
+
+// SetPrintPayloadOnError sets whether all the destinations print payloads they fail to send.
+func (t *FanOutTransport) SetPrintPayloadOnError(printPayloadOnError bool) {
+	for _, d := range t.destinations {
+		d.SetPrintPayloadOnError(printPayloadOnError)
+	}
+}
+
+// SetHTTPClient sets the HTTP client of all the destinations.
+func (t *FanOutTransport) SetHTTPClient(httpClient *http.Client) {
+	for _, d := range t.destinations {
+		d.SetHTTPClient(httpClient)
+	}
+}
+
+// SetItemsPerMinute sets the max number of items sent per minute to each destination.
+func (t *FanOutTransport) SetItemsPerMinute(itemsPerMinute int) {
+	for _, d := range t.destinations {
+		d.SetItemsPerMinute(itemsPerMinute)
+	}
+}
+
+func (t *FanOutTransport) setContext(ctx context.Context) {
+	for _, d := range t.destinations {
+		d.setContext(ctx)
+	}
+}
+
+// SetItemsPerMinuteByLevel sets the max number of items of the given levels sent per minute to
+// each destination supporting it.
+func (t *FanOutTransport) SetItemsPerMinuteByLevel(limits map[string]int) {
+	for _, d := range t.destinations {
+		if l, ok := d.(levelLimiter); ok {
+			l.SetItemsPerMinuteByLevel(limits)
+		}
+	}
+}
+
+// SetSigningKey sets the key used to sign payloads by each destination supporting it.
+func (t *FanOutTransport) SetSigningKey(key []byte) {
+	for _, d := range t.destinations {
+		if s, ok := d.(signer); ok {
+			s.SetSigningKey(key)
+		}
+	}
+}
+
+// SetCompressPayloadOnError sets whether the destinations supporting it compress the payloads
+// they print on error.
+func (t *FanOutTransport) SetCompressPayloadOnError(compress bool) {
+	for _, d := range t.destinations {
+		if c, ok := d.(payloadCompressor); ok {
+			c.SetCompressPayloadOnError(compress)
+		}
+	}
+}
+
+// SetClock sets the clock used for rate limiting by the destinations supporting it.
+func (t *FanOutTransport) SetClock(clock Clock) {
+	for _, d := range t.destinations {
+		if c, ok := d.(clockSetter); ok {
+			c.SetClock(clock)
+		}
+	}
+}
+
+func (t *FanOutTransport) setEvents(events *eventStream) {
+	for _, d := range t.destinations {
+		if e, ok := d.(interface{ setEvents(*eventStream) }); ok {
+			e.setEvents(events)
+		}
+	}
+}
+
+func (t *FanOutTransport) setMetricsRecorder(recorder MetricsRecorder) {
+	for _, d := range t.destinations {
+		if m, ok := d.(interface{ setMetricsRecorder(MetricsRecorder) }); ok {
+			m.setMetricsRecorder(recorder)
+		}
+	}
+}
+
+func (t *FanOutTransport) getLogger() ClientLogger {
+	if l, ok := t.primary().(loggerProvider); ok {
+		return l.getLogger()
+	}
+	return nil
+}
+
+func (t *FanOutTransport) getHTTPClient() *http.Client {
+	if p, ok := t.primary().(httpClientProvider); ok {
+		return p.getHTTPClient()
+	}
+	return http.DefaultClient
+}
+
+func (t *FanOutTransport) warmup(ctx context.Context) error {
+	var err error
+	for _, d := range t.destinations {
+		if w, ok := d.(warmer); ok {
+			if wErr := w.warmup(ctx); wErr != nil && err == nil {
+				err = wErr
+			}
+		}
+	}
+	return err
+}
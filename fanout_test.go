@@ -0,0 +1,54 @@
+package rollbar
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type destinationRecorder struct {
+	tokens   []string
+	uuids    []interface{}
+	failures int
+}
+
+func (d *destinationRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body map[string]interface{}
+	json.NewDecoder(r.Body).Decode(&body)
+	d.tokens = append(d.tokens, r.Header.Get("X-Rollbar-Access-Token"))
+	d.uuids = append(d.uuids, body["data"].(map[string]interface{})["uuid"])
+	if len(d.tokens) <= d.failures {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}
+}
+
+func TestFanOutTransport(t *testing.T) {
+	team, org := &destinationRecorder{}, &destinationRecorder{failures: 1}
+	teamServer, orgServer := httptest.NewServer(team), httptest.NewServer(org)
+	defer teamServer.Close()
+	defer orgServer.Close()
+
+	transport := NewFanOutTransport(NewSyncTransport("team", teamServer.URL), NewSyncTransport("org", orgServer.URL))
+	transport.SetLogger(&SilentClientLogger{})
+	client := NewSync("team", "test", "", "", "")
+	client.Transport = transport
+
+	client.Message(ERR, "payment declined")
+
+	if len(team.tokens) != 1 || team.tokens[0] != "team" {
+		t.Error("expected one item sent with the team token, got:", team.tokens)
+	}
+	if len(org.tokens) != 2 || org.tokens[1] != "org" {
+		t.Error("expected the item to be retried with the org token, got:", org.tokens)
+	}
+	if team.uuids[0] == nil || team.uuids[0] != org.uuids[1] {
+		t.Error("expected the same UUID in both projects, got:", team.uuids, org.uuids)
+	}
+
+	client.SetToken("team-rotated")
+	client.Message(ERR, "payment declined")
+	if team.tokens[1] != "team-rotated" || org.tokens[2] != "org" {
+		t.Error("expected the token to only change for the primary destination, got:", team.tokens, org.tokens)
+	}
+}
@@ -0,0 +1,33 @@
+package rollbar
+
+import "context"
+
+// GoOption configures Go.
+type GoOption func(*goOptions)
+
+type goOptions struct {
+	policy RecoverPolicy
+}
+
+// WithRepanic makes Go panic again after reporting a panic, once the item was sent, so that the
+// process still crashes as it would with a plain go statement.
+func WithRepanic() GoOption {
+	return func(o *goOptions) {
+		o.policy = RepanicPolicy
+	}
+}
+
+// Go calls f in a new goroutine, and recovers and reports a panic to Rollbar if it occurs. A panic
+// in a goroutine started with a go statement cannot be recovered by its parent and crashes the
+// process, usually before it is reported; with Go it is reported as a critical item and the
+// goroutine returns, or the panic is resumed with WithRepanic.
+func (c *Client) Go(f func(), opts ...GoOption) {
+	var o goOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	go func() {
+		defer c.Recover(context.TODO(), o.policy)
+		f()
+	}()
+}
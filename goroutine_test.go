@@ -0,0 +1,44 @@
+package rollbar
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// notifyingTransport is a TestTransport which also hands the bodies it receives to a channel.
+type notifyingTransport struct {
+	TestTransport
+	sent chan map[string]interface{}
+}
+
+func (t *notifyingTransport) Send(body map[string]interface{}) error {
+	t.sent <- body
+	return nil
+}
+
+func TestGo(t *testing.T) {
+	client := testClient()
+	transport := &notifyingTransport{sent: make(chan map[string]interface{}, 1)}
+	client.Transport = transport
+
+	client.Go(func() {
+		panic(errors.New("goroutine failed"))
+	})
+
+	select {
+	case body := <-transport.sent:
+		data := body["data"].(map[string]interface{})
+		if data["level"] != "critical" {
+			t.Error("expected a critical item, got:", data["level"])
+		}
+		if errorFromData(data)["message"] != "goroutine failed" {
+			t.Error("data should have correct error message")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the panic was not reported")
+	}
+	if transport.WaitCalled {
+		t.Error("Wait called unexpectedly")
+	}
+}
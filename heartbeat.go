@@ -0,0 +1,190 @@
+package rollbar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultHeartbeatInterval is the interval used by StartHeartbeat when a non-positive interval
+	// is given.
+	DefaultHeartbeatInterval = time.Minute
+	// DefaultHeartbeatMessage is the message body of the items reported by a Heartbeat.
+	DefaultHeartbeatMessage = "heartbeat"
+)
+
+// A HeartbeatSender delivers a heartbeat payload somewhere other than the Rollbar item API, for
+// example to a check-in URL of a Rollbar-compatible monitor. Returning an error only causes it to
+// be logged, the Heartbeat keeps running.
+type HeartbeatSender func(ctx context.Context, payload map[string]interface{}) error
+
+// A Heartbeat periodically reports that the process is alive and able to reach Rollbar, so that
+// the absence of errors can be distinguished from a broken reporting pipeline. By default every
+// beat is reported as a low-level (debug) message item. Use Client.StartHeartbeat to create one.
+type Heartbeat struct {
+	ctx      context.Context
+	client   *Client
+	interval time.Duration
+	level    string
+	message  string
+	payload  func() map[string]interface{}
+	sender   HeartbeatSender
+
+	started  time.Time
+	sequence int
+	stop     chan struct{}
+	done     chan struct{}
+	once     sync.Once
+}
+
+type heartbeatOption func(*Heartbeat)
+
+// WithHeartbeatLevel sets the level of the items reported for each beat. The default is DEBUG.
+func WithHeartbeatLevel(level string) heartbeatOption {
+	return func(h *Heartbeat) {
+		h.level = level
+	}
+}
+
+// WithHeartbeatMessage sets the message body of the items reported for each beat. The default is
+// DefaultHeartbeatMessage.
+func WithHeartbeatMessage(message string) heartbeatOption {
+	return func(h *Heartbeat) {
+		h.message = message
+	}
+}
+
+// WithHeartbeatPayload sets a function which is called on every beat and whose result is merged
+// into the heartbeat payload, e.g. to include queue depths or other liveness information.
+func WithHeartbeatPayload(payload func() map[string]interface{}) heartbeatOption {
+	return func(h *Heartbeat) {
+		h.payload = payload
+	}
+}
+
+// WithHeartbeatSender replaces the reporting of heartbeat items with the given sender.
+func WithHeartbeatSender(sender HeartbeatSender) heartbeatOption {
+	return func(h *Heartbeat) {
+		h.sender = sender
+	}
+}
+
+// HeartbeatURLSender returns a HeartbeatSender which POSTs the payload as JSON to the given URL.
+// If httpClient is nil then http.DefaultClient is used.
+func HeartbeatURLSender(url string, httpClient *http.Client) HeartbeatSender {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return func(ctx context.Context, payload map[string]interface{}) error {
+		jsonBody, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		req, err := http.NewRequest("POST", url, bytes.NewReader(jsonBody))
+		if err != nil {
+			return err
+		}
+		req = req.WithContext(ctx)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return ErrHTTPError(resp.StatusCode)
+		}
+		return nil
+	}
+}
+
+// StartHeartbeat starts reporting a heartbeat every interval until Stop is called on the returned
+// Heartbeat or the context of the Client is done.
+func (c *Client) StartHeartbeat(interval time.Duration, opts ...heartbeatOption) *Heartbeat {
+	if interval <= 0 {
+		interval = DefaultHeartbeatInterval
+	}
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	h := &Heartbeat{
+		ctx:      ctx,
+		client:   c,
+		interval: interval,
+		level:    DEBUG,
+		message:  DefaultHeartbeatMessage,
+		started:  time.Now(),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	go h.run()
+	return h
+}
+
+// Stop stops the Heartbeat and blocks until no further beats will be reported. It is safe to call
+// Stop more than once.
+func (h *Heartbeat) Stop() {
+	h.once.Do(func() {
+		close(h.stop)
+	})
+	<-h.done
+}
+
+// Interval is the time between two beats.
+func (h *Heartbeat) Interval() time.Duration {
+	return h.interval
+}
+
+func (h *Heartbeat) run() {
+	defer close(h.done)
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-h.stop:
+			return
+		case <-h.ctx.Done():
+			return
+		case <-ticker.C:
+			h.beat()
+		}
+	}
+}
+
+func (h *Heartbeat) beat() {
+	h.sequence++
+	payload := h.buildPayload()
+	if h.sender != nil {
+		if err := h.sender(h.ctx, payload); err != nil {
+			rollbarError(nil, "heartbeat failed: %s", err.Error())
+		}
+		return
+	}
+	h.client.MessageWithExtrasAndContext(h.ctx, h.level, h.message, payload)
+}
+
+func (h *Heartbeat) buildPayload() map[string]interface{} {
+	payload := map[string]interface{}{}
+	if h.payload != nil {
+		for k, v := range h.payload() {
+			payload[k] = v
+		}
+	}
+	payload["heartbeat"] = map[string]interface{}{
+		"sequence":         h.sequence,
+		"interval_seconds": h.interval.Seconds(),
+		"uptime_seconds":   int64(time.Since(h.started).Seconds()),
+	}
+	return payload
+}
@@ -0,0 +1,99 @@
+package rollbar
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHeartbeatReportsItem(t *testing.T) {
+	client := testClient()
+	hb := client.StartHeartbeat(time.Millisecond, WithHeartbeatLevel(INFO), WithHeartbeatMessage("alive"),
+		WithHeartbeatPayload(func() map[string]interface{} {
+			return map[string]interface{}{"queue": 3}
+		}))
+	time.Sleep(20 * time.Millisecond)
+	hb.Stop()
+	hb.Stop()
+
+	transport := client.Transport.(*TestTransport)
+	if transport.Body == nil {
+		t.Fatal("expected a heartbeat item to be reported")
+	}
+	data := transport.Body["data"].(map[string]interface{})
+	if data["level"] != INFO {
+		t.Error("wrong level, got:", data["level"])
+	}
+	if data["title"] != "alive" {
+		t.Error("wrong title, got:", data["title"])
+	}
+	custom := data["custom"].(map[string]interface{})
+	if custom["queue"] != 3 {
+		t.Error("expected payload to be merged into custom data, got:", custom)
+	}
+	heartbeat := custom["heartbeat"].(map[string]interface{})
+	if heartbeat["sequence"].(int) < 1 {
+		t.Error("expected a positive sequence, got:", heartbeat["sequence"])
+	}
+}
+
+func TestHeartbeatSender(t *testing.T) {
+	client := testClient()
+	beats := make(chan map[string]interface{}, 10)
+	hb := client.StartHeartbeat(time.Millisecond, WithHeartbeatSender(func(ctx context.Context, payload map[string]interface{}) error {
+		select {
+		case beats <- payload:
+		default:
+		}
+		return nil
+	}))
+	payload := <-beats
+	hb.Stop()
+
+	if payload["heartbeat"] == nil {
+		t.Error("expected heartbeat details in payload")
+	}
+	if client.Transport.(*TestTransport).Body != nil {
+		t.Error("no item should be reported when a sender is set")
+	}
+}
+
+func TestHeartbeatStopsWithClientContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	client := NewAsync("", "test", "", "", "", WithClientContext(ctx))
+	client.Transport = &TestTransport{}
+	hb := client.StartHeartbeat(time.Hour)
+	if hb.Interval() != time.Hour {
+		t.Error("wrong interval, got:", hb.Interval())
+	}
+	cancel()
+	hb.Stop()
+}
+
+func TestHeartbeatURLSender(t *testing.T) {
+	var received map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer ts.Close()
+
+	sender := HeartbeatURLSender(ts.URL, nil)
+	if err := sender(context.Background(), map[string]interface{}{"hello": "world"}); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if received["hello"] != "world" {
+		t.Error("expected payload to be posted, got:", received)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer failing.Close()
+	err := HeartbeatURLSender(failing.URL, nil)(context.Background(), nil)
+	if err != ErrHTTPError(http.StatusNotFound) {
+		t.Error("expected ErrHTTPError, got:", err)
+	}
+}
@@ -0,0 +1,76 @@
+package rollbar
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+)
+
+// AddIgnoreError makes the Client ignore the errors matching target, as reported by errors.Is, at
+// any depth of their chain of causes, e.g. AddIgnoreError(context.Canceled). Ignored errors are
+// dropped before their item is built, so that known noisy errors neither capture stack traces nor
+// take space in the queue.
+func (c *Client) AddIgnoreError(target error) {
+	c.ApplyOptions(func(conf *configuration) {
+		conf.ignoreErrors = append(conf.ignoreErrors[:len(conf.ignoreErrors):len(conf.ignoreErrors)], target)
+	})
+}
+
+// AddIgnoreErrorType makes the Client ignore the errors of the same type as sample at any depth of
+// their chain of causes, e.g. AddIgnoreErrorType(&ValidationError{}) to ignore all the
+// *ValidationError errors whatever their fields. Ignored errors are dropped before their item is
+// built.
+func (c *Client) AddIgnoreErrorType(sample error) {
+	c.ApplyOptions(func(conf *configuration) {
+		conf.ignoreTypes = append(conf.ignoreTypes[:len(conf.ignoreTypes):len(conf.ignoreTypes)], reflect.TypeOf(sample))
+	})
+}
+
+// AddIgnoreMessagePattern makes the Client ignore the errors whose message, and the messages whose
+// text, matches pattern, e.g. regexp.MustCompile("^write: broken pipe$"). Ignored errors and
+// messages are dropped before their item is built.
+func (c *Client) AddIgnoreMessagePattern(pattern *regexp.Regexp) {
+	c.ApplyOptions(func(conf *configuration) {
+		conf.ignoreMsgs = append(conf.ignoreMsgs[:len(conf.ignoreMsgs):len(conf.ignoreMsgs)], pattern)
+	})
+}
+
+// ClearIgnores removes the errors, error types and message patterns ignored by the Client.
+func (c *Client) ClearIgnores() {
+	c.ApplyOptions(func(conf *configuration) {
+		conf.ignoreErrors, conf.ignoreTypes, conf.ignoreMsgs = nil, nil, nil
+	})
+}
+
+// ignoredError returns whether err, or one of its causes, is ignored.
+func (c configuration) ignoredError(err error) bool {
+	if len(c.ignoreErrors) == 0 && len(c.ignoreTypes) == 0 && len(c.ignoreMsgs) == 0 {
+		return false
+	}
+	if c.ignoredMessage(err.Error()) {
+		return true
+	}
+	for cause := err; cause != nil; cause = c.unwrapper(cause) {
+		for _, target := range c.ignoreErrors {
+			if errors.Is(cause, target) {
+				return true
+			}
+		}
+		for _, typ := range c.ignoreTypes {
+			if reflect.TypeOf(cause) == typ {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ignoredMessage returns whether msg matches one of the ignored message patterns.
+func (c configuration) ignoredMessage(msg string) bool {
+	for _, pattern := range c.ignoreMsgs {
+		if pattern.MatchString(msg) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package rollbar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+)
+
+type validationError struct {
+	field string
+}
+
+func (e *validationError) Error() string {
+	return "invalid " + e.field
+}
+
+func TestIgnoreLists(t *testing.T) {
+	client := testClient()
+	client.AddIgnoreError(context.Canceled)
+	client.AddIgnoreErrorType(&validationError{})
+	client.AddIgnoreMessagePattern(regexp.MustCompile("broken pipe$"))
+
+	ignored := []error{
+		fmt.Errorf("serving request: %w", context.Canceled),
+		fmt.Errorf("decoding body: %w", &validationError{field: "email"}),
+		errors.New("write tcp 10.0.0.1:443: broken pipe"),
+	}
+	for _, err := range ignored {
+		if uuid := client.ErrorWithExtrasCtx(context.Background(), ERR, err, noExtras); uuid != "" {
+			t.Errorf("expected %q to be ignored", err)
+		}
+	}
+	if uuid := client.MessageWithExtrasCtx(context.Background(), INFO, "client went away: broken pipe", noExtras); uuid != "" {
+		t.Error("expected the message to be ignored")
+	}
+	if body := client.Transport.(*TestTransport).Body; body != nil {
+		t.Fatal("expected no item, got:", body)
+	}
+
+	if uuid := client.ErrorWithExtrasCtx(context.Background(), ERR, errors.New("database unreachable"), noExtras); uuid == "" {
+		t.Error("expected other errors to be reported")
+	}
+
+	client.ClearIgnores()
+	if uuid := client.ErrorWithExtrasCtx(context.Background(), ERR, context.Canceled, noExtras); uuid == "" {
+		t.Error("expected the error to be reported once the ignores are cleared")
+	}
+}
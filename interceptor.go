@@ -0,0 +1,49 @@
+package rollbar
+
+// A SendFunc sends the body of an item, as Transport.Send does.
+type SendFunc func(body map[string]interface{}) error
+
+// A TransportInterceptor wraps the sending of items by a transport. The returned SendFunc may
+// inspect or modify the body before calling next, or not call next at all to prevent the item from
+// being sent, and may inspect the error returned by next. This allows cross-cutting concerns such
+// as signing, audit logging or encryption to be implemented once and combined with any Transport.
+//
+// For the asynchronous transport, interceptors are called when items are queued rather than when
+// they are sent to the API.
+type TransportInterceptor func(next SendFunc) SendFunc
+
+// WrapTransport returns a Transport which sends items through the given interceptors before
+// passing them to t. The first interceptor is the outermost: it is called first and sees the error
+// returned by all the others. All other methods are delegated to t.
+//
+//	client.Transport = rollbar.WrapTransport(client.Transport, auditLog, sign)
+func WrapTransport(t Transport, interceptors ...TransportInterceptor) Transport {
+	send := SendFunc(t.Send)
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		send = interceptors[i](send)
+	}
+	return &interceptedTransport{Transport: t, send: send, interceptors: interceptors}
+}
+
+type interceptedTransport struct {
+	Transport
+	send         SendFunc
+	interceptors []TransportInterceptor
+}
+
+// Send sends the body through the interceptors.
+func (t *interceptedTransport) Send(body map[string]interface{}) error {
+	return t.send(body)
+}
+
+func (t *interceptedTransport) SetClock(clock Clock) {
+	if inner, ok := t.Transport.(clockSetter); ok {
+		inner.SetClock(clock)
+	}
+}
+
+func (t *interceptedTransport) SetCompressPayloadOnError(compress bool) {
+	if inner, ok := t.Transport.(payloadCompressor); ok {
+		inner.SetCompressPayloadOnError(compress)
+	}
+}
@@ -0,0 +1,70 @@
+package rollbar
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWrapTransport(t *testing.T) {
+	var calls []string
+	record := func(name string) TransportInterceptor {
+		return func(next SendFunc) SendFunc {
+			return func(body map[string]interface{}) error {
+				calls = append(calls, name)
+				err := next(body)
+				calls = append(calls, name+" done")
+				return err
+			}
+		}
+	}
+	sign := func(next SendFunc) SendFunc {
+		return func(body map[string]interface{}) error {
+			body["signature"] = "abc"
+			return next(body)
+		}
+	}
+	errDropped := errors.New("dropped")
+	drop := func(next SendFunc) SendFunc {
+		return func(body map[string]interface{}) error {
+			if body["data"].(map[string]interface{})["level"] == DEBUG {
+				return errDropped
+			}
+			return next(body)
+		}
+	}
+
+	client := testClient()
+	inner := client.Transport.(*TestTransport)
+	client.Transport = WrapTransport(inner, record("outer"), drop, sign, record("inner"))
+
+	client.Message(ERR, "sent")
+	if inner.Body == nil || inner.Body["signature"] != "abc" {
+		t.Fatal("expected the signed body to be sent, got:", inner.Body)
+	}
+	expected := []string{"outer", "inner", "inner done", "outer done"}
+	if len(calls) != len(expected) {
+		t.Fatal("unexpected calls:", calls)
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Error("unexpected calls:", calls)
+			break
+		}
+	}
+
+	inner.Body = nil
+	if err := client.Transport.Send(map[string]interface{}{"data": map[string]interface{}{"level": DEBUG}}); err != errDropped {
+		t.Error("expected the interceptor to short-circuit the send, got:", err)
+	}
+	if inner.Body != nil {
+		t.Error("expected the item not to be sent")
+	}
+
+	client.Wait()
+	if !inner.WaitCalled {
+		t.Error("expected Wait to be delegated")
+	}
+	if client.Config().Transport.Interceptors != 4 {
+		t.Error("expected the interceptors in the configuration, got:", client.Config().Transport)
+	}
+}
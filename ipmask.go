@@ -0,0 +1,26 @@
+//go:build go1.18
+// +build go1.18
+
+package rollbar
+
+import "net/netip"
+
+// anonymizeIP returns ip with all but its first v4Bits bits, or v6Bits bits for an IPv6 address,
+// set to zero. IPv4-mapped IPv6 addresses are masked as IPv4 addresses and zones are dropped. An
+// empty string is returned if ip cannot be parsed, so that malformed addresses are not reported.
+func anonymizeIP(ip string, v4Bits, v6Bits int) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+	addr = addr.Unmap().WithZone("")
+	bits := v6Bits
+	if addr.Is4() {
+		bits = v4Bits
+	}
+	prefix, err := addr.Prefix(bits)
+	if err != nil {
+		return ""
+	}
+	return prefix.Addr().String()
+}
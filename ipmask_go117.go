@@ -0,0 +1,26 @@
+//go:build !go1.18
+// +build !go1.18
+
+package rollbar
+
+import (
+	"net"
+	"strings"
+)
+
+// anonymizeIP returns ip with all but its first v4Bits bits, or v6Bits bits for an IPv6 address,
+// set to zero. IPv4-mapped IPv6 addresses are masked as IPv4 addresses and zones are dropped. An
+// empty string is returned if ip cannot be parsed, so that malformed addresses are not reported.
+func anonymizeIP(ip string, v4Bits, v6Bits int) string {
+	if i := strings.IndexByte(ip, '%'); i >= 0 {
+		ip = ip[:i]
+	}
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ""
+	}
+	if v4 := parsed.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(v4Bits, 8*net.IPv4len)).String()
+	}
+	return parsed.Mask(net.CIDRMask(v6Bits, 8*net.IPv6len)).String()
+}
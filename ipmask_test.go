@@ -0,0 +1,48 @@
+package rollbar
+
+import "testing"
+
+func TestAnonymizeIP(t *testing.T) {
+	cases := []struct {
+		ip             string
+		v4Bits, v6Bits int
+		expected       string
+	}{
+		{"1.2.3.4", 24, 48, "1.2.3.0"},
+		{"1.2.3.4", 16, 48, "1.2.0.0"},
+		{"2001:db8:85a3:8d3:1319:8a2e:370:7348", 24, 48, "2001:db8:85a3::"},
+		{"2001:db8:85a3:8d3:1319:8a2e:370:7348", 24, 64, "2001:db8:85a3:8d3::"},
+		{"::ffff:1.2.3.4", 24, 48, "1.2.3.0"},
+		{"fe80::1%eth0", 24, 48, "fe80::"},
+		// Malformed addresses are not reported.
+		{"1.2.3", 24, 48, ""},
+		{"not-an-ip", 24, 48, ""},
+	}
+	for _, c := range cases {
+		if actual := anonymizeIP(c.ip, c.v4Bits, c.v6Bits); actual != c.expected {
+			t.Errorf("anonymizeIP(%q, %d, %d) = %q, expected %q", c.ip, c.v4Bits, c.v6Bits, actual, c.expected)
+		}
+	}
+}
+
+func TestSetIPAnonymizationMask(t *testing.T) {
+	client := testClient()
+	client.SetCaptureIp(CaptureIpAnonymize)
+	if err := client.SetIPAnonymizationMask(16, 32); err != nil {
+		t.Fatal(err)
+	}
+	info := &RequestInfo{URL: "/", UserIP: "10.20.30.40"}
+	if ip := requestInfoDetails(*client.configuration(), info)["user_ip"]; ip != "10.20.0.0" {
+		t.Error("expected the /16 network of the client, got:", ip)
+	}
+
+	if err := client.SetIPAnonymizationMask(33, 48); err == nil {
+		t.Error("expected an error for an invalid IPv4 mask")
+	}
+	if err := client.SetIPAnonymizationMask(24, 129); err == nil {
+		t.Error("expected an error for an invalid IPv6 mask")
+	}
+	if v4, v6 := client.IPAnonymizationMask(); v4 != 16 || v6 != 32 {
+		t.Errorf("the masks should be left unchanged, got /%d and /%d", v4, v6)
+	}
+}
@@ -0,0 +1,86 @@
+package rollbar
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultPodInfoDir is the directory where the Kubernetes Downward API volume is conventionally
+// mounted.
+const DefaultPodInfoDir = "/etc/podinfo"
+
+// serviceAccountNamespaceFile holds the namespace of the pod in every container which mounts the
+// service account token.
+const serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// KubernetesEnricher returns an enricher which adds the details of the Kubernetes pod the
+// application runs in as data.server.kubernetes, so that occurrences can be traced back to pods.
+//
+// The details are read once in the background, following the Downward API conventions: the POD_NAME, POD_NAMESPACE,
+// NODE_NAME and CONTAINER_IMAGE environment variables, or else the files name, namespace and
+// node_name of a Downward API volume mounted at podInfoDir (DefaultPodInfoDir if empty). The pod
+// name falls back to the hostname and the namespace to the one of the service account. The
+// container image is not exposed by the Downward API and is only known when set as CONTAINER_IMAGE
+// in the pod spec. The enricher adds nothing until the details are known, and when none is found.
+func KubernetesEnricher(podInfoDir string) EnricherFunc {
+	if podInfoDir == "" {
+		podInfoDir = DefaultPodInfoDir
+	}
+	lookup := startLookup("kubernetes", DefaultLookupTimeout, func(context.Context) (interface{}, error) {
+		return kubernetesDetails(podInfoDir), nil
+	})
+
+	return func(data map[string]interface{}) {
+		result, _ := lookup.result()
+		details, _ := result.(map[string]interface{})
+		if len(details) == 0 {
+			return
+		}
+		kubernetes := make(map[string]interface{}, len(details))
+		for k, v := range details {
+			kubernetes[k] = v
+		}
+		serverData(data)["kubernetes"] = kubernetes
+	}
+}
+
+// kubernetesDetails reads the details of the pod. It returns no details outside of Kubernetes.
+func kubernetesDetails(podInfoDir string) map[string]interface{} {
+	details := map[string]interface{}{}
+	add := func(key, value string) {
+		addNonEmpty(details, key, value)
+	}
+	add("pod", firstNonEmpty(os.Getenv("POD_NAME"), readPodInfo(podInfoDir, "name")))
+	add("namespace", firstNonEmpty(os.Getenv("POD_NAMESPACE"), readPodInfo(podInfoDir, "namespace"),
+		readTrimmed(serviceAccountNamespaceFile)))
+	add("node", firstNonEmpty(os.Getenv("NODE_NAME"), readPodInfo(podInfoDir, "node_name")))
+	add("container_image", os.Getenv("CONTAINER_IMAGE"))
+	if _, ok := details["pod"]; !ok && len(details) > 0 {
+		add("pod", lookupHostname())
+	}
+	return details
+}
+
+func readPodInfo(dir, name string) string {
+	return readTrimmed(filepath.Join(dir, name))
+}
+
+func readTrimmed(path string) string {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
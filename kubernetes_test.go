@@ -0,0 +1,66 @@
+package rollbar
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestKubernetesEnricher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "podinfo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, "name"), []byte("web-7d9f8-x2k4q\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "namespace"), []byte("shop\n"), 0644)
+	os.Setenv("NODE_NAME", "node-1")
+	os.Setenv("CONTAINER_IMAGE", "registry.example.com/web:1.2.3")
+	defer os.Unsetenv("NODE_NAME")
+	defer os.Unsetenv("CONTAINER_IMAGE")
+
+	client := testClient()
+	client.AddEnricher(KubernetesEnricher(dir))
+	waitLookups()
+	client.Message(ERR, "out of stock")
+
+	data := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	server := data["server"].(map[string]interface{})
+	kubernetes := server["kubernetes"].(map[string]interface{})
+	expected := map[string]string{
+		"pod":             "web-7d9f8-x2k4q",
+		"namespace":       "shop",
+		"node":            "node-1",
+		"container_image": "registry.example.com/web:1.2.3",
+	}
+	for key, value := range expected {
+		if kubernetes[key] != value {
+			t.Errorf("expected %s to be %q, got: %v", key, value, kubernetes[key])
+		}
+	}
+	if server["host"] == nil {
+		t.Error("expected the server host to be kept")
+	}
+}
+
+func TestKubernetesEnricherOutsideKubernetes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "podinfo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	data := map[string]interface{}{}
+	enrich := KubernetesEnricher(dir)
+	waitLookups()
+	enrich(data)
+	if _, ok := data["server"]; ok && !fileExists(serviceAccountNamespaceFile) {
+		t.Error("expected no details outside of Kubernetes, got:", data["server"])
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
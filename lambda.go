@@ -0,0 +1,108 @@
+package rollbar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// A LambdaContextFunc returns the request ID and invoked function ARN of the Lambda invocation
+// described by ctx. The rollbar package does not depend on aws-lambda-go, so these are only
+// reported when a LambdaContextFunc reading them from lambdacontext.FromContext is given with
+// WithLambdaContext; contrib/lambda provides one.
+type LambdaContextFunc func(ctx context.Context) (requestID, functionARN string)
+
+type lambdaWrapper struct {
+	contextFunc     LambdaContextFunc
+	deadlineWarning time.Duration
+}
+
+// LambdaOption configures LambdaWrapper.
+type LambdaOption func(*lambdaWrapper)
+
+// WithLambdaContext sets the function used to read the request ID and function ARN of each
+// invocation.
+func WithLambdaContext(f LambdaContextFunc) LambdaOption {
+	return func(w *lambdaWrapper) {
+		w.contextFunc = f
+	}
+}
+
+// WithLambdaDeadlineWarning makes LambdaWrapper report a warning when an invocation is still
+// running when less than threshold remains before the deadline of its context, which usually
+// means the function is about to time out. The handler must accept a context.Context as its first
+// argument.
+func WithLambdaDeadlineWarning(threshold time.Duration) LambdaOption {
+	return func(w *lambdaWrapper) {
+		w.deadlineWarning = threshold
+	}
+}
+
+// lambdaDetails describes an invocation as reported in custom.lambda. Most of it comes from the
+// environment variables set by the Lambda runtime, which are the same ones lambdacontext reads.
+func (w *lambdaWrapper) lambdaDetails(ctx context.Context, coldStart bool) map[string]interface{} {
+	details := map[string]interface{}{
+		"function_name":    os.Getenv("AWS_LAMBDA_FUNCTION_NAME"),
+		"function_version": os.Getenv("AWS_LAMBDA_FUNCTION_VERSION"),
+		"cold_start":       coldStart,
+	}
+	if limit, err := strconv.Atoi(os.Getenv("AWS_LAMBDA_FUNCTION_MEMORY_SIZE")); err == nil {
+		details["memory_limit_mb"] = limit
+	}
+	if stream := os.Getenv("AWS_LAMBDA_LOG_STREAM_NAME"); stream != "" {
+		details["log_stream_name"] = stream
+	}
+	if w.contextFunc != nil && ctx != nil {
+		requestID, functionARN := w.contextFunc(ctx)
+		if requestID != "" {
+			details["request_id"] = requestID
+		}
+		if functionARN != "" {
+			details["function_arn"] = functionARN
+		}
+	}
+	return details
+}
+
+// watchDeadline reports a warning when the invocation is still running close to the deadline of
+// ctx. The returned function must be called when the invocation returns, and only once.
+func (w *lambdaWrapper) watchDeadline(c *Client, ctx context.Context, details map[string]interface{}) func() {
+	if w.deadlineWarning <= 0 || ctx == nil {
+		return func() {}
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return func() {}
+	}
+	reported := make(chan struct{})
+	timer := time.AfterFunc(time.Until(deadline)-w.deadlineWarning, func() {
+		defer close(reported)
+		c.MessageWithExtrasAndContext(ctx, WARN, "Lambda invocation is approaching its deadline", map[string]interface{}{
+			"lambda":       details,
+			"remaining_ms": time.Until(deadline).Milliseconds(),
+		})
+	})
+	return func() {
+		// Wait for a warning that is being reported so that it is sent before the invocation ends.
+		if !timer.Stop() {
+			<-reported
+		}
+	}
+}
+
+// logPanic reports a panic recovered from a Lambda handler along with the invocation details.
+func (w *lambdaWrapper) logPanic(c *Client, ctx context.Context, err interface{}, details map[string]interface{}) {
+	errValue, ok := err.(error)
+	if !ok {
+		errValue = errors.New(fmt.Sprint(err))
+	}
+	if c.configuration().checkIgnore(errValue.Error()) {
+		return
+	}
+	c.ErrorWithStackSkipWithExtrasAndContext(ctx, CRIT, errValue, 3, map[string]interface{}{
+		"lambda": details,
+	})
+}
@@ -0,0 +1,113 @@
+package rollbar
+
+import (
+	"fmt"
+	"time"
+)
+
+// levelLimiter is implemented by the transports supporting rate limits per level.
+type levelLimiter interface {
+	SetItemsPerMinuteByLevel(limits map[string]int)
+}
+
+// SetItemsPerMinuteByLevel sets the max number of items of the given levels to send in a given
+// minute. Items of these levels are counted separately from the limit set with SetItemsPerMinute,
+// which only applies to the other levels; a limit of 0 sends any number of items of the level.
+func (t *baseTransport) SetItemsPerMinuteByLevel(limits map[string]int) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.levelLimits = limits
+}
+
+func (t *interceptedTransport) SetItemsPerMinuteByLevel(limits map[string]int) {
+	if inner, ok := t.Transport.(levelLimiter); ok {
+		inner.SetItemsPerMinuteByLevel(limits)
+	}
+}
+
+// itemLevel returns the level of an item.
+func itemLevel(body map[string]interface{}) string {
+	data, _ := body["data"].(map[string]interface{})
+	level, _ := data["level"].(string)
+	return level
+}
+
+// withinLevelLimit returns whether an item can be sent according to the limit of its level, and
+// whether its level has a limit at all. It must be called with the lock held.
+func (t *baseTransport) withinLevelLimit(body map[string]interface{}) (allowed, limited bool) {
+	level := itemLevel(body)
+	limit, limited := t.levelLimits[level]
+	if !limited {
+		return true, false
+	}
+	if limit > 0 && t.levelCounters[level] >= limit {
+		rollbarError(t.Logger, fmt.Sprintf("item per minute limit of level %s reached: %d occurences, "+
+			"ignoring errors until timeout", level, t.levelCounters[level]))
+		return false, true
+	}
+	return true, true
+}
+
+// countSent counts an item sent within the current minute, against the limit of its level if it
+// has one, or else the limit set with SetItemsPerMinute.
+func (t *baseTransport) countSent(body map[string]interface{}) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	level := itemLevel(body)
+	if _, limited := t.levelLimits[level]; !limited {
+		t.perMinCounter++
+		return
+	}
+	if t.levelCounters == nil {
+		t.levelCounters = map[string]int{}
+	}
+	t.levelCounters[level]++
+}
+
+// resetCounters starts a new minute of the rate limits once the current one has elapsed at now.
+func (t *baseTransport) resetCounters(now time.Time) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	elapsedTime := now.Sub(t.startTime).Seconds()
+	if elapsedTime < 0 || elapsedTime >= 60 {
+		t.startTime = now
+		t.perMinCounter = 0
+		t.levelCounters = nil
+	}
+}
+
+// SetItemsPerMinuteByLevel sets the max number of items of the given levels sent per minute, e.g.
+// map[string]int{"debug": 10, "error": 100, "critical": 0}, so that a flood of items of low levels
+// cannot starve the items of higher levels under the limit set with SetItemsPerMinute. Items of
+// the levels of limits are counted separately from that limit, which only applies to the other
+// levels, and a limit of 0 sends any number of items of the level. Transports which do not support
+// limits per level are left unchanged and an error is logged.
+func (c *Client) SetItemsPerMinuteByLevel(limits map[string]int) {
+	l, ok := c.Transport.(levelLimiter)
+	if !ok {
+		rollbarError(nil, "transport %T does not support limits per level", c.Transport)
+		return
+	}
+	limits = copyLevelLimits(limits)
+	c.ApplyOptions(func(conf *configuration) { conf.levelLimits = limits })
+	l.SetItemsPerMinuteByLevel(limits)
+}
+
+// copyLevelLimits returns a copy of limits, so that the limits in use are not modified by the
+// caller.
+func copyLevelLimits(limits map[string]int) map[string]int {
+	if limits == nil {
+		return nil
+	}
+	copied := make(map[string]int, len(limits))
+	for level, limit := range limits {
+		copied[level] = limit
+	}
+	return copied
+}
+
+// ItemsPerMinuteByLevel returns the max number of items of the given levels sent per minute, see
+// SetItemsPerMinuteByLevel.
+func (c *Client) ItemsPerMinuteByLevel() map[string]int {
+	return c.configuration().levelLimits
+}
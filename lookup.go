@@ -0,0 +1,106 @@
+package rollbar
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultLookupTimeout bounds the metadata lookups made in the background, such as the requests to
+// cloud metadata services.
+const DefaultLookupTimeout = 2 * time.Second
+
+// A metadataLookup looks up metadata, such as the details of the cloud instance the application
+// runs on, in the background. Building items never waits for a lookup: its result is used once
+// available, and items reported before then, or after it failed, go without it.
+type metadataLookup struct {
+	name    string
+	timeout time.Duration
+	start   time.Time
+	done    chan struct{}
+	value   interface{}
+	err     error
+}
+
+var (
+	lookups     = map[string]*metadataLookup{}
+	lookupsLock sync.Mutex
+
+	// machineHostname is resolved when the package is initialized rather than in the background,
+	// as os.Hostname is a single cheap system call, so that the items reported at startup carry it.
+	machineHostname, _ = os.Hostname()
+)
+
+// startLookup runs f in a new goroutine with a context canceled after timeout, and registers the
+// lookup so that its status is reported in the diagnostic of items. It replaces the lookup of the
+// same name registered before, if any.
+func startLookup(name string, timeout time.Duration, f func(ctx context.Context) (interface{}, error)) *metadataLookup {
+	l := &metadataLookup{name: name, timeout: timeout, start: time.Now(), done: make(chan struct{})}
+	lookupsLock.Lock()
+	lookups[name] = l
+	lookupsLock.Unlock()
+	go func() {
+		defer close(l.done)
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		l.value, l.err = f(ctx)
+	}()
+	return l
+}
+
+// result returns the result of the lookup without waiting for it. The boolean is false while the
+// lookup is running and when it failed.
+func (l *metadataLookup) result() (interface{}, bool) {
+	select {
+	case <-l.done:
+		return l.value, l.err == nil
+	default:
+		return nil, false
+	}
+}
+
+// status describes the state of the lookup: "pending", "timed out", "ok", or the error of a failed
+// lookup.
+func (l *metadataLookup) status() string {
+	select {
+	case <-l.done:
+		if l.err != nil {
+			return l.err.Error()
+		}
+		return "ok"
+	default:
+		if time.Since(l.start) > l.timeout {
+			return "timed out"
+		}
+		return "pending"
+	}
+}
+
+// lookupStatuses returns the status of every lookup by name, or nil if there are none.
+func lookupStatuses() map[string]string {
+	lookupsLock.Lock()
+	defer lookupsLock.Unlock()
+	if len(lookups) == 0 {
+		return nil
+	}
+	statuses := make(map[string]string, len(lookups))
+	for name, l := range lookups {
+		statuses[name] = l.status()
+	}
+	return statuses
+}
+
+// lookupHostname returns the hostname of the machine, or "" if it could not be resolved.
+func lookupHostname() string {
+	return machineHostname
+}
+
+// host returns the server host of items: the one set with SetServerHost, or else the hostname of
+// the machine.
+func (c configuration) host() string {
+	if c.serverHost != "" {
+		return c.serverHost
+	}
+	return lookupHostname()
+}
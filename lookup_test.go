@@ -0,0 +1,82 @@
+package rollbar
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+// waitLookups blocks until all lookups are done.
+func waitLookups() {
+	lookupsLock.Lock()
+	pending := make([]*metadataLookup, 0, len(lookups))
+	for _, l := range lookups {
+		pending = append(pending, l)
+	}
+	lookupsLock.Unlock()
+	for _, l := range pending {
+		<-l.done
+	}
+}
+
+func TestMetadataLookup(t *testing.T) {
+	release := make(chan struct{})
+	slow := startLookup("test-slow", 10*time.Millisecond, func(ctx context.Context) (interface{}, error) {
+		<-release
+		return "value", nil
+	})
+	failed := startLookup("test-failed", time.Second, func(ctx context.Context) (interface{}, error) {
+		return nil, errors.New("metadata service unavailable")
+	})
+	defer func() {
+		lookupsLock.Lock()
+		delete(lookups, "test-slow")
+		delete(lookups, "test-failed")
+		lookupsLock.Unlock()
+	}()
+
+	if _, ok := slow.result(); ok {
+		t.Error("a running lookup should have no result")
+	}
+	time.Sleep(20 * time.Millisecond)
+	<-failed.done
+
+	client := testClient()
+	client.Message(ERR, "boom")
+	data := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	statuses := data["notifier"].(map[string]interface{})["diagnostic"].(map[string]interface{})["lookups"].(map[string]string)
+	if statuses["test-slow"] != "timed out" {
+		t.Error("wrong status of the slow lookup, got:", statuses["test-slow"])
+	}
+	if statuses["test-failed"] != "metadata service unavailable" {
+		t.Error("wrong status of the failed lookup, got:", statuses["test-failed"])
+	}
+
+	close(release)
+	<-slow.done
+	if value, ok := slow.result(); !ok || value != "value" {
+		t.Error("expected the result of the lookup once done, got:", value)
+	}
+	if status := slow.status(); status != "ok" {
+		t.Error("wrong status, got:", status)
+	}
+}
+
+func TestLookupHostname(t *testing.T) {
+	expected, err := os.Hostname()
+	if err != nil {
+		t.Skip("no hostname:", err)
+	}
+	if hostname := lookupHostname(); hostname != expected {
+		t.Errorf("expected the hostname to be known from the start, got: %q", hostname)
+	}
+
+	client := testClient()
+	client.Message(ERR, "boom")
+	data := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	if host := data["server"].(map[string]interface{})["host"]; host != expected {
+		t.Errorf("expected the hostname as server.host, got: %v", host)
+	}
+}
@@ -0,0 +1,47 @@
+package rollbar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// errMaxItems is returned by push for the items dropped because of the limit set with SetMaxItems.
+var errMaxItems = errors.New("rollbar: max items reached")
+
+// SetMaxItems sets the maximum number of items reported by the client over the lifetime of the
+// process, which protects the quota of the project from crash loops and runaway errors. Once the
+// limit is reached, a final warning stating so is sent and further items are dropped, emitting
+// ItemDropped events with the reason DropReasonMaxItems. Setting the limit, even to the same
+// value, resets the count of the reported items. The default value is 0, which reports any number
+// of items.
+func (c *Client) SetMaxItems(maxItems int) {
+	c.ApplyOptions(func(conf *configuration) { conf.maxItems = maxItems })
+	atomic.StoreInt64(c.diagnostic.items, 0)
+}
+
+// MaxItems returns the maximum number of items reported over the lifetime of the process, see
+// SetMaxItems.
+func (c *Client) MaxItems() int {
+	return c.configuration().maxItems
+}
+
+// countItem counts an item about to be sent against the limit set with SetMaxItems. It returns
+// false if the item must be dropped, and whether it is the last item before the limit.
+func (c *Client) countItem(maxItems int) (send, last bool) {
+	if maxItems <= 0 {
+		return true, false
+	}
+	n := atomic.AddInt64(c.diagnostic.items, 1)
+	return n <= int64(maxItems), n == int64(maxItems)
+}
+
+// sendMaxItemsReached sends the warning stating that the limit set with SetMaxItems was reached,
+// scrubbed and transformed like the other items.
+func (c *Client) sendMaxItemsReached(conf *configuration) {
+	msg := fmt.Sprintf("maxItems has been hit (%d items), ignoring errors until reset", conf.maxItems)
+	body := c.buildBody(context.Background(), WARN, msg, nil)
+	body["data"].(map[string]interface{})["body"] = messageBody(msg)
+	c.send(conf, body)
+}
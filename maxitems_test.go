@@ -0,0 +1,54 @@
+package rollbar
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetMaxItems(t *testing.T) {
+	client := testClient()
+	client.SetMaxItems(2)
+	events := client.Events()
+
+	client.Message(ERR, "first")
+	client.Message(ERR, "second")
+	data := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	if data["level"] != WARN || !strings.HasPrefix(data["title"].(string), "maxItems has been hit") {
+		t.Error("expected the final warning, got:", data["level"], data["title"])
+	}
+
+	client.Message(ERR, "third")
+	if title := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})["title"]; title == "third" {
+		t.Error("expected the item to be dropped")
+	}
+	var dropped int
+	for len(events) > 0 {
+		if e, ok := (<-events).(ItemDropped); ok && e.Reason == DropReasonMaxItems {
+			dropped++
+		}
+	}
+	if dropped != 1 {
+		t.Error("expected one dropped item, got:", dropped)
+	}
+
+	client.SetMaxItems(2)
+	client.Message(ERR, "after reset")
+	if title := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})["title"]; title != "after reset" {
+		t.Error("expected the item to be reported after a reset, got:", title)
+	}
+}
+
+func TestMaxItemsReachedScrubbed(t *testing.T) {
+	client := testClient()
+	client.SetCustom(map[string]interface{}{"password": "hunter2"})
+	client.SetMaxItems(1)
+
+	client.Message(ERR, "first")
+	data := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	if !strings.HasPrefix(data["title"].(string), "maxItems has been hit") {
+		t.Fatal("expected the final warning, got:", data["title"])
+	}
+	if password := data["custom"].(map[string]interface{})["password"]; password != FILTERED {
+		t.Error("expected the custom data of the warning to be scrubbed, got:", password)
+	}
+}
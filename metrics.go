@@ -0,0 +1,228 @@
+package rollbar
+
+import (
+	"sync"
+	"time"
+)
+
+// processStart is the time the process started, or rather this package was initialized, from
+// which the uptime reported in the diagnostic of items is computed.
+var processStart = time.Now()
+
+// TransportStats are the counters and gauges describing the activity of a transport since it was
+// created, as returned by Client.TransportMetrics. They are meant to be exported to a monitoring
+// system, e.g. with Prometheus collectors reading them when scraped, so that alerts can be raised
+// when items are silently dropped:
+//
+//	prometheus.NewCounterFunc(prometheus.CounterOpts{Name: "rollbar_items_dropped_total"}, func() float64 {
+//		m := rollbar.TransportMetrics()
+//		return float64(m.Dropped + m.Failed)
+//	})
+type TransportStats struct {
+	// Sent is the number of items accepted by the API.
+	Sent uint64
+	// Retried is the number of times sending an item was retried after a temporary error.
+	Retried uint64
+	// Failed is the number of items which could not be sent, after all retries.
+	Failed uint64
+	// Dropped is the number of items discarded without being sent, because the queue was full, the
+	// items per minute limit was reached or the transport was stopped.
+	Dropped uint64
+	// QueueDepth is the number of items waiting to be sent by the asynchronous transport.
+	QueueDepth int
+	// LastSendLatency is the duration of the last request to the API.
+	LastSendLatency time.Duration
+	// LastError is the error of the last item which could not be sent, if any.
+	LastError string
+}
+
+// A MetricsRecorder observes the behavior of a Client and its transport, see SetMetricsRecorder.
+// It is satisfied by thin adapters over statsd, expvar or OpenTelemetry instruments, so that the
+// rollbar package does not depend on any metrics system. Its methods may be called concurrently.
+type MetricsRecorder interface {
+	// Inc increments the counter with the given name.
+	Inc(name string)
+	// Observe records a value of the distribution with the given name.
+	Observe(name string, v float64)
+}
+
+// The names of the metrics passed to a MetricsRecorder.
+const (
+	// MetricItemsReported counts the items reported to the Client and handed to its transport.
+	MetricItemsReported = "rollbar.items.reported"
+	// MetricItemsEscalated counts the items whose level was raised by the escalation policy.
+	MetricItemsEscalated = "rollbar.items.escalated"
+	// MetricItemsSent counts the items accepted by the API.
+	MetricItemsSent = "rollbar.transport.sent"
+	// MetricItemsRetried counts the retries of items after a temporary error.
+	MetricItemsRetried = "rollbar.transport.retried"
+	// MetricItemsFailed counts the items which could not be sent, after all retries.
+	MetricItemsFailed = "rollbar.transport.failed"
+	// MetricItemsDropped counts the items discarded without being sent.
+	MetricItemsDropped = "rollbar.transport.dropped"
+	// MetricSendLatency observes the duration in seconds of the requests to the API.
+	MetricSendLatency = "rollbar.transport.send_latency_seconds"
+	// MetricQueueDepth observes the number of queued items each time the asynchronous transport
+	// queues an item.
+	MetricQueueDepth = "rollbar.transport.queue_depth"
+)
+
+// transportMetrics collects the metrics of a transport and passes them on to its recorder, along
+// with the events of the transport to the stream of its client. It has its own lock so that
+// metrics can be recorded while the lock of the transport is held.
+type transportMetrics struct {
+	metrics  TransportStats
+	recorder MetricsRecorder
+	events   *eventStream
+	lock     sync.Mutex
+}
+
+// update applies f to the metrics and returns the recorder, if any, which is called without
+// holding the lock.
+func (m *transportMetrics) update(f func(metrics *TransportStats)) MetricsRecorder {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	f(&m.metrics)
+	return m.recorder
+}
+
+func (m *transportMetrics) inc(name string, f func(metrics *TransportStats)) {
+	if recorder := m.update(f); recorder != nil {
+		recorder.Inc(name)
+	}
+}
+
+func (m *transportMetrics) sent(latency time.Duration) {
+	m.inc(MetricItemsSent, func(metrics *TransportStats) {
+		metrics.Sent++
+		metrics.LastSendLatency = latency
+	})
+}
+
+func (m *transportMetrics) retried() {
+	m.inc(MetricItemsRetried, func(metrics *TransportStats) { metrics.Retried++ })
+}
+
+func (m *transportMetrics) failed() {
+	m.inc(MetricItemsFailed, func(metrics *TransportStats) { metrics.Failed++ })
+}
+
+func (m *transportMetrics) dropped() {
+	m.inc(MetricItemsDropped, func(metrics *TransportStats) { metrics.Dropped++ })
+}
+
+func (m *transportMetrics) sendError(err error) {
+	m.update(func(metrics *TransportStats) { metrics.LastError = err.Error() })
+}
+
+func (m *transportMetrics) latency(latency time.Duration) {
+	recorder := m.update(func(metrics *TransportStats) { metrics.LastSendLatency = latency })
+	if recorder != nil {
+		recorder.Observe(MetricSendLatency, latency.Seconds())
+	}
+}
+
+func (m *transportMetrics) queued(depth int) {
+	if recorder := m.update(func(*TransportStats) {}); recorder != nil {
+		recorder.Observe(MetricQueueDepth, float64(depth))
+	}
+}
+
+func (t *baseTransport) setMetricsRecorder(recorder MetricsRecorder) {
+	t.metrics.lock.Lock()
+	defer t.metrics.lock.Unlock()
+	t.metrics.recorder = recorder
+}
+
+// Metrics returns the metrics of the transport.
+func (t *baseTransport) Metrics() TransportStats {
+	t.metrics.lock.Lock()
+	defer t.metrics.lock.Unlock()
+	return t.metrics.metrics
+}
+
+// Metrics returns the metrics of the transport, including the number of queued items.
+func (t *AsyncTransport) Metrics() TransportStats {
+	metrics := t.baseTransport.Metrics()
+	metrics.QueueDepth = len(t.bodyChannel)
+	return metrics
+}
+
+// QueueLen returns the number of items waiting in the queue of the transport.
+func (t *AsyncTransport) QueueLen() int {
+	return len(t.bodyChannel)
+}
+
+// QueueCap returns the capacity of the queue of the transport.
+func (t *AsyncTransport) QueueCap() int {
+	return cap(t.bodyChannel)
+}
+
+// DroppedCount returns the number of items dropped by the transport, see TransportStats.Dropped.
+func (t *AsyncTransport) DroppedCount() uint64 {
+	return t.Metrics().Dropped
+}
+
+// RetriedCount returns the number of times the transport retried to send an item, see
+// TransportStats.Retried.
+func (t *AsyncTransport) RetriedCount() uint64 {
+	return t.Metrics().Retried
+}
+
+func (t *interceptedTransport) Metrics() TransportStats {
+	if inner, ok := t.Transport.(metricsReporter); ok {
+		return inner.Metrics()
+	}
+	return TransportStats{}
+}
+
+func (t *interceptedTransport) setMetricsRecorder(recorder MetricsRecorder) {
+	if inner, ok := t.Transport.(interface{ setMetricsRecorder(MetricsRecorder) }); ok {
+		inner.setMetricsRecorder(recorder)
+	}
+}
+
+// metricsReporter is implemented by the transports reporting their metrics.
+type metricsReporter interface {
+	Metrics() TransportStats
+}
+
+// TransportMetrics returns the metrics of the transport of the client. They are all zero for
+// transports which do not report metrics.
+func (c *Client) TransportMetrics() TransportStats {
+	if t, ok := c.Transport.(metricsReporter); ok {
+		return t.Metrics()
+	}
+	return TransportStats{}
+}
+
+// SetMetricsRecorder sets the recorder observing the items reported by the client and the activity
+// of its transport, see the Metric constants for the names of the metrics. A nil recorder, the
+// default, disables recording. Transports which are not implemented by this package only report
+// MetricItemsReported and MetricItemsEscalated.
+func (c *Client) SetMetricsRecorder(recorder MetricsRecorder) {
+	c.ApplyOptions(func(conf *configuration) { conf.metrics = recorder })
+	if t, ok := c.Transport.(interface{ setMetricsRecorder(MetricsRecorder) }); ok {
+		t.setMetricsRecorder(recorder)
+	}
+}
+
+// addUsageDiagnostic adds the counters of the transport and the uptime of the process to the
+// notifier diagnostic of an item as usage, so that the misbehavior of the SDK, such as dropped
+// items, can be diagnosed from the items themselves.
+func addUsageDiagnostic(body map[string]interface{}, stats TransportStats) {
+	usage := map[string]interface{}{
+		"items_sent":     stats.Sent,
+		"items_retried":  stats.Retried,
+		"items_failed":   stats.Failed,
+		"items_dropped":  stats.Dropped,
+		"queue_depth":    stats.QueueDepth,
+		"uptime_seconds": int64(time.Since(processStart).Seconds()),
+	}
+	if stats.LastError != "" {
+		usage["last_send_error"] = stats.LastError
+	}
+	data := body["data"].(map[string]interface{})
+	notifier := data["notifier"].(map[string]interface{})
+	notifier["diagnostic"].(map[string]interface{})["usage"] = usage
+}
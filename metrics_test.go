@@ -0,0 +1,123 @@
+package rollbar
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testMetricsRecorder struct {
+	counters     map[string]int
+	observations map[string][]float64
+	lock         sync.Mutex
+}
+
+func (r *testMetricsRecorder) Inc(name string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.counters[name]++
+}
+
+func (r *testMetricsRecorder) Observe(name string, v float64) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.observations[name] = append(r.observations[name], v)
+}
+
+func TestSetMetricsRecorder(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	recorder := &testMetricsRecorder{counters: map[string]int{}, observations: map[string][]float64{}}
+	client := NewSync("token", "test", "", "", "")
+	client.SetEndpoint(ts.URL)
+	client.SetMetricsRecorder(recorder)
+	client.SetEscalationPolicy(2, time.Minute)
+
+	client.Message(WARN, "disk almost full")
+	client.Message(WARN, "disk almost full")
+
+	if recorder.counters[MetricItemsReported] != 2 || recorder.counters[MetricItemsSent] != 2 {
+		t.Error("wrong counters, got:", recorder.counters)
+	}
+	if recorder.counters[MetricItemsEscalated] != 1 {
+		t.Error("expected the second item to be escalated, got:", recorder.counters)
+	}
+	if len(recorder.observations[MetricSendLatency]) != 2 {
+		t.Error("expected the latency of each send to be observed, got:", recorder.observations)
+	}
+	if !client.Config().MetricsRecorder {
+		t.Error("expected the recorder to be reported in the configuration")
+	}
+}
+
+func TestAsyncTransportMetricsRecorder(t *testing.T) {
+	recorder := &testMetricsRecorder{counters: map[string]int{}, observations: map[string][]float64{}}
+	transport := NewAsyncTransport("", "", 0)
+	transport.SetLogger(&SilentClientLogger{})
+	transport.setMetricsRecorder(recorder)
+
+	transport.Send(map[string]interface{}{"hello": "world"})
+
+	if recorder.counters[MetricItemsDropped] != 1 {
+		t.Error("expected the item to be dropped, got:", recorder.counters)
+	}
+}
+
+func TestUsageDiagnostic(t *testing.T) {
+	var bodies []map[string]interface{}
+	status := http.StatusInternalServerError
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+		w.WriteHeader(status)
+	}))
+	defer ts.Close()
+
+	client := NewSync("token", "test", "", "", "")
+	client.SetEndpoint(ts.URL)
+	client.Transport.SetLogger(&SilentClientLogger{})
+	client.Transport.SetPrintPayloadOnError(false)
+
+	client.Message(ERR, "first")
+	status = http.StatusOK
+	client.Message(ERR, "second")
+
+	data := bodies[1]["data"].(map[string]interface{})
+	usage := data["notifier"].(map[string]interface{})["diagnostic"].(map[string]interface{})["usage"].(map[string]interface{})
+	if usage["items_failed"] != float64(1) || usage["items_sent"] != float64(0) {
+		t.Error("wrong counters, got:", usage)
+	}
+	if !strings.Contains(usage["last_send_error"].(string), "500") {
+		t.Error("expected the last send error, got:", usage["last_send_error"])
+	}
+	if _, ok := usage["uptime_seconds"]; !ok {
+		t.Error("expected the uptime")
+	}
+}
+
+func TestAsyncTransportQueueGetters(t *testing.T) {
+	transport, _, release, stop := blockedAsyncTransport(2, DropNewestPolicy, 0)
+	defer stop()
+	for _, message := range []string{"queued", "queued too", "dropped"} {
+		transport.Send(messageItem(message))
+	}
+	if transport.QueueLen() != 2 || transport.QueueCap() != 2 {
+		t.Errorf("expected a full queue of 2 items, got: %d/%d", transport.QueueLen(), transport.QueueCap())
+	}
+	if transport.DroppedCount() != 1 || transport.RetriedCount() != 0 {
+		t.Errorf("expected 1 item dropped and none retried, got: %d, %d", transport.DroppedCount(), transport.RetriedCount())
+	}
+	close(release)
+	transport.Wait()
+	if transport.QueueLen() != 0 {
+		t.Error("expected an empty queue, got:", transport.QueueLen())
+	}
+}
@@ -0,0 +1,395 @@
+package rollbar
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// A Route describes the route template matched by a router for a request, e.g. "/users/{id}",
+// along with the values of its parameters.
+type Route struct {
+	Pattern string
+	Params  map[string]string
+	// Operation optionally names the logical operation served by the route, such as the gRPC
+	// method behind a grpc-gateway route. Items are then grouped by operation rather than by route.
+	Operation string
+}
+
+// Name returns the name of the route reported as data.context for a request with the given
+// method: the method and pattern, preceded by the operation if any, e.g.
+// "/pkg.Users/GetUser (GET /v1/users/{id})".
+func (route *Route) Name(method string) string {
+	name := method + " " + route.Pattern
+	if route.Operation != "" {
+		name = route.Operation + " (" + name + ")"
+	}
+	return name
+}
+
+// A RouteResolverFunc returns the Route matched for a request, or nil if there is none. Routers
+// such as chi and gorilla/mux store the matched route in the request, see the resolvers provided
+// in the contrib packages of this module.
+type RouteResolverFunc func(*http.Request) *Route
+
+// NewRouteContext returns a new Context that carries the matched route. Items reported with a
+// request carrying this context include the route pattern and parameters in data.request.
+func NewRouteContext(ctx context.Context, route *Route) context.Context {
+	return context.WithValue(ctx, routeKey, route)
+}
+
+// RouteFromContext returns the Route value stored in ctx, if any.
+func RouteFromContext(ctx context.Context) (*Route, bool) {
+	route, ok := ctx.Value(routeKey).(*Route)
+	return route, ok
+}
+
+type middleware struct {
+	client     *Client
+	resolver   RouteResolverFunc
+	panicLevel string
+	repanic    bool
+
+	abandonThreshold time.Duration
+	abandonLevel     string
+	statusLevels     StatusLevels
+	telemetry        bool
+
+	claims       ClaimsFunc
+	personClaims PersonClaims
+}
+
+// A MiddlewareOption configures the HTTP middleware returned by Middleware.
+type MiddlewareOption func(*middleware)
+
+// WithMiddlewareClient sets the Client used by the middleware to report items. By default the
+// managed Client instance is used.
+func WithMiddlewareClient(client *Client) MiddlewareOption {
+	return func(m *middleware) {
+		m.client = client
+	}
+}
+
+// WithRouteResolver sets the function used by the middleware to find the route matched for a
+// request. The route pattern is reported as data.context, prefixed by the request method, so
+// that occurrences are grouped per route instead of per concrete URL.
+func WithRouteResolver(resolver RouteResolverFunc) MiddlewareOption {
+	return func(m *middleware) {
+		m.resolver = resolver
+	}
+}
+
+// WithMiddlewarePanicLevel sets the level at which recovered panics are reported. The default is
+// CRIT.
+func WithMiddlewarePanicLevel(level string) MiddlewareOption {
+	return func(m *middleware) {
+		m.panicLevel = level
+	}
+}
+
+// WithMiddlewareRepanic sets whether a recovered panic is panicked again after it has been
+// reported, leaving it to the http.Server. By default the middleware responds with a 500 status
+// instead.
+func WithMiddlewareRepanic(repanic bool) MiddlewareOption {
+	return func(m *middleware) {
+		m.repanic = repanic
+	}
+}
+
+// WithMiddlewareAbandonment makes the middleware report, at the given level, the requests whose
+// context is canceled while the handler is still running after at least threshold, which happens
+// when the client gives up waiting, e.g. because it timed out. The item carries the stack of the
+// handler at the time the request was abandoned, which shows where slow requests spend their time
+// even when they never fail. Reporting requires the stacks of all goroutines to be captured, so
+// threshold should be well above the usual latency of the handler.
+func WithMiddlewareAbandonment(threshold time.Duration, level string) MiddlewareOption {
+	return func(m *middleware) {
+		m.abandonThreshold = threshold
+		m.abandonLevel = level
+	}
+}
+
+// WithMiddlewareServerErrors makes the middleware report, at the given level, the responses of the
+// next handler with a 5xx status code, even when the handler does not panic. The items carry the
+// request, the status code and the duration of the request. By default only panics are reported.
+// It is a shorthand for WithMiddlewareStatusLevels(StatusLevels{"5xx": level}).
+func WithMiddlewareServerErrors(level string) MiddlewareOption {
+	return WithMiddlewareStatusLevels(StatusLevels{"5xx": level})
+}
+
+// WithMiddlewareStatusLevels makes the middleware report the responses of the next handler at the
+// level mapped to their status code by levels, even when the handler does not panic, e.g.
+// DefaultStatusLevels to report server errors as errors and throttled requests as warnings. The
+// items carry the request, the status code and the duration of the request. By default only panics
+// are reported.
+func WithMiddlewareStatusLevels(levels StatusLevels) MiddlewareOption {
+	return func(m *middleware) {
+		m.statusLevels = levels
+	}
+}
+
+// WithMiddlewareRequestTelemetry makes the middleware give each request a telemetry queue of its
+// own, see NewTelemetryContext, so that the items reported within the context of a request only
+// carry the telemetry events captured within it. By default the items carry the telemetry events of
+// the client, whatever the request they were captured for.
+func WithMiddlewareRequestTelemetry() MiddlewareOption {
+	return func(m *middleware) {
+		m.telemetry = true
+	}
+}
+
+// Middleware returns a net/http middleware which recovers and reports the panics of the next
+// handler along with the request, the status code of the response, if written, as
+// custom.response_status and the duration of the request as custom.duration_ms. It can be used
+// with any router accepting func(http.Handler) http.Handler middlewares, such as chi and
+// gorilla/mux. Panics with the value http.ErrAbortHandler are not reported.
+//
+// The context of the request passed to the next handler carries the request, see
+// NewRequestContext, so that items reported with it by the handler include the request and the
+// person without passing them explicitly.
+func Middleware(opts ...MiddlewareOption) func(http.Handler) http.Handler {
+	m := &middleware{
+		panicLevel: CRIT,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := m.personContext(r)
+			if m.telemetry {
+				ctx = NewTelemetryContext(ctx)
+			}
+			r = r.WithContext(NewRequestContext(ctx, r))
+			rw := &responseRecorder{ResponseWriter: w, start: time.Now()}
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					return
+				}
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+				err, ok := rec.(error)
+				if !ok {
+					err = errors.New(fmt.Sprint(rec))
+				}
+				m.report(m.panicLevel, m.request(r), err, 2, rw.details())
+				if m.repanic {
+					panic(rec)
+				}
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}()
+			if m.abandonThreshold > 0 {
+				defer m.watchAbandonment(r)()
+			}
+
+			next.ServeHTTP(rw, r)
+			if level := m.statusLevels.Level(rw.status); level != "" && rw.status >= 400 {
+				m.reportStatus(level, m.request(r), rw)
+			}
+		})
+	}
+}
+
+// report reports err with the stack trace starting skip frames above the caller of report. Panics
+// are reported with a skip of 2 to omit the deferred function and runtime.gopanic.
+func (m *middleware) report(level string, r *http.Request, err error, skip int, extras map[string]interface{}) {
+	client := m.client
+	if client == nil {
+		client = std
+	}
+	if client.configuration().checkIgnore(err.Error()) {
+		return
+	}
+	client.RequestErrorWithStackSkipWithExtrasAndContext(r.Context(), level, r, err, skip+3, extras)
+}
+
+// ReportHandlerError reports err, recovered from a panic or returned by a handler, on behalf of the
+// middleware of frameworks which cannot use Middleware, such as those of the contrib packages.
+// Items are reported with client, or with the managed Client instance if client is nil, and
+// describe r unless it is nil. The stack trace starts skip frames above the caller of
+// ReportHandlerError: panics are reported from the deferred function recovering them with a skip
+// of 2 to omit that function and runtime.gopanic.
+func ReportHandlerError(ctx context.Context, client *Client, level string, r *http.Request, err error, skip int, extras map[string]interface{}) {
+	if client == nil {
+		client = std
+	}
+	if r != nil {
+		client.RequestErrorWithStackSkipWithExtrasAndContext(ctx, level, r, err, skip+3, extras)
+		return
+	}
+	client.ErrorWithStackSkipWithExtrasAndContext(ctx, level, err, skip+3, extras)
+}
+
+// reportStatus reports the error response recorded by rw as a message, as the stack of the
+// middleware would not tell anything about the error.
+func (m *middleware) reportStatus(level string, r *http.Request, rw *responseRecorder) {
+	client := m.client
+	if client == nil {
+		client = std
+	}
+	msg := statusMessage(rw.status)
+	if client.configuration().checkIgnore(msg) {
+		return
+	}
+	client.RequestMessageWithExtrasAndContext(r.Context(), level, r, msg, rw.details())
+}
+
+// responseRecorder records the status code of the response written by a handler. It always
+// implements http.Flusher and http.Hijacker: Flush does nothing and Hijack returns an error when
+// the wrapped ResponseWriter does not support them. It can be unwrapped by http.ResponseController.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	start  time.Time
+}
+
+func (rw *responseRecorder) WriteHeader(status int) {
+	if rw.status == 0 {
+		rw.status = status
+	}
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *responseRecorder) Write(b []byte) (int, error) {
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+	return rw.ResponseWriter.Write(b)
+}
+
+func (rw *responseRecorder) Flush() {
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		if rw.status == 0 {
+			rw.status = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+func (rw *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := rw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("rollbar: the ResponseWriter does not implement http.Hijacker")
+	}
+	return h.Hijack()
+}
+
+func (rw *responseRecorder) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// details returns the status code of the response, if written, and the duration of the request so
+// far, as reported in the custom data of items.
+func (rw *responseRecorder) details() map[string]interface{} {
+	details := map[string]interface{}{
+		"duration_ms": time.Since(rw.start).Milliseconds(),
+	}
+	if rw.status != 0 {
+		details["response_status"] = rw.status
+	}
+	return details
+}
+
+// watchAbandonment reports the request if its context is canceled after the abandonment threshold
+// while the calling goroutine is still serving it. The returned function must be called when the
+// handler returns.
+func (m *middleware) watchAbandonment(r *http.Request) func() {
+	start := time.Now()
+	id := goroutineID()
+	reported := make(chan struct{})
+	stop := afterFunc(r.Context(), func() {
+		defer close(reported)
+		elapsed := time.Since(start)
+		if r.Context().Err() != context.Canceled || elapsed < m.abandonThreshold {
+			return
+		}
+		message := fmt.Sprintf("request abandoned by the client after %s", elapsed.Round(time.Millisecond))
+		err := NewStackError(message, goroutineStack(id))
+		client := m.client
+		if client == nil {
+			client = std
+		}
+		client.RequestErrorWithStackSkipWithExtrasAndContext(r.Context(), m.abandonLevel, m.request(r), err, 0, map[string]interface{}{
+			"abandoned_after_ms": elapsed.Milliseconds(),
+		})
+	})
+	return func() {
+		// Wait for a report in progress so that it carries the stack of the handler.
+		if !stop() {
+			<-reported
+		}
+	}
+}
+
+// goroutineID returns the ID of the calling goroutine as printed in its stack trace.
+func goroutineID() string {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	// The first line is e.g. "goroutine 18 [running]:".
+	fields := strings.Fields(string(buf))
+	if len(fields) < 2 {
+		return ""
+	}
+	return fields[1]
+}
+
+// goroutineStack returns the stack trace of the goroutine with the given ID in the format of
+// debug.Stack, or nil if the goroutine does not exist anymore.
+func goroutineStack(id string) []byte {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) || len(buf) >= 16<<20 {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	prefix := []byte("goroutine " + id + " [")
+	for _, trace := range bytes.Split(buf, []byte("\n\n")) {
+		if bytes.HasPrefix(trace, prefix) {
+			return trace
+		}
+	}
+	return nil
+}
+
+// request returns r with a context carrying the route resolved for it, if any. Routes are resolved
+// when reporting rather than before calling the next handler, as routers such as chi only match
+// them once the request reaches them.
+func (m *middleware) request(r *http.Request) *http.Request {
+	if m.resolver == nil {
+		return r
+	}
+	route := m.resolver(r)
+	if route == nil || route.Pattern == "" {
+		return r
+	}
+	ctx := NewRouteContext(r.Context(), route)
+	return r.WithContext(NewContextNameContext(ctx, route.Name(r.Method)))
+}
+
+// personContext returns the context of r carrying the person identified by its claims, if any.
+func (m *middleware) personContext(r *http.Request) context.Context {
+	ctx := r.Context()
+	if m.claims == nil {
+		return ctx
+	}
+	if _, ok := PersonFromContext(ctx); ok {
+		return ctx
+	}
+	if person := PersonFromClaims(m.claims(r), m.personClaims); person != nil {
+		ctx = NewPersonContext(ctx, person)
+	}
+	return ctx
+}
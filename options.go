@@ -0,0 +1,259 @@
+package rollbar
+
+import (
+	"regexp"
+	"time"
+)
+
+// Option changes a setting of the configuration applied by ApplyOptions.
+type Option func(*configuration)
+
+// ApplyOptions applies the options to a copy of the configuration of the client and replaces the
+// configuration with it in a single step, so that items reported concurrently use either the
+// previous settings or all of the new ones, never a mix of both. This allows reloading a
+// configuration file or settings fetched at runtime while the client is in use. The individual
+// setters replace the configuration the same way, one setting at a time, so that they are never
+// lost when they run concurrently with ApplyOptions.
+//
+// The settings of the transport, the token, the endpoint and the rate limit, are updated after
+// the configuration has been replaced.
+func (c *Client) ApplyOptions(opts ...Option) {
+	c.reconfig.Lock()
+	defer c.reconfig.Unlock()
+
+	previous := c.configuration()
+	conf := *previous
+	for _, opt := range opts {
+		opt(&conf)
+	}
+	c.config.Store(&conf)
+
+	if conf.token != previous.token {
+		c.Transport.SetToken(conf.token)
+	}
+	if conf.endpoint != previous.endpoint {
+		c.Transport.SetEndpoint(conf.endpoint)
+	}
+	if conf.itemsPerMinute != previous.itemsPerMinute {
+		c.Transport.SetItemsPerMinute(conf.itemsPerMinute)
+	}
+	if c.Telemetry == nil {
+		return
+	}
+	scrubHeaders, _ := c.Telemetry.headerFilters()
+	if conf.scrubHeaders != previous.scrubHeaders {
+		scrubHeaders = conf.scrubHeaders
+	}
+	c.Telemetry.setHeaderFilters(scrubHeaders, conf.allowHeaders)
+}
+
+// WithToken sets the access token, see SetToken.
+func WithToken(token string) Option {
+	return func(conf *configuration) {
+		conf.token = token
+	}
+}
+
+// WithEnvironment sets the environment, see SetEnvironment.
+func WithEnvironment(environment string) Option {
+	return func(conf *configuration) {
+		conf.environment = environment
+	}
+}
+
+// WithCodeVersion sets the code version, see SetCodeVersion.
+func WithCodeVersion(codeVersion string) Option {
+	return func(conf *configuration) {
+		conf.codeVersion = codeVersion
+	}
+}
+
+// WithServerHost sets the server hostname, see SetServerHost.
+func WithServerHost(serverHost string) Option {
+	return func(conf *configuration) {
+		conf.serverHost = serverHost
+	}
+}
+
+// WithServerRoot sets the path to the application code root, see SetServerRoot.
+func WithServerRoot(serverRoot string) Option {
+	return func(conf *configuration) {
+		conf.serverRoot = serverRoot
+	}
+}
+
+// WithEndpoint sets the endpoint items are posted to, see SetEndpoint.
+func WithEndpoint(endpoint string) Option {
+	return func(conf *configuration) {
+		conf.endpoint = endpoint
+	}
+}
+
+// WithPlatform sets the platform, see SetPlatform.
+func WithPlatform(platform string) Option {
+	return func(conf *configuration) {
+		conf.platform = platform
+	}
+}
+
+// WithEnabled sets whether reporting is enabled, see SetEnabled.
+func WithEnabled(enabled bool) Option {
+	return func(conf *configuration) {
+		conf.enabled = enabled
+	}
+}
+
+// WithEnabledEnvironments sets the environments in which items are reported, see
+// SetEnabledEnvironments.
+func WithEnabledEnvironments(environments []string) Option {
+	return func(conf *configuration) {
+		conf.environments = environments
+	}
+}
+
+// WithContextName sets the name of the operation reported as data.context, see SetContextName.
+func WithContextName(name string) Option {
+	return func(conf *configuration) {
+		conf.contextName = name
+	}
+}
+
+// WithCustom sets the custom data reported with all items, see SetCustom.
+func WithCustom(custom map[string]interface{}) Option {
+	return func(conf *configuration) {
+		conf.custom = custom
+	}
+}
+
+// WithFingerprint sets whether custom fingerprints are computed, see SetFingerprint.
+func WithFingerprint(fingerprint bool) Option {
+	return func(conf *configuration) {
+		conf.fingerprint = fingerprint
+	}
+}
+
+// WithScrubFields sets the regular expression matching the scrubbed keys, see SetScrubFields.
+func WithScrubFields(fields *regexp.Regexp) Option {
+	return func(conf *configuration) {
+		conf.scrubFields = fields
+	}
+}
+
+// WithScrubHeaders sets the regular expression matching the scrubbed headers, see
+// SetScrubHeaders.
+func WithScrubHeaders(headers *regexp.Regexp) Option {
+	return func(conf *configuration) {
+		conf.scrubHeaders = headers
+	}
+}
+
+// WithAllowedHeaders sets the only headers reported, see SetAllowedHeaders.
+func WithAllowedHeaders(headers ...string) Option {
+	return func(conf *configuration) {
+		conf.allowHeaders = headers
+	}
+}
+
+// WithScrubCookies sets the regular expression matching the scrubbed cookies, see
+// SetScrubCookies.
+func WithScrubCookies(cookies *regexp.Regexp) Option {
+	return func(conf *configuration) {
+		conf.scrubCookies = cookies
+	}
+}
+
+// WithPersonScrubPolicy sets how the email and username of the person are reported, see
+// SetPersonScrubPolicy.
+func WithPersonScrubPolicy(policy PersonScrubPolicy) Option {
+	return func(conf *configuration) {
+		conf.personScrub = policy
+	}
+}
+
+// WithScrubPaths sets the paths of the scrubbed fields, see SetScrubPaths.
+func WithScrubPaths(paths ...string) Option {
+	return func(conf *configuration) {
+		conf.scrubPaths = paths
+	}
+}
+
+// WithScrubExemptFields sets the keys exempted from scrubbing, see SetScrubExemptFields.
+func WithScrubExemptFields(keys ...string) Option {
+	return func(conf *configuration) {
+		conf.scrubExempt = keys
+	}
+}
+
+// WithScrubPII sets whether PII is masked wherever it is found in the items, see SetScrubPII.
+func WithScrubPII(scrubPII bool) Option {
+	return func(conf *configuration) {
+		conf.scrubPII = scrubPII
+	}
+}
+
+// WithScrubSecrets sets whether secrets embedded in free text are masked, see SetScrubSecrets.
+func WithScrubSecrets(scrubSecrets bool) Option {
+	return func(conf *configuration) {
+		conf.scrubSecrets = scrubSecrets
+	}
+}
+
+// WithItemsPerMinute sets the maximum number of items sent per minute, see SetItemsPerMinute.
+func WithItemsPerMinute(itemsPerMinute int) Option {
+	return func(conf *configuration) {
+		conf.itemsPerMinute = itemsPerMinute
+	}
+}
+
+// WithEscalationPolicy sets the policy making repeated items more severe, see
+// SetEscalationPolicy. The occurrences counted so far are discarded.
+func WithEscalationPolicy(threshold int, window time.Duration) Option {
+	return func(conf *configuration) {
+		if threshold <= 0 {
+			conf.escalation = 0
+			conf.occurrences = nil
+			return
+		}
+		conf.escalation = threshold
+		conf.occurrences = newOccurrenceCache(window)
+	}
+}
+
+// WithAttachTelemetry sets whether telemetry events are attached to items, see
+// SetAttachTelemetry.
+func WithAttachTelemetry(attach bool) Option {
+	return func(conf *configuration) {
+		conf.telemetry = attach
+	}
+}
+
+// WithTelemetryMaxEvents sets the maximum number of telemetry events attached to an item, see
+// SetTelemetryMaxEvents.
+func WithTelemetryMaxEvents(max int) Option {
+	return func(conf *configuration) {
+		conf.telemetryMax = max
+	}
+}
+
+// WithTelemetryLevel sets the minimum level of the telemetry events attached to items, see
+// SetTelemetryLevel.
+func WithTelemetryLevel(level string) Option {
+	return func(conf *configuration) {
+		conf.telemetryLevel = level
+	}
+}
+
+// WithCheckIgnore sets the function deciding which errors and messages are ignored, see
+// SetCheckIgnore.
+func WithCheckIgnore(checkIgnore func(string) bool) Option {
+	return func(conf *configuration) {
+		conf.checkIgnore = checkIgnore
+	}
+}
+
+// WithTransform sets the function called on the payload before it is sent, see SetTransform.
+func WithTransform(transform func(map[string]interface{})) Option {
+	return func(conf *configuration) {
+		conf.transform = transform
+	}
+}
@@ -0,0 +1,98 @@
+package rollbar
+
+import (
+	"context"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+	"testing"
+)
+
+func TestApplyOptions(t *testing.T) {
+	client := NewSync("", "test", "", "", "")
+	client.SetCodeVersion("abc123")
+	client.ApplyOptions(
+		WithEnvironment("staging"),
+		WithEndpoint("https://rollbar.example.com/api/1/item/"),
+		WithScrubHeaders(regexp.MustCompile("X-Api-Key")),
+		WithTelemetryLevel("error"),
+	)
+
+	config := client.Config()
+	if config.Environment != "staging" || config.CodeVersion != "abc123" || client.TelemetryLevel() != "error" {
+		t.Error("wrong configuration, got:", config.Environment, config.CodeVersion, client.TelemetryLevel())
+	}
+	if config.Endpoint != "https://rollbar.example.com/api/1/item/" || config.Transport.Endpoint != config.Endpoint {
+		t.Error("wrong endpoint, got:", config.Endpoint, config.Transport.Endpoint)
+	}
+	if client.Telemetry.Network.ScrubHeaders.String() != "X-Api-Key" {
+		t.Error("expected the telemetry to use the new headers, got:", client.Telemetry.Network.ScrubHeaders)
+	}
+}
+
+func TestApplyOptionsConcurrently(t *testing.T) {
+	client := testClient()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			client.ApplyOptions(WithEnvironment("staging"), WithCodeVersion("v2"))
+		}()
+		go func() {
+			defer wg.Done()
+			client.buildBody(context.Background(), INFO, "reconfiguring", nil)
+		}()
+	}
+	wg.Wait()
+
+	if client.Environment() != "staging" || client.CodeVersion() != "v2" {
+		t.Error("wrong configuration, got:", client.Environment(), client.CodeVersion())
+	}
+}
+
+func TestSettersConcurrentlyWithApplyOptions(t *testing.T) {
+	client := testClient()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			client.ApplyOptions(WithEnvironment("staging"))
+		}()
+		go func() {
+			defer wg.Done()
+			client.AddEnricher(func(map[string]interface{}) {})
+		}()
+	}
+	wg.Wait()
+
+	if n := len(client.configuration().enrichers); n != 10 {
+		t.Error("expected no enricher to be lost, got:", n)
+	}
+}
+
+func TestApplyOptionsConcurrentlyWithNetworkTelemetry(t *testing.T) {
+	client := testClient()
+	client.SetTelemetry(EnableNetworkTelemetryRequestHeaders())
+	req := httptest.NewRequest("GET", "http://example.com/", nil)
+	req.Header.Set("X-Request-Id", "42")
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			client.ApplyOptions(WithAllowedHeaders("X-Request-Id"), WithScrubHeaders(regexp.MustCompile("X-Api-Key")))
+		}()
+		go func() {
+			defer wg.Done()
+			client.Telemetry.populateTransporterBody(req, nil)
+		}()
+	}
+	wg.Wait()
+
+	scrubHeaders, allowed := client.Telemetry.headerFilters()
+	if scrubHeaders.String() != "X-Api-Key" || len(allowed) != 1 {
+		t.Error("expected the telemetry to use the new headers, got:", scrubHeaders, allowed)
+	}
+}
@@ -0,0 +1,44 @@
+package rollbar
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// PersonScrubPolicy tells how the email and username of the person of the items are reported, see
+// SetPersonScrubPolicy. Policies are combined with |, e.g. HashEmail|OmitUsername.
+type PersonScrubPolicy int
+
+const (
+	// HashEmail reports the hex encoded SHA-256 hash of person.email rather than the email.
+	HashEmail PersonScrubPolicy = 1 << iota
+	// HashUsername reports the hex encoded SHA-256 hash of person.username rather than the
+	// username.
+	HashUsername
+	// OmitEmail leaves person.email out of the items. It takes precedence over HashEmail.
+	OmitEmail
+	// OmitUsername leaves person.username out of the items. It takes precedence over HashUsername.
+	OmitUsername
+)
+
+// scrubPerson applies policy to the email and username of the person data, leaving its id as is.
+func scrubPerson(person map[string]string, policy PersonScrubPolicy) {
+	scrubPersonKeys(person, policy, "email", "username")
+}
+
+// scrubPersonKeys applies policy to the email and username of person found under the given keys,
+// such as "Email" and "Username" in the person of the configured options of the diagnostic.
+func scrubPersonKeys(person map[string]string, policy PersonScrubPolicy, emailKey, usernameKey string) {
+	scrubPersonField(person, emailKey, policy&OmitEmail != 0, policy&HashEmail != 0)
+	scrubPersonField(person, usernameKey, policy&OmitUsername != 0, policy&HashUsername != 0)
+}
+
+func scrubPersonField(person map[string]string, key string, omit, hash bool) {
+	switch value := person[key]; {
+	case omit:
+		delete(person, key)
+	case hash && value != "":
+		sum := sha256.Sum256([]byte(value))
+		person[key] = hex.EncodeToString(sum[:])
+	}
+}
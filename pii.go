@@ -0,0 +1,45 @@
+package rollbar
+
+import "regexp"
+
+var (
+	emailPattern      = regexp.MustCompile(`[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}`)
+	cardNumberPattern = regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`)
+	awsKeyPattern     = regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)
+)
+
+// scrubPII masks the email addresses, credit card numbers, bearer tokens and AWS access key IDs
+// found in s. Sequences of 13 to 19 digits, possibly grouped by spaces or dashes, are only masked
+// if they pass the Luhn check, which keeps most identifiers and timestamps as is.
+func scrubPII(s string) string {
+	s = emailPattern.ReplaceAllString(s, FILTERED)
+	s = cardNumberPattern.ReplaceAllStringFunc(s, func(number string) string {
+		if luhn(number) {
+			return FILTERED
+		}
+		return number
+	})
+	s = bearerTokenPattern.ReplaceAllString(s, "${1}"+FILTERED)
+	return awsKeyPattern.ReplaceAllString(s, FILTERED)
+}
+
+// luhn returns whether the digits of number, ignoring the other characters, pass the Luhn check.
+func luhn(number string) bool {
+	sum, double := 0, false
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
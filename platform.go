@@ -0,0 +1,42 @@
+package rollbar
+
+import (
+	"os"
+	"runtime"
+)
+
+// platformAWSLambda is the platform of the functions running on AWS Lambda, whether detected from
+// the environment or set by LambdaWrapper.
+const platformAWSLambda = "aws_lambda"
+
+// dockerEnvFile is created by Docker at the root of the file system of containers.
+var dockerEnvFile = "/.dockerenv"
+
+// detectPlatform returns the platform the application runs on, reported as data.platform, and its
+// details, reported in data.server. The platform is detected from the environment variables set by
+// Heroku (DYNO), AWS Lambda (AWS_LAMBDA_FUNCTION_NAME) and Google Cloud Run or Knative
+// (K_SERVICE), or else from the presence of /.dockerenv, and is runtime.GOOS otherwise.
+func detectPlatform() (string, map[string]interface{}) {
+	details := map[string]interface{}{}
+	switch {
+	case os.Getenv("DYNO") != "":
+		addNonEmpty(details, "dyno", os.Getenv("DYNO"))
+		addNonEmpty(details, "app", os.Getenv("HEROKU_APP_NAME"))
+		addNonEmpty(details, "release", os.Getenv("HEROKU_RELEASE_VERSION"))
+		return "heroku", map[string]interface{}{"heroku": details}
+	case os.Getenv("AWS_LAMBDA_FUNCTION_NAME") != "":
+		addNonEmpty(details, "function_name", os.Getenv("AWS_LAMBDA_FUNCTION_NAME"))
+		addNonEmpty(details, "function_version", os.Getenv("AWS_LAMBDA_FUNCTION_VERSION"))
+		addNonEmpty(details, "region", os.Getenv("AWS_REGION"))
+		return platformAWSLambda, map[string]interface{}{"lambda": details}
+	case os.Getenv("K_SERVICE") != "":
+		addNonEmpty(details, "service", os.Getenv("K_SERVICE"))
+		addNonEmpty(details, "revision", os.Getenv("K_REVISION"))
+		addNonEmpty(details, "configuration", os.Getenv("K_CONFIGURATION"))
+		return "cloud_run", map[string]interface{}{"cloud_run": details}
+	}
+	if _, err := os.Stat(dockerEnvFile); err == nil {
+		return "docker", nil
+	}
+	return runtime.GOOS, nil
+}
@@ -0,0 +1,24 @@
+package rollbar
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestDetectPlatform(t *testing.T) {
+	defer func(file string) { dockerEnvFile = file }(dockerEnvFile)
+	dockerEnvFile = "/nonexistent/.dockerenv"
+	if platform, details := detectPlatform(); platform != runtime.GOOS || details != nil {
+		t.Error("expected the operating system, got:", platform, details)
+	}
+
+	defer setTestEnv(map[string]string{"DYNO": "web.1", "HEROKU_APP_NAME": "shop"})()
+	client := testClient()
+	client.Message(INFO, "platform")
+
+	data := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	heroku, _ := data["server"].(map[string]interface{})["heroku"].(map[string]interface{})
+	if data["platform"] != "heroku" || heroku["dyno"] != "web.1" || heroku["app"] != "shop" {
+		t.Error("wrong platform, got:", data["platform"], heroku)
+	}
+}
@@ -0,0 +1,67 @@
+package rollbar
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ProcessEnricher returns an enricher which adds the details of the running process as
+// data.server.process: its pid, executable path, arguments, start time and uptime in seconds, and
+// the hostname of the machine, which tells apart the processes of a service running on the same
+// host. The start time is the time this package was initialized, which is close to the start of
+// the process.
+//
+// Arguments are scrubbed since they often carry credentials: the values of the flags whose name
+// matches scrubArgs (regexp.MustCompile(DefaultScrubFields) if nil), given as -name=value or
+// -name value, are replaced by FILTERED, as are secrets such as bearer tokens and API keys found in
+// any argument, see SetScrubSecrets.
+func ProcessEnricher(scrubArgs *regexp.Regexp) EnricherFunc {
+	if scrubArgs == nil {
+		scrubArgs = regexp.MustCompile(DefaultScrubFields)
+	}
+	pid := os.Getpid()
+	executable, _ := os.Executable()
+	argv := scrubArguments(os.Args, scrubArgs)
+	start := processStart.UTC().Format(time.RFC3339)
+
+	return func(data map[string]interface{}) {
+		process := map[string]interface{}{
+			"pid":            pid,
+			"argv":           argv,
+			"start_time":     start,
+			"uptime_seconds": int64(time.Since(processStart).Seconds()),
+		}
+		addNonEmpty(process, "executable", executable)
+		addNonEmpty(process, "hostname", lookupHostname())
+		serverData(data)["process"] = process
+	}
+}
+
+// scrubArguments returns a copy of the command-line arguments with the values of the flags
+// matching pattern and the secrets found in free text replaced by FILTERED.
+func scrubArguments(args []string, pattern *regexp.Regexp) []string {
+	scrubbed := make([]string, len(args))
+	filterNext := false
+	for i, arg := range args {
+		isFlag := strings.HasPrefix(arg, "-") && arg != "-" && arg != "--"
+		if filterNext && !isFlag {
+			scrubbed[i] = FILTERED
+			filterNext = false
+			continue
+		}
+		filterNext = false
+		scrubbed[i] = scrubSecrets(arg)
+		if !isFlag {
+			continue
+		}
+		name := strings.TrimLeft(arg, "-")
+		if eq := strings.IndexByte(name, '='); eq < 0 {
+			filterNext = pattern.MatchString(name)
+		} else if pattern.MatchString(name[:eq]) {
+			scrubbed[i] = arg[:len(arg)-len(name)+eq+1] + FILTERED
+		}
+	}
+	return scrubbed
+}
@@ -0,0 +1,37 @@
+package rollbar
+
+import (
+	"os"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestProcessEnricher(t *testing.T) {
+	client := testClient()
+	client.AddEnricher(ProcessEnricher(nil))
+	client.Message(INFO, "process")
+
+	data := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	process := data["server"].(map[string]interface{})["process"].(map[string]interface{})
+	if process["pid"] != os.Getpid() || process["start_time"] == "" {
+		t.Error("wrong process details, got:", process)
+	}
+	if argv := process["argv"].([]string); len(argv) != len(os.Args) {
+		t.Error("expected the arguments of the process, got:", argv)
+	}
+}
+
+func TestScrubArguments(t *testing.T) {
+	args := []string{
+		"/usr/bin/app", "-password", "hunter2", "--api-token=abc", "-v", "serve",
+		"--secret", "--port", "8080", "--header", "Authorization: Bearer abc.def",
+	}
+	expected := []string{
+		"/usr/bin/app", "-password", FILTERED, "--api-token=" + FILTERED, "-v", "serve",
+		"--secret", "--port", "8080", "--header", "Authorization: Bearer " + FILTERED,
+	}
+	if scrubbed := scrubArguments(args, regexp.MustCompile(DefaultScrubFields)); !reflect.DeepEqual(scrubbed, expected) {
+		t.Error("wrong arguments, got:", scrubbed)
+	}
+}
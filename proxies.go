@@ -0,0 +1,203 @@
+package rollbar
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// DefaultClientIPHeaders are the headers from which the client IP address of requests is taken, in
+// order of priority, unless set otherwise with SetClientIPHeaders.
+var DefaultClientIPHeaders = []string{"X-Real-IP", "X-Forwarded-For"}
+
+// SetTrustedProxies sets the addresses of the proxies trusted to set the client IP headers, as
+// CIDR ranges such as "10.0.0.0/8" or single IP addresses. Once set, the client IP address of a
+// request is only taken from its headers when the request comes from a trusted proxy, and the
+// X-Forwarded-For chain is read from the right, skipping the trusted proxies, so that clients
+// cannot spoof their address. By default all peers are trusted; an empty list restores this
+// default. An error is returned, and the setting left unchanged, if an address cannot be parsed.
+func (c *Client) SetTrustedProxies(cidrs []string) error {
+	proxies, err := parseTrustedProxies(cidrs)
+	if err != nil {
+		return err
+	}
+	c.ApplyOptions(func(conf *configuration) { conf.trustedProxies = proxies })
+	return nil
+}
+
+// SetClientIPHeaders sets the headers from which the client IP address of requests is taken, in
+// order of priority, e.g. SetClientIPHeaders("CF-Connecting-IP", "X-Forwarded-For") behind
+// Cloudflare. Headers holding several addresses are read like X-Forwarded-For. Without headers,
+// the address of the peer is always used. The default value is DefaultClientIPHeaders.
+func (c *Client) SetClientIPHeaders(headers ...string) {
+	c.ApplyOptions(func(conf *configuration) { conf.ipHeaders = headers })
+}
+
+// SetTrustForwardedHeaders sets whether the scheme and host of the url of requests are taken from
+// the X-Forwarded-Proto and X-Forwarded-Host headers, or from the Forwarded header, when the
+// request comes from a trusted proxy, see SetTrustedProxies, so that the url of requests received
+// through a TLS-terminating proxy is reported as served to the client. When enabled, the url of
+// requests is always absolute, using the Host header and whether the request was received over
+// TLS when the forwarding headers are missing or untrusted. This is disabled by default.
+func (c *Client) SetTrustForwardedHeaders(trust bool) {
+	c.ApplyOptions(func(conf *configuration) { conf.trustForwarded = trust })
+}
+
+// TrustForwardedHeaders specifies whether or not the url of requests is built from the forwarding
+// headers of trusted proxies.
+func (c *Client) TrustForwardedHeaders() bool {
+	return c.configuration().trustForwarded
+}
+
+// TrustedProxies are the currently set trusted proxies, as CIDR ranges.
+func (c *Client) TrustedProxies() []string {
+	trusted := c.configuration().trustedProxies
+	proxies := make([]string, 0, len(trusted))
+	for _, proxy := range trusted {
+		proxies = append(proxies, proxy.String())
+	}
+	return proxies
+}
+
+// ClientIPHeaders are the currently set headers holding the client IP address of requests.
+func (c *Client) ClientIPHeaders() []string {
+	return c.configuration().ipHeaders
+}
+
+func parseTrustedProxies(cidrs []string) ([]*net.IPNet, error) {
+	if len(cidrs) == 0 {
+		return nil, nil
+	}
+	proxies := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			ip := net.ParseIP(cidr)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy address %q", cidr)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy range %q: %v", cidr, err)
+		}
+		proxies = append(proxies, network)
+	}
+	return proxies, nil
+}
+
+// trusted returns whether ip is the address of a trusted proxy. All addresses are trusted when no
+// proxy is set.
+func (c configuration) trusted(ip string) bool {
+	if c.trustedProxies == nil {
+		return true
+	}
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, proxy := range c.trustedProxies {
+		if proxy.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteIP attempts to extract the real remote IP address by looking first at the client IP
+// headers, in order, when the peer is a trusted proxy, and then falling back to RemoteAddr defined
+// in http.Request.
+func remoteIP(configuration configuration, req *http.Request) string {
+	peer := peerIP(req)
+	if !configuration.trusted(peer) {
+		return peer
+	}
+	for _, header := range configuration.ipHeaders {
+		value := req.Header.Get(header)
+		if value == "" {
+			continue
+		}
+		ips := strings.Split(value, ",")
+		if configuration.trustedProxies == nil {
+			return strings.TrimSpace(ips[0])
+		}
+		// Each proxy appends the address of its peer, so the client is the rightmost address which
+		// is not a trusted proxy.
+		for i := len(ips) - 1; i >= 0; i-- {
+			ip := strings.TrimSpace(ips[i])
+			if i == 0 || !configuration.trusted(ip) {
+				return ip
+			}
+		}
+	}
+	return peer
+}
+
+// peerIP returns the IP address of the peer which sent req.
+func peerIP(req *http.Request) string {
+	if host, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+		return host
+	}
+	return strings.Split(req.RemoteAddr, ":")[0]
+}
+
+// requestURL returns the url of req to report. Unless the forwarding headers are trusted, this is
+// the URL of req as is. Otherwise the url is made absolute with the scheme and host forwarded by
+// the peer if it is a trusted proxy, and those of req itself if not.
+func requestURL(configuration configuration, req *http.Request) string {
+	if !configuration.trustForwarded {
+		return req.URL.String()
+	}
+	u := *req.URL
+	u.Scheme, u.Host = "http", req.Host
+	if req.TLS != nil {
+		u.Scheme = "https"
+	}
+	if configuration.trusted(peerIP(req)) {
+		proto, host := forwarded(req.Header)
+		if proto != "" {
+			u.Scheme = proto
+		}
+		if host != "" {
+			u.Host = host
+		}
+	}
+	return u.String()
+}
+
+// forwarded returns the scheme and host forwarded by the first proxy in headers, taken from the
+// X-Forwarded-Proto and X-Forwarded-Host headers, or else from the Forwarded header. They are
+// empty if not forwarded.
+func forwarded(headers http.Header) (proto, host string) {
+	proto = firstValue(headers.Get("X-Forwarded-Proto"))
+	host = firstValue(headers.Get("X-Forwarded-Host"))
+	if proto != "" || host != "" {
+		return strings.ToLower(proto), host
+	}
+	// Forwarded: for=192.0.2.60;proto=https;host=example.com, for=198.51.100.17
+	for _, pair := range strings.Split(firstValue(headers.Get("Forwarded")), ";") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		value := strings.Trim(parts[1], `"`)
+		switch strings.ToLower(parts[0]) {
+		case "proto":
+			proto = strings.ToLower(value)
+		case "host":
+			host = value
+		}
+	}
+	return proto, host
+}
+
+// firstValue returns the first of the comma separated values of a header.
+func firstValue(value string) string {
+	return strings.TrimSpace(strings.Split(value, ",")[0])
+}
@@ -0,0 +1,102 @@
+package rollbar
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRemoteIP(t *testing.T) {
+	client := testClient()
+	if err := client.SetTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		remoteAddr, forwardedFor, expected string
+	}{
+		// The headers of untrusted peers are ignored.
+		{"8.8.8.8:1234", "1.1.1.1", "8.8.8.8"},
+		// The client is the rightmost untrusted address.
+		{"10.0.0.2:1234", "6.6.6.6, 1.1.1.1, 10.0.0.3", "1.1.1.1"},
+		{"192.168.1.1:1234", "1.1.1.1", "1.1.1.1"},
+		// Without headers, the peer is the client.
+		{"10.0.0.2:1234", "", "10.0.0.2"},
+		{"[2001:db8::1]:1234", "", "2001:db8::1"},
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = c.remoteAddr
+		if c.forwardedFor != "" {
+			r.Header.Set("X-Forwarded-For", c.forwardedFor)
+		}
+		if ip := remoteIP(*client.configuration(), r); ip != c.expected {
+			t.Errorf("remoteIP(%s, %q) = %s, expected %s", c.remoteAddr, c.forwardedFor, ip, c.expected)
+		}
+	}
+}
+
+func TestClientIPHeaders(t *testing.T) {
+	client := testClient()
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "10.0.0.2:1234"
+	r.Header.Set("X-Forwarded-For", "1.1.1.1, 10.0.0.3")
+	r.Header.Set("CF-Connecting-IP", "2.2.2.2")
+
+	if ip := remoteIP(*client.configuration(), r); ip != "1.1.1.1" {
+		t.Error("expected the first forwarded address when all peers are trusted, got:", ip)
+	}
+	client.SetClientIPHeaders("CF-Connecting-IP", "X-Forwarded-For")
+	if ip := remoteIP(*client.configuration(), r); ip != "2.2.2.2" {
+		t.Error("expected the address of the first header, got:", ip)
+	}
+	client.SetClientIPHeaders()
+	if ip := remoteIP(*client.configuration(), r); ip != "10.0.0.2" {
+		t.Error("expected the peer without headers, got:", ip)
+	}
+}
+
+func TestSetTrustedProxiesInvalid(t *testing.T) {
+	client := testClient()
+	if err := client.SetTrustedProxies([]string{"10.0.0.0/33"}); err == nil {
+		t.Error("expected an error for an invalid range")
+	}
+	if err := client.SetTrustedProxies([]string{"proxy.local"}); err == nil {
+		t.Error("expected an error for an invalid address")
+	}
+	if proxies := client.TrustedProxies(); len(proxies) != 0 {
+		t.Error("the trusted proxies should be left unchanged, got:", proxies)
+	}
+}
+
+func TestRequestURLForwarded(t *testing.T) {
+	client := testClient()
+	if err := client.SetTrustedProxies([]string{"10.0.0.0/8"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		remoteAddr, header, value, expected string
+	}{
+		// The headers of trusted proxies are honored.
+		{"10.0.0.2:1234", "X-Forwarded-Proto", "https", "https://internal.local/orders?id=1"},
+		{"10.0.0.2:1234", "X-Forwarded-Host", "shop.example.com, internal.local", "http://shop.example.com/orders?id=1"},
+		{"10.0.0.2:1234", "Forwarded", `for=1.1.1.1;proto=https;host="shop.example.com", for=10.0.0.3`, "https://shop.example.com/orders?id=1"},
+		// The headers of untrusted peers are ignored.
+		{"8.8.8.8:1234", "X-Forwarded-Proto", "https", "http://internal.local/orders?id=1"},
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest("GET", "http://internal.local/orders?id=1", nil)
+		r.URL.Scheme, r.URL.Host = "", ""
+		r.RemoteAddr = c.remoteAddr
+		r.Header.Set(c.header, c.value)
+
+		client.SetTrustForwardedHeaders(false)
+		if url := requestURL(*client.configuration(), r); url != "/orders?id=1" {
+			t.Errorf("expected the URL as is when disabled, got %s", url)
+		}
+		client.SetTrustForwardedHeaders(true)
+		if url := requestURL(*client.configuration(), r); url != c.expected {
+			t.Errorf("requestURL(%s, %s: %s) = %s, expected %s", c.remoteAddr, c.header, c.value, url, c.expected)
+		}
+	}
+}
@@ -51,9 +51,35 @@ func (q *Queue) Pop() interface{} {
 	return node
 }
 
-// Items returns all populated (non nil) items
+// Items returns all populated (non nil) items. It is the same as Snapshot.
 func (q *Queue) Items() []interface{} {
+	return q.Snapshot()
+}
+
+// Snapshot returns a copy of the items of the queue in first to last order, which can be used
+// safely while items are pushed concurrently.
+func (q *Queue) Snapshot() []interface{} {
 	q.lock.RLock()
 	defer q.lock.RUnlock()
-	return q.nodes[:q.count]
+	return q.copyItems()
+}
+
+// Drain removes all the items from the queue and returns them in first to last order.
+func (q *Queue) Drain() []interface{} {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	items := q.copyItems()
+	for i := range q.nodes {
+		q.nodes[i] = nil
+	}
+	q.head, q.tail, q.count = 0, 0, 0
+	return items
+}
+
+func (q *Queue) copyItems() []interface{} {
+	items := make([]interface{}, q.count)
+	for i := range items {
+		items[i] = q.nodes[(q.head+i)%len(q.nodes)]
+	}
+	return items
 }
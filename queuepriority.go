@@ -0,0 +1,70 @@
+package rollbar
+
+// priorityQueuer is implemented by the transports whose queue can reserve room for the items of
+// high levels.
+type priorityQueuer interface {
+	SetQueuePriority(reserve int, level string)
+}
+
+// SetQueuePriority reserves the last reserve slots of the buffer for the items of the given level
+// or higher: once the queue holds Buffer-reserve items, the items of lower levels are dropped while
+// those of higher levels are still queued, so that a flood of debug and info items cannot crowd
+// out errors, e.g. SetQueuePriority(100, ERR). A reserve of 0, the default, queues the items in
+// order of arrival whatever their level.
+func (t *AsyncTransport) SetQueuePriority(reserve int, level string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.priorityReserve = reserve
+	t.priorityLevel = level
+}
+
+// admits returns whether the item with the given body can be queued when queued items are already
+// in the queue, according to the priority set with SetQueuePriority.
+func (t *AsyncTransport) admits(body map[string]interface{}, queued int) bool {
+	t.lock.RLock()
+	reserve, level := t.priorityReserve, t.priorityLevel
+	t.lock.RUnlock()
+	if reserve <= 0 || queued < t.Buffer-reserve {
+		return true
+	}
+	return levelRank(itemLevel(body)) >= levelRank(level)
+}
+
+// levelRank ranks the levels of items from debug to critical. Unknown levels rank below debug.
+func levelRank(level string) int {
+	for i, l := range escalationLevels {
+		if l == level {
+			return i
+		}
+	}
+	return -1
+}
+
+func (t *interceptedTransport) SetQueuePriority(reserve int, level string) {
+	if inner, ok := t.Transport.(priorityQueuer); ok {
+		inner.SetQueuePriority(reserve, level)
+	}
+}
+
+// SetQueuePriority reserves room for the items of high levels in the queue of each destination
+// supporting it.
+func (t *FanOutTransport) SetQueuePriority(reserve int, level string) {
+	for _, d := range t.destinations {
+		if q, ok := d.(priorityQueuer); ok {
+			q.SetQueuePriority(reserve, level)
+		}
+	}
+}
+
+// SetQueuePriority reserves the last reserve slots of the queue of the transport for the items of
+// the given level or higher, so that items of lower levels are dropped first when the queue is
+// near capacity, see AsyncTransport.SetQueuePriority. Transports without a queue are left
+// unchanged and an error is logged.
+func (c *Client) SetQueuePriority(reserve int, level string) {
+	q, ok := c.Transport.(priorityQueuer)
+	if !ok {
+		rollbarError(nil, "transport %T does not support queue priorities", c.Transport)
+		return
+	}
+	q.SetQueuePriority(reserve, level)
+}
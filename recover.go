@@ -0,0 +1,40 @@
+package rollbar
+
+import "context"
+
+// RecoverPolicy tells Recover what to do with a panic once it is reported.
+type RecoverPolicy int
+
+const (
+	// SwallowPolicy stops the panic, and the function deferring Recover returns normally with the
+	// zero values or the values set to its named results.
+	SwallowPolicy RecoverPolicy = iota
+	// RepanicPolicy waits for the panic to be sent and then resumes it.
+	RepanicPolicy
+)
+
+// Recover recovers and reports a panic of the function deferring it to Rollbar within ctx, as a
+// critical item whose stack trace starts where the panic was raised, and then swallows or resumes
+// the panic according to policy:
+//
+//	func handle(ctx context.Context, job Job) {
+//		defer client.Recover(ctx, rollbar.SwallowPolicy)
+//		...
+//	}
+//
+// Recover must be deferred directly, as in the example, since a panic can only be recovered by a
+// deferred function: called from a deferred closure, it does nothing.
+func (c *Client) Recover(ctx context.Context, policy RecoverPolicy) {
+	c.reportRecovered(ctx, recover(), policy)
+}
+
+// reportRecovered reports a recovered panic, if any, and resumes it according to policy.
+func (c *Client) reportRecovered(ctx context.Context, err interface{}, policy RecoverPolicy) {
+	if err == nil {
+		return
+	}
+	c.LogPanicWithContext(ctx, err, policy == RepanicPolicy)
+	if policy == RepanicPolicy {
+		panic(err)
+	}
+}
@@ -0,0 +1,58 @@
+package rollbar
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func recoveredJob(client *Client, policy RecoverPolicy) (result string) {
+	defer client.Recover(context.Background(), policy)
+	result = "started"
+	panic(errors.New("job failed"))
+}
+
+func TestRecover(t *testing.T) {
+	client := testClient()
+	if result := recoveredJob(client, SwallowPolicy); result != "started" {
+		t.Error("expected the named result to be kept, got:", result)
+	}
+	transport := client.Transport.(*TestTransport)
+	if transport.WaitCalled {
+		t.Error("Wait called unexpectedly")
+	}
+	data := transport.Body["data"].(map[string]interface{})
+	if data["level"] != "critical" {
+		t.Error("expected a critical item, got:", data["level"])
+	}
+	if errorFromData(data)["message"] != "job failed" {
+		t.Error("data should have correct error message")
+	}
+	traceChain := data["body"].(map[string]interface{})["trace_chain"].([]map[string]interface{})
+	if frames := traceChain[0]["frames"].(stack); frames[0].Method != "rollbar-go.recoveredJob" {
+		t.Error("expected the trace to start at the panic, got:", frames[0])
+	}
+}
+
+func TestRecoverRepanic(t *testing.T) {
+	client := testClient()
+	defer func() {
+		if err := recover(); err == nil || err.(error).Error() != "job failed" {
+			t.Error("expected the panic to be resumed, got:", err)
+		}
+		if !client.Transport.(*TestTransport).WaitCalled {
+			t.Error("expected Wait to be called before resuming the panic")
+		}
+	}()
+	recoveredJob(client, RepanicPolicy)
+}
+
+func TestRecoverNoPanic(t *testing.T) {
+	client := testClient()
+	func() {
+		defer client.Recover(context.Background(), RepanicPolicy)
+	}()
+	if client.Transport.(*TestTransport).Body != nil {
+		t.Error("nothing should be reported without a panic")
+	}
+}
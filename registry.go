@@ -0,0 +1,63 @@
+package rollbar
+
+import (
+	"sort"
+	"sync"
+)
+
+var (
+	clientsLock sync.RWMutex
+	clients     = map[string]*Client{}
+)
+
+// RegisterClient registers client under the given name, e.g. the name of the component of the
+// application reporting to a Rollbar project of its own, so that it can be retrieved anywhere with
+// C instead of passing it around:
+//
+//	rollbar.RegisterClient("payments", rollbar.New(paymentsToken, env, version, host, root))
+//	...
+//	rollbar.C("payments").Error(err)
+//
+// Registering a client under a name already in use replaces the previous one, which is not
+// closed. Registering a nil client removes the name from the registry.
+func RegisterClient(name string, client *Client) {
+	clientsLock.Lock()
+	defer clientsLock.Unlock()
+	if client == nil {
+		delete(clients, name)
+		return
+	}
+	clients[name] = client
+}
+
+// LookupClient returns the client registered under the given name with RegisterClient, and
+// whether there is one.
+func LookupClient(name string) (*Client, bool) {
+	clientsLock.RLock()
+	defer clientsLock.RUnlock()
+	client, ok := clients[name]
+	return client, ok
+}
+
+// C returns the client registered under the given name with RegisterClient, or the managed Client
+// instance of this package if there is none, so that the items of components without a project of
+// their own are reported to the default project.
+func C(name string) *Client {
+	if client, ok := LookupClient(name); ok {
+		return client
+	}
+	return std
+}
+
+// RegisteredClients returns the names of the registered clients, in lexical order, e.g. to close
+// all the clients on shutdown.
+func RegisteredClients() []string {
+	clientsLock.RLock()
+	defer clientsLock.RUnlock()
+	names := make([]string, 0, len(clients))
+	for name := range clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,37 @@
+package rollbar
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRegisterClient(t *testing.T) {
+	payments := testClient()
+	RegisterClient("payments", payments)
+	RegisterClient("search", testClient())
+	defer RegisterClient("payments", nil)
+	defer RegisterClient("search", nil)
+
+	if C("payments") != payments {
+		t.Error("expected the registered client")
+	}
+	if C("billing") != std {
+		t.Error("expected the managed client for unregistered names")
+	}
+	if _, ok := LookupClient("billing"); ok {
+		t.Error("expected no client registered as billing")
+	}
+	if names := RegisteredClients(); !reflect.DeepEqual(names, []string{"payments", "search"}) {
+		t.Error("unexpected names:", names)
+	}
+
+	C("payments").Message(ERR, "declined")
+	if payments.Transport.(*TestTransport).Body == nil {
+		t.Error("expected the item to be reported by the registered client")
+	}
+
+	RegisterClient("search", nil)
+	if _, ok := LookupClient("search"); ok {
+		t.Error("expected the client to be removed")
+	}
+}
@@ -0,0 +1,64 @@
+package rollbar
+
+import (
+	"context"
+	"mime/multipart"
+	"net/http"
+)
+
+// RequestInfo describes an incoming request independently of net/http. Web frameworks which do
+// not use *http.Request, such as those based on fasthttp, can convert their requests into a
+// RequestInfo so that they are reported as data.request like an *http.Request would be, including
+// the scrubbing of headers, query and form parameters.
+type RequestInfo struct {
+	// URL is the full URL of the request, including the query string.
+	URL string
+	// Method is the HTTP method of the request.
+	Method string
+	// Headers are the request headers.
+	Headers map[string][]string
+	// Query holds the parsed query string parameters.
+	Query map[string][]string
+	// Form holds the parsed POST / PUT form parameters.
+	Form map[string][]string
+	// Files holds the files of a parsed multipart/form-data request, as found in
+	// multipart.Form.File. Only their names, sizes and content types are reported, never their
+	// contents.
+	Files map[string][]*multipart.FileHeader
+	// UserIP is the IP address of the client that made the request.
+	UserIP string
+}
+
+// A RequestExtractorFunc converts a value describing an incoming request, such as the request
+// context of a web framework which does not use *http.Request, into a RequestInfo. The second
+// return value should be false if the value is not handled by the function.
+//
+// A RequestExtractorFunc is used by Log and the level functions (Critical, Error, etc.) for
+// arguments of types they do not recognize. See SetRequestExtractor.
+type RequestExtractorFunc func(interface{}) (*RequestInfo, bool)
+
+// NewRequestInfoContext returns a new Context that carries the request info. Items reported within
+// this context include the request as data.request unless an *http.Request is given explicitly.
+func NewRequestInfoContext(ctx context.Context, info *RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey, info)
+}
+
+// RequestInfoFromContext returns the RequestInfo value stored in ctx, if any.
+func RequestInfoFromContext(ctx context.Context) (*RequestInfo, bool) {
+	info, ok := ctx.Value(requestInfoKey).(*RequestInfo)
+	return info, ok
+}
+
+// NewRequestContext returns a new Context that carries the request. Items reported within this
+// context include the request as data.request unless a request is given explicitly, so that code
+// deep in a handler does not need the request to report it. It takes precedence over a
+// RequestInfo carried by the same context.
+func NewRequestContext(ctx context.Context, r *http.Request) context.Context {
+	return context.WithValue(ctx, requestKey, r)
+}
+
+// RequestFromContext returns the request stored in ctx, if any.
+func RequestFromContext(ctx context.Context) (*http.Request, bool) {
+	r, ok := ctx.Value(requestKey).(*http.Request)
+	return r, ok
+}
@@ -2,10 +2,11 @@ package rollbar
 
 import (
 	"context"
+	"io"
 	"net/http"
-	"os"
 	"regexp"
 	"runtime"
+	"time"
 )
 
 const (
@@ -31,8 +32,7 @@ const (
 )
 
 var (
-	hostname, _ = os.Hostname()
-	std         = NewAsync("", "development", "", hostname, "")
+	std         = NewAsync("", "development", "", "", "")
 	nilErrTitle = "<nil>"
 )
 
@@ -52,6 +52,34 @@ type UnwrapperFunc func(error) error
 // behavior by calling SetStackTracer. See SetStackTracer for more details.
 type StackTracerFunc func(error) ([]runtime.Frame, bool)
 
+// An ErrorTaggerFunc returns the tags categorizing an error, such as "retryable" or "dependency",
+// or nil if it has none. It is called with the reported error itself, so errors.Is and errors.As
+// can be used to inspect the errors it wraps.
+//
+// The Client does not tag errors by default. See SetErrorTagger for more details.
+type ErrorTaggerFunc func(error) []string
+
+// A LevelMapperFunc returns the level at which an error is reported, and true, or false to keep
+// the level given by the call site. It is called with the reported error itself, so errors.Is and
+// errors.As can be used to inspect the errors it wraps.
+//
+// The Client does not map levels by default. See SetLevelMapper for more details.
+type LevelMapperFunc func(err error) (level string, ok bool)
+
+// A PersonProviderFunc returns the person associated with an item, or nil if there is none. It is
+// called when the item is reported, with the context of the report and the request of the item,
+// which is nil for items without a request, so that the person can be resolved lazily, e.g. from a
+// session store or the claims of a token.
+//
+// The Client does not use a provider by default. See SetPersonProvider for more details.
+type PersonProviderFunc func(ctx context.Context, r *http.Request) *Person
+
+// A MessageScrubberFunc returns the text of an item title, exception message or message body to
+// report in place of the given text, e.g. with its secrets redacted.
+//
+// The Client does not use a message scrubber by default. See SetMessageScrubber for more details.
+type MessageScrubberFunc func(string) string
+
 // DefaultUnwrapper is the default UnwrapperFunc used by rollbar-go clients. It can unwrap any
 // error types with the Unwrap method specified in Go 1.13, or any error type implementing the
 // legacy CauseStacker interface.
@@ -94,7 +122,8 @@ func SetContext(ctx context.Context) {
 	std.SetContext(ctx)
 }
 
-// SetTelemetry sets the telemetry
+// SetTelemetry replaces the telemetry of the managed Client instance with one configured by the
+// given options, e.g. SetTelemetry(EnableNetworkTelemetry(http.DefaultClient), SetCustomQueueSize(100)).
 func SetTelemetry(options ...OptionFunc) {
 	std.SetTelemetry(options...)
 }
@@ -113,6 +142,66 @@ func CaptureTelemetryEvent(eventType, eventlevel string, eventData map[string]in
 	std.CaptureTelemetryEvent(eventType, eventlevel, eventData)
 }
 
+// CaptureTelemetryEventWithContext sets the user-specified telemetry event within the given
+// context on the managed Client instance. See Client.CaptureTelemetryEventWithContext.
+func CaptureTelemetryEventWithContext(ctx context.Context, eventType, eventlevel string, eventData map[string]interface{}) {
+	std.CaptureTelemetryEventWithContext(ctx, eventType, eventlevel, eventData)
+}
+
+// RecordNavigationTelemetry captures a navigation telemetry event from one state to another on the
+// managed Client instance.
+func RecordNavigationTelemetry(from, to string) {
+	std.RecordNavigationTelemetry(from, to)
+}
+
+// RecordConnectivityTelemetry captures a connectivity telemetry event recording that the
+// application went online or offline on the managed Client instance.
+func RecordConnectivityTelemetry(online bool) {
+	std.RecordConnectivityTelemetry(online)
+}
+
+// RecordLogTelemetry captures a log telemetry event with the given level and message on the
+// managed Client instance.
+func RecordLogTelemetry(level, message string) {
+	std.RecordLogTelemetry(level, message)
+}
+
+// RecordNetworkTelemetry captures a network telemetry event for a request and the status code of
+// its response on the managed Client instance. See Client.RecordNetworkTelemetry.
+func RecordNetworkTelemetry(method, url string, status int) {
+	std.RecordNetworkTelemetry(method, url, status)
+}
+
+// RecordManualTelemetry captures a telemetry event of the given type on the managed Client
+// instance. See Client.RecordManualTelemetry.
+func RecordManualTelemetry(eventType string, body map[string]interface{}) {
+	std.RecordManualTelemetry(eventType, body)
+}
+
+// TelemetryWriter returns an io.Writer recording each write as a log telemetry event of the
+// managed Client instance. See Client.TelemetryWriter.
+func TelemetryWriter(level string) io.Writer {
+	return std.TelemetryWriter(level)
+}
+
+// SetAttachTelemetry sets whether the managed Client instance attaches the captured telemetry events
+// to the reported items. See Client.SetAttachTelemetry.
+func SetAttachTelemetry(attach bool) {
+	std.SetAttachTelemetry(attach)
+}
+
+// SetTelemetryMaxEvents sets the maximum number of telemetry events attached to an item by the
+// managed Client instance. See Client.SetTelemetryMaxEvents.
+func SetTelemetryMaxEvents(max int) {
+	std.SetTelemetryMaxEvents(max)
+}
+
+// SetTelemetryLevel sets the minimum level of the telemetry events attached to items by the managed
+// Client instance. See Client.SetTelemetryLevel.
+func SetTelemetryLevel(level string) {
+	std.SetTelemetryLevel(level)
+}
+
 // SetEnabled sets whether or not the managed Client instance is enabled.
 // If this is true then this library works as normal.
 // If this is false then no calls will be made to the network.
@@ -121,6 +210,12 @@ func SetEnabled(enabled bool) {
 	std.SetEnabled(enabled)
 }
 
+// ApplyOptions replaces the configuration of the managed Client instance in a single step. See
+// Client.ApplyOptions.
+func ApplyOptions(opts ...Option) {
+	std.ApplyOptions(opts...)
+}
+
 // SetToken sets the token on the managed Client instance. The value is a Rollbar access token
 // with scope "post_server_item". It is required to set this value before any of the other
 // functions herein will be able to work properly.
@@ -134,6 +229,18 @@ func SetEnvironment(environment string) {
 	std.SetEnvironment(environment)
 }
 
+// SetEnabledEnvironments sets the environments in which the managed Client instance reports
+// items. See Client.SetEnabledEnvironments.
+func SetEnabledEnvironments(environments []string) {
+	std.SetEnabledEnvironments(environments)
+}
+
+// SetContextName sets the name of the operation reported as data.context by the managed Client
+// instance. See Client.SetContextName.
+func SetContextName(name string) {
+	std.SetContextName(name)
+}
+
 // SetEndpoint sets the endpoint on the managed Client instance.
 // The endpoint to post items to.
 // The default value is https://api.rollbar.com/api/1/item/
@@ -146,6 +253,30 @@ func SetItemsPerMinute(itemsPerMinute int) {
 	std.SetItemsPerMinute(itemsPerMinute)
 }
 
+// SetMaxItems sets the maximum number of items reported by the managed Client instance over the
+// lifetime of the process. See Client.SetMaxItems.
+func SetMaxItems(maxItems int) {
+	std.SetMaxItems(maxItems)
+}
+
+// SetItemsPerMinuteByLevel sets the max number of items of the given levels sent per minute by the
+// managed Client instance. See Client.SetItemsPerMinuteByLevel.
+func SetItemsPerMinuteByLevel(limits map[string]int) {
+	std.SetItemsPerMinuteByLevel(limits)
+}
+
+// SetStrict sets whether the transport of the managed Client instance surfaces the rejections of
+// items by the API with their message. See Client.SetStrict.
+func SetStrict(strict bool) {
+	std.SetStrict(strict)
+}
+
+// SetSendErrorHandler sets the function called with each item the managed Client instance could
+// not send. See Client.SetSendErrorHandler.
+func SetSendErrorHandler(handler SendErrorFunc) {
+	std.SetSendErrorHandler(handler)
+}
+
 // SetPlatform sets the platform on the managed Client instance.
 // The platform is reported for all Rollbar items. The default is
 // the running operating system (darwin, freebsd, linux, etc.) but it can
@@ -154,6 +285,12 @@ func SetPlatform(platform string) {
 	std.SetPlatform(platform)
 }
 
+// SetDiagnosticModules sets the paths of the modules whose versions are reported by the managed
+// Client instance. See Client.SetDiagnosticModules.
+func SetDiagnosticModules(paths ...string) {
+	std.SetDiagnosticModules(paths...)
+}
+
 // SetCodeVersion sets the code version on the managed Client instance.
 // The code version is a string describing the running code version on the server.
 func SetCodeVersion(codeVersion string) {
@@ -181,18 +318,112 @@ func SetCustom(custom map[string]interface{}) {
 
 // SetScrubHeaders sets the headers to scrub on the managed Client instance.
 // The value is a regular expression used to match headers for scrubbing.
-// The default value is regexp.MustCompile("Authorization").
+// The default value is regexp.MustCompile(DefaultScrubHeaders).
 func SetScrubHeaders(headers *regexp.Regexp) {
 	std.SetScrubHeaders(headers)
 }
 
 // SetScrubFields sets the fields to scrub on the managed Client instance.
 // The value is a regular expression to match keys in the item payload for scrubbing.
-// The default vlaue is regexp.MustCompile("password|secret|token").
+// The default value is regexp.MustCompile(DefaultScrubFields).
 func SetScrubFields(fields *regexp.Regexp) {
 	std.SetScrubFields(fields)
 }
 
+// SetAllowedHeaders sets the only headers reported by the managed Client instance, e.g.
+// "Content-Type" and "User-Agent". See Client.SetAllowedHeaders.
+func SetAllowedHeaders(headers ...string) {
+	std.SetAllowedHeaders(headers...)
+}
+
+// SetPersonScrubPolicy sets how the email and username of the person are reported by the managed
+// Client instance, e.g. HashEmail. See Client.SetPersonScrubPolicy.
+func SetPersonScrubPolicy(policy PersonScrubPolicy) {
+	std.SetPersonScrubPolicy(policy)
+}
+
+// SetScrubPII sets whether PII, such as email addresses and credit card numbers, is masked wherever
+// it is found in the items of the managed Client instance. See Client.SetScrubPII.
+func SetScrubPII(scrubPII bool) {
+	std.SetScrubPII(scrubPII)
+}
+
+// SetScrubPaths sets the paths of the fields scrubbed by the managed Client instance, e.g.
+// "request.body.card.number". See Client.SetScrubPaths.
+func SetScrubPaths(paths ...string) {
+	std.SetScrubPaths(paths...)
+}
+
+// SetScrubExemptFields sets the keys not scrubbed by the managed Client instance although they
+// match the fields to scrub. See Client.SetScrubExemptFields.
+func SetScrubExemptFields(keys ...string) {
+	std.SetScrubExemptFields(keys...)
+}
+
+// SetSigningKey sets the key used by the managed Client instance to sign payloads. See
+// Client.SetSigningKey.
+func SetSigningKey(key []byte) {
+	std.SetSigningKey(key)
+}
+
+// SetMetricsRecorder sets the recorder observing the managed Client instance and its transport.
+// See Client.SetMetricsRecorder.
+func SetMetricsRecorder(recorder MetricsRecorder) {
+	std.SetMetricsRecorder(recorder)
+}
+
+// SetTestMode sets whether the managed Client instance reports from tests, which prefixes the
+// environment of items with "test-". See Client.SetTestMode.
+func SetTestMode(testMode bool) {
+	std.SetTestMode(testMode)
+}
+
+// StartTest enables test mode on the managed Client instance and reports the name of the running
+// test with every item until it completes. See Client.StartTest.
+func StartTest(t TB) {
+	std.StartTest(t)
+}
+
+// SetCustomDigestThreshold sets the length above which values in custom data are replaced by a
+// digest by the managed Client instance. See Client.SetCustomDigestThreshold.
+func SetCustomDigestThreshold(threshold int) {
+	std.SetCustomDigestThreshold(threshold)
+}
+
+// SetClock sets the clock used by the managed Client instance. See Client.SetClock.
+func SetClock(clock Clock) {
+	std.SetClock(clock)
+}
+
+// SetIDGenerator sets the generator of the data.uuid of items reported by the managed Client
+// instance.
+func SetIDGenerator(generator IDGenerator) {
+	std.SetIDGenerator(generator)
+}
+
+// AddEnricher adds an enricher called for every item reported by the managed Client instance,
+// e.g. KubernetesEnricher.
+func AddEnricher(enricher EnricherFunc) {
+	std.AddEnricher(enricher)
+}
+
+// ClearEnrichers removes all enrichers added to the managed Client instance.
+func ClearEnrichers() {
+	std.ClearEnrichers()
+}
+
+// SetEscalationPolicy makes the managed Client instance promote items reported threshold times
+// within window to a higher level. See Client.SetEscalationPolicy.
+func SetEscalationPolicy(threshold int, window time.Duration) {
+	std.SetEscalationPolicy(threshold, window)
+}
+
+// SetScrubSecrets sets whether secrets embedded in free text, such as bearer tokens wrapped into
+// error messages, are masked by the managed Client instance. See Client.SetScrubSecrets.
+func SetScrubSecrets(scrubSecrets bool) {
+	std.SetScrubSecrets(scrubSecrets)
+}
+
 // SetTransform sets the transform function called after the entire payload has been built before it
 // is sent to the API.
 // The structure of the final payload sent to the API is:
@@ -230,6 +461,26 @@ func SetStackTracer(stackTracer StackTracerFunc) {
 	std.SetStackTracer(stackTracer)
 }
 
+// SetSkipPresets sets the SkipPresets used by the managed Client instance to drop the leading
+// frames of the wrappers reporting to Rollbar from stack traces. See Client.SetSkipPresets.
+func SetSkipPresets(presets ...SkipPreset) {
+	std.SetSkipPresets(presets...)
+}
+
+// SetRequestExtractor sets the RequestExtractorFunc used by the managed Client instance. It allows
+// Log and the level functions (Critical, Error, etc.) to accept the request types of web
+// frameworks which do not use *http.Request. See the documentation of RequestExtractorFunc for
+// more details.
+func SetRequestExtractor(extractor RequestExtractorFunc) {
+	std.SetRequestExtractor(extractor)
+}
+
+// RegisterContextValue registers a key of context values reported under the given name in
+// custom.context_values by the managed Client instance. See Client.RegisterContextValue.
+func RegisterContextValue(name string, key interface{}) {
+	std.RegisterContextValue(name, key)
+}
+
 // SetCheckIgnore sets the checkIgnore function on the managed Client instance.
 // CheckIgnore is called during the recovery process of a panic that
 // occurred inside a function wrapped by Wrap or WrapAndWait.
@@ -241,6 +492,12 @@ func SetCheckIgnore(checkIgnore func(string) bool) {
 	std.SetCheckIgnore(checkIgnore)
 }
 
+// SetErrorTagger sets the ErrorTaggerFunc used by the managed Client instance to categorize the
+// reported errors. See Client.SetErrorTagger.
+func SetErrorTagger(tagger ErrorTaggerFunc) {
+	std.SetErrorTagger(tagger)
+}
+
 // SetPerson information for identifying a user associated with
 // any subsequent errors or messages. Only id is required to be
 // non-empty.
@@ -253,6 +510,54 @@ func ClearPerson() {
 	std.ClearPerson()
 }
 
+// SetScrubber sets the Scrubber applied by the managed Client instance to every section of the
+// items. See Client.SetScrubber.
+func SetScrubber(scrubber Scrubber) {
+	std.SetScrubber(scrubber)
+}
+
+// SetMessageScrubber sets the MessageScrubberFunc applied by the managed Client instance to the
+// titles, exception messages and message bodies of the items. See Client.SetMessageScrubber.
+func SetMessageScrubber(scrubber MessageScrubberFunc) {
+	std.SetMessageScrubber(scrubber)
+}
+
+// AddIgnoreError makes the managed Client instance ignore the errors matching target. See
+// Client.AddIgnoreError.
+func AddIgnoreError(target error) {
+	std.AddIgnoreError(target)
+}
+
+// AddIgnoreErrorType makes the managed Client instance ignore the errors of the same type as
+// sample. See Client.AddIgnoreErrorType.
+func AddIgnoreErrorType(sample error) {
+	std.AddIgnoreErrorType(sample)
+}
+
+// AddIgnoreMessagePattern makes the managed Client instance ignore the errors and messages whose
+// text matches pattern. See Client.AddIgnoreMessagePattern.
+func AddIgnoreMessagePattern(pattern *regexp.Regexp) {
+	std.AddIgnoreMessagePattern(pattern)
+}
+
+// ClearIgnores removes the errors, error types and message patterns ignored by the managed Client
+// instance.
+func ClearIgnores() {
+	std.ClearIgnores()
+}
+
+// SetLevelMapper sets the LevelMapperFunc used by the managed Client instance to choose the level
+// of the reported errors. See Client.SetLevelMapper.
+func SetLevelMapper(mapper LevelMapperFunc) {
+	std.SetLevelMapper(mapper)
+}
+
+// SetPersonProvider sets the PersonProviderFunc used by the managed Client instance to resolve the
+// person of the reported items. See Client.SetPersonProvider.
+func SetPersonProvider(provider PersonProviderFunc) {
+	std.SetPersonProvider(provider)
+}
+
 // SetFingerprint sets whether or not to use custom client-side fingerprinting on the managed Client
 // instance. This custom fingerprinting is based on a CRC32 checksum. The alternative is to let
 // the server compute a fingerprint for each item. The default is false.
@@ -266,6 +571,72 @@ func SetLogger(logger ClientLogger) {
 	std.SetLogger(logger)
 }
 
+// SetCaptureCookies sets whether the managed Client instance reports the cookies of requests. See
+// Client.SetCaptureCookies.
+func SetCaptureCookies(captureCookies bool) {
+	std.SetCaptureCookies(captureCookies)
+}
+
+// SetScrubCookies sets the regular expression used by the managed Client instance to match the
+// names of the cookies to scrub.
+func SetScrubCookies(cookies *regexp.Regexp) {
+	std.SetScrubCookies(cookies)
+}
+
+// SetEmptyItemPolicy sets how the managed Client instance handles nil errors and empty messages.
+// See Client.SetEmptyItemPolicy.
+func SetEmptyItemPolicy(policy emptyItemPolicy) {
+	std.SetEmptyItemPolicy(policy)
+}
+
+// SetTrustedProxies sets the proxies trusted by the managed Client instance to set the client IP
+// headers. See Client.SetTrustedProxies.
+func SetTrustedProxies(cidrs []string) error {
+	return std.SetTrustedProxies(cidrs)
+}
+
+// SetQueuePriority reserves the last reserve slots of the queue of the managed Client instance for
+// the items of the given level or higher. See Client.SetQueuePriority.
+func SetQueuePriority(reserve int, level string) {
+	std.SetQueuePriority(reserve, level)
+}
+
+// SetDropPolicy sets what the transport of the managed Client instance does with the items
+// reported while its queue is full. See Client.SetDropPolicy.
+func SetDropPolicy(policy DropPolicy, timeout time.Duration) {
+	std.SetDropPolicy(policy, timeout)
+}
+
+// SetOfflineMode replaces the transport of the managed Client instance by a SpoolTransport writing
+// the items to files in dir instead of sending them. See Client.SetOfflineMode.
+func SetOfflineMode(dir string) error {
+	return std.SetOfflineMode(dir)
+}
+
+// ReplaySpool sends the items written to dir by a SpoolTransport with the token and endpoint of the
+// managed Client instance. See Client.ReplaySpool.
+func ReplaySpool(ctx context.Context, dir string) error {
+	return std.ReplaySpool(ctx, dir)
+}
+
+// SetTrustForwardedHeaders sets whether the managed Client instance builds the url of requests from
+// the forwarding headers of trusted proxies. See Client.SetTrustForwardedHeaders.
+func SetTrustForwardedHeaders(trust bool) {
+	std.SetTrustForwardedHeaders(trust)
+}
+
+// SetClientIPHeaders sets the headers from which the managed Client instance takes the client IP
+// address of requests, in order of priority. See Client.SetClientIPHeaders.
+func SetClientIPHeaders(headers ...string) {
+	std.SetClientIPHeaders(headers...)
+}
+
+// SetCorrelationHeaders sets the headers from which the managed Client instance takes the
+// correlation ID of requests, in order of priority. See Client.SetCorrelationHeaders.
+func SetCorrelationHeaders(headers ...string) {
+	std.SetCorrelationHeaders(headers...)
+}
+
 // SetCaptureIp sets what level of IP address information to capture from requests.
 // CaptureIpFull means capture the entire address without any modification.
 // CaptureIpAnonymize means apply a pseudo-anonymization.
@@ -274,6 +645,12 @@ func SetCaptureIp(captureIp captureIp) {
 	std.SetCaptureIp(captureIp)
 }
 
+// SetIPAnonymizationMask sets how many leading bits of the IPv4 and IPv6 addresses are kept when
+// they are anonymized by the managed Client instance. See Client.SetIPAnonymizationMask.
+func SetIPAnonymizationMask(ipv4Bits, ipv6Bits int) error {
+	return std.SetIPAnonymizationMask(ipv4Bits, ipv6Bits)
+}
+
 // SetRetryAttempts sets how many times to attempt to retry sending an item if the http transport
 // experiences temporary error conditions. By default this is equal to DefaultRetryAttempts.
 // Temporary errors include timeouts and rate limit responses.
@@ -290,6 +667,13 @@ func SetPrintPayloadOnError(printPayloadOnError bool) {
 	std.SetPrintPayloadOnError(printPayloadOnError)
 }
 
+// SetCompressPayloadOnError sets whether the transport of the managed Client instance writes the
+// payloads output because of an error gzip compressed and base64 encoded. See
+// Client.SetCompressPayloadOnError.
+func SetCompressPayloadOnError(compress bool) {
+	std.SetCompressPayloadOnError(compress)
+}
+
 // SetHTTPClient sets custom http Client. http.DefaultClient is used by default
 func SetHTTPClient(httpClient *http.Client) {
 	std.SetHTTPClient(httpClient)
@@ -307,6 +691,17 @@ func Environment() string {
 	return std.Environment()
 }
 
+// EnabledEnvironments returns the environments in which the managed Client instance reports items.
+func EnabledEnvironments() []string {
+	return std.EnabledEnvironments()
+}
+
+// ContextName is the name of the operation reported as data.context currently set on the managed
+// Client instance.
+func ContextName() string {
+	return std.ContextName()
+}
+
 // Endpoint is the currently configured endpoint to send items on the managed Client instance.
 func Endpoint() string {
 	return std.Endpoint()
@@ -319,6 +714,12 @@ func Platform() string {
 	return std.Platform()
 }
 
+// DiagnosticModules returns the paths of the modules whose versions are reported by the managed
+// Client instance.
+func DiagnosticModules() []string {
+	return std.DiagnosticModules()
+}
+
 // CodeVersion is the string describing the running code version on the server that is currently set
 // on the managed Client instance.
 func CodeVersion() string {
@@ -349,11 +750,169 @@ func Fingerprint() bool {
 	return std.Fingerprint()
 }
 
+// ScrubHeaders is the currently set regular expression used by the managed Client instance to
+// match headers for scrubbing.
+func ScrubHeaders() *regexp.Regexp {
+	return std.ScrubHeaders()
+}
+
+// ScrubFields is the currently set regular expression used by the managed Client instance to
+// match keys in the item payload for scrubbing.
+func ScrubFields() *regexp.Regexp {
+	return std.ScrubFields()
+}
+
+// AllowedHeaders returns the only headers reported by the managed Client instance.
+func AllowedHeaders() []string {
+	return std.AllowedHeaders()
+}
+
+// ScrubPaths returns the paths of the fields scrubbed by the managed Client instance.
+func ScrubPaths() []string {
+	return std.ScrubPaths()
+}
+
+// ScrubExemptFields returns the keys exempted from scrubbing by the managed Client instance.
+func ScrubExemptFields() []string {
+	return std.ScrubExemptFields()
+}
+
+// TestMode specifies whether or not the managed Client instance reports from tests.
+func TestMode() bool {
+	return std.TestMode()
+}
+
+// Config returns a snapshot of the effective configuration of the managed Client instance, with
+// the access token redacted.
+func Config() ConfigSnapshot {
+	return std.Config()
+}
+
+// EscalationPolicy is the currently set escalation policy of the managed Client instance.
+func EscalationPolicy() (threshold int, window time.Duration) {
+	return std.EscalationPolicy()
+}
+
+// TransportMetrics returns the metrics of the transport of the managed Client instance. See
+// Client.TransportMetrics.
+func TransportMetrics() TransportStats {
+	return std.TransportMetrics()
+}
+
+// Events returns the channel of the events of the items reported by the managed Client instance.
+// See Client.Events.
+func Events() <-chan Event {
+	return std.Events()
+}
+
+// EmptyItemPolicy is the currently set policy for nil errors and empty messages on the managed
+// Client instance.
+func EmptyItemPolicy() emptyItemPolicy {
+	return std.EmptyItemPolicy()
+}
+
+// EmptyItemCallSites returns the number of nil errors and empty messages reported to the managed
+// Client instance, by call site. See Client.EmptyItemCallSites.
+func EmptyItemCallSites() map[string]int {
+	return std.EmptyItemCallSites()
+}
+
+// TrustedProxies are the currently set trusted proxies of the managed Client instance.
+func TrustedProxies() []string {
+	return std.TrustedProxies()
+}
+
+// ClientIPHeaders are the currently set headers holding the client IP address of requests on the
+// managed Client instance.
+func ClientIPHeaders() []string {
+	return std.ClientIPHeaders()
+}
+
+// CorrelationHeaders are the currently set headers holding the correlation ID of requests on the
+// managed Client instance.
+func CorrelationHeaders() []string {
+	return std.CorrelationHeaders()
+}
+
+// CaptureCookies specifies whether the managed Client instance reports the cookies of requests.
+func CaptureCookies() bool {
+	return std.CaptureCookies()
+}
+
+// ScrubCookies is the currently set regular expression matching the cookies to scrub on the
+// managed Client instance.
+func ScrubCookies() *regexp.Regexp {
+	return std.ScrubCookies()
+}
+
 // CaptureIp is the currently set level of IP address information to capture from requests.
 func CaptureIp() captureIp {
 	return std.CaptureIp()
 }
 
+// CustomQueueSize is the size of the telemetry queue of the managed Client instance.
+// It can be changed by calling SetTelemetry with the SetCustomQueueSize option.
+func CustomQueueSize() int {
+	return std.CustomQueueSize()
+}
+
+// NetworkTelemetryEnabled is whether the managed Client instance captures network telemetry.
+// It can be enabled by calling SetTelemetry with the EnableNetworkTelemetry option.
+func NetworkTelemetryEnabled() bool {
+	return std.NetworkTelemetryEnabled()
+}
+
+// NetworkTelemetryRequestHeadersEnabled is whether the managed Client instance captures request
+// headers with network telemetry. It can be enabled by calling SetTelemetry with the
+// EnableNetworkTelemetryRequestHeaders option.
+func NetworkTelemetryRequestHeadersEnabled() bool {
+	return std.NetworkTelemetryRequestHeadersEnabled()
+}
+
+// NetworkTelemetryResponseHeadersEnabled is whether the managed Client instance captures response
+// headers with network telemetry. It can be enabled by calling SetTelemetry with the
+// EnableNetworkTelemetryResponseHeaders option.
+func NetworkTelemetryResponseHeadersEnabled() bool {
+	return std.NetworkTelemetryResponseHeadersEnabled()
+}
+
+// LoggerTelemetryEnabled is whether the managed Client instance captures the output of the
+// standard logger as telemetry. It can be enabled by calling SetTelemetry with the
+// EnableLoggerTelemetry option.
+func LoggerTelemetryEnabled() bool {
+	return std.LoggerTelemetryEnabled()
+}
+
+// AttachTelemetry is whether the managed Client instance currently attaches telemetry events to the
+// reported items.
+func AttachTelemetry() bool {
+	return std.AttachTelemetry()
+}
+
+// TelemetryMaxEvents is the maximum number of telemetry events attached to an item currently set
+// on the managed Client instance.
+func TelemetryMaxEvents() int {
+	return std.TelemetryMaxEvents()
+}
+
+// MaxItems is the maximum number of items reported over the lifetime of the process currently set
+// on the managed Client instance.
+func MaxItems() int {
+	return std.MaxItems()
+}
+
+// ItemsPerMinuteByLevel is the max number of items of the given levels sent per minute currently
+// set on the managed Client instance.
+func ItemsPerMinuteByLevel() map[string]int {
+	return std.ItemsPerMinuteByLevel()
+}
+
+// TelemetryLevel is the minimum level of the telemetry events attached to items currently set on
+// the managed Client instance.
+func TelemetryLevel() string {
+	return std.TelemetryLevel()
+}
+
 // -- Reporting
 
 // Critical reports an item with level `critical`. This function recognizes arguments with the following types:
@@ -431,6 +990,49 @@ func Debug(interfaces ...interface{}) {
 	Log(DEBUG, interfaces...)
 }
 
+// CriticalAndWait reports an item with level `critical` like Critical, and waits until the items
+// of the managed Client instance have been sent, like Wait. This is meant for programs which exit
+// right after reporting, such as command-line tools and batch jobs.
+func CriticalAndWait(interfaces ...interface{}) {
+	Log(CRIT, interfaces...)
+	Wait()
+}
+
+// ErrorAndWait reports an item with level `error` like Error, and waits until it is sent. See
+// CriticalAndWait.
+func ErrorAndWait(interfaces ...interface{}) {
+	Log(ERR, interfaces...)
+	Wait()
+}
+
+// WarningAndWait reports an item with level `warning` like Warning, and waits until it is sent.
+// See CriticalAndWait.
+func WarningAndWait(interfaces ...interface{}) {
+	Log(WARN, interfaces...)
+	Wait()
+}
+
+// InfoAndWait reports an item with level `info` like Info, and waits until it is sent. See
+// CriticalAndWait.
+func InfoAndWait(interfaces ...interface{}) {
+	Log(INFO, interfaces...)
+	Wait()
+}
+
+// DebugAndWait reports an item with level `debug` like Debug, and waits until it is sent. See
+// CriticalAndWait.
+func DebugAndWait(interfaces ...interface{}) {
+	Log(DEBUG, interfaces...)
+	Wait()
+}
+
+// LogAndWait reports an item with the given level like Log, and waits until it is sent. See
+// CriticalAndWait.
+func LogAndWait(level string, interfaces ...interface{}) {
+	Log(level, interfaces...)
+	Wait()
+}
+
 // Log reports an item with the given level. This function recognizes arguments with the following types:
 //    *http.Request
 //    error
@@ -443,7 +1045,8 @@ func Debug(interfaces ...interface{}) {
 // that number of stack frames. If the map is present it is used as extra custom data in the
 // item. If a string is present without an error, then we log a message without a stack
 // trace. If a request is present we extract as much relevant information from it as we can. If
-// a context is present, it is applied to downstream operations.
+// a context is present, it is applied to downstream operations. Arguments of any other type are
+// passed to the RequestExtractorFunc set with SetRequestExtractor, if there is one.
 func Log(level string, interfaces ...interface{}) {
 	var r *http.Request
 	var err error
@@ -451,6 +1054,7 @@ func Log(level string, interfaces ...interface{}) {
 	skipSet := false
 	var extras map[string]interface{}
 	var msg string
+	var info *RequestInfo
 	ctx := context.TODO()
 	for _, ival := range interfaces {
 		switch val := ival.(type) {
@@ -468,9 +1072,20 @@ func Log(level string, interfaces ...interface{}) {
 		case context.Context:
 			ctx = val
 		default:
-			rollbarError(std.Transport.(*AsyncTransport).Logger, "Unknown input type: %T", val)
+			if i, ok := std.requestInfoFrom(val); ok {
+				info = i
+				continue
+			}
+			var logger ClientLogger
+			if p, ok := std.Transport.(loggerProvider); ok {
+				logger = p.getLogger()
+			}
+			rollbarError(logger, "Unknown input type: %T", val)
 		}
 	}
+	if info != nil {
+		ctx = NewRequestInfoContext(ctx, info)
+	}
 	if !skipSet {
 		skip = 2
 	}
@@ -513,6 +1128,13 @@ func ErrorWithExtrasAndContext(ctx context.Context, level string, err error, ext
 	std.ErrorWithExtrasAndContext(ctx, level, err, extras)
 }
 
+// ErrorWithExtrasCtx asynchronously sends an error to Rollbar with the given severity level with
+// extra custom data, within the given context, and returns the UUID of the item. See
+// Client.ErrorWithExtrasCtx.
+func ErrorWithExtrasCtx(ctx context.Context, level string, err error, extras map[string]interface{}) string {
+	return std.ErrorWithExtrasCtx(ctx, level, err, extras)
+}
+
 // RequestError asynchronously sends an error to Rollbar with the given
 // severity level and request-specific information.
 func RequestError(level string, r *http.Request, err error) {
@@ -531,6 +1153,13 @@ func RequestErrorWithExtrasAndContext(ctx context.Context, level string, r *http
 	std.RequestErrorWithExtrasAndContext(ctx, level, r, err, extras)
 }
 
+// RequestErrorWithExtrasCtx asynchronously sends an error to Rollbar with the given severity level
+// and request-specific information with extra custom data, within the given context, and returns
+// the UUID of the item. See Client.ErrorWithExtrasCtx.
+func RequestErrorWithExtrasCtx(ctx context.Context, level string, r *http.Request, err error, extras map[string]interface{}) string {
+	return std.RequestErrorWithExtrasCtx(ctx, level, r, err, extras)
+}
+
 // ErrorWithStackSkip asynchronously sends an error to Rollbar with the given
 // severity level and a given number of stack trace frames skipped.
 func ErrorWithStackSkip(level string, err error, skip int) {
@@ -591,6 +1220,13 @@ func MessageWithExtrasAndContext(ctx context.Context, level string, msg string,
 	std.MessageWithExtrasAndContext(ctx, level, msg, extras)
 }
 
+// MessageWithExtrasCtx asynchronously sends a message to Rollbar with the given severity level with
+// extra custom data, within the given context, and returns the UUID of the item. See
+// Client.ErrorWithExtrasCtx.
+func MessageWithExtrasCtx(ctx context.Context, level string, msg string, extras map[string]interface{}) string {
+	return std.MessageWithExtrasCtx(ctx, level, msg, extras)
+}
+
 // RequestMessage asynchronously sends a message to Rollbar with the given
 // severity level and request-specific information.
 func RequestMessage(level string, r *http.Request, msg string) {
@@ -611,6 +1247,25 @@ func RequestMessageWithExtrasAndContext(ctx context.Context, level string, r *ht
 	std.RequestMessageWithExtrasAndContext(ctx, level, r, msg, extras)
 }
 
+// RequestMessageWithExtrasCtx asynchronously sends a message to Rollbar with the given severity
+// level and request-specific information with extra custom data, within the given context, and
+// returns the UUID of the item. See Client.ErrorWithExtrasCtx.
+func RequestMessageWithExtrasCtx(ctx context.Context, level string, r *http.Request, msg string, extras map[string]interface{}) string {
+	return std.RequestMessageWithExtrasCtx(ctx, level, r, msg, extras)
+}
+
+// Warmup establishes the connection of the managed Client instance to the endpoint ahead of the
+// first item. See Client.Warmup.
+func Warmup(ctx context.Context) error {
+	return std.Warmup(ctx)
+}
+
+// ReportDeploy reports a deploy with the managed Client instance and returns its ID. See
+// Client.ReportDeploy.
+func ReportDeploy(ctx context.Context, deploy Deploy) (int64, error) {
+	return std.ReportDeploy(ctx, deploy)
+}
+
 // Wait will block until the queue of errors / messages is empty.
 func Wait() {
 	std.Wait()
@@ -622,12 +1277,26 @@ func Close() {
 	std.Close()
 }
 
+// StartHeartbeat starts reporting a heartbeat on the managed Client instance every interval until
+// Stop is called on the returned Heartbeat. See Client.StartHeartbeat for more information.
+func StartHeartbeat(interval time.Duration, opts ...heartbeatOption) *Heartbeat {
+	return std.StartHeartbeat(interval, opts...)
+}
+
 // LogPanic accepts an error value returned by recover() and
-// handles logging to Rollbar with stack info.
+// handles logging to Rollbar with stack info. Errors are reported as is,
+// keeping their type as the class of the item, and the stack trace starts
+// where the panic was raised. If wait is true, this also waits before
+// returning to ensure the item was reported, e.g. before resuming the panic.
 func LogPanic(err interface{}, wait bool) {
 	std.LogPanic(err, wait)
 }
 
+// LogPanicWithContext is LogPanic within the given context, see Client.LogPanicWithContext.
+func LogPanicWithContext(ctx context.Context, err interface{}, wait bool) {
+	std.LogPanicWithContext(ctx, err, wait)
+}
+
 // WrapWithArgs calls f with the supplied args and reports a panic to Rollbar if it occurs.
 // If wait is true, this also waits before returning to ensure the message was reported.
 // If an error is captured it is subsequently returned.
@@ -642,6 +1311,26 @@ func Wrap(f interface{}, args ...interface{}) interface{} {
 	return std.WrapWithArgs(f, false, args...)
 }
 
+// WrapWithContext calls f with the supplied args and reports a panic to Rollbar within ctx if it
+// occurs, passing ctx to f if its first parameter is a context.Context not among args.
+// If an error is captured it is subsequently returned.
+func WrapWithContext(ctx context.Context, f interface{}, args ...interface{}) interface{} {
+	return std.WrapWithContext(ctx, f, args...)
+}
+
+// Recover recovers and reports a panic of the function deferring it to Rollbar within ctx, and
+// then swallows or resumes the panic according to policy, see Client.Recover. It must be deferred
+// directly: defer rollbar.Recover(ctx, rollbar.RepanicPolicy).
+func Recover(ctx context.Context, policy RecoverPolicy) {
+	std.reportRecovered(ctx, recover(), policy)
+}
+
+// Go calls f in a new goroutine, and recovers and reports a panic to Rollbar if it occurs, see
+// Client.Go.
+func Go(f func(), opts ...GoOption) {
+	std.Go(f, opts...)
+}
+
 // WrapAndWait calls f, and recovers and reports a panic to Rollbar if it occurs.
 // This also waits before returning to ensure the message was reported.
 // If an error is captured it is subsequently returned.
@@ -652,8 +1341,9 @@ func WrapAndWait(f interface{}, args ...interface{}) interface{} {
 // LambdaWrapper calls handlerFunc with arguments, and recovers and reports a
 // panic to Rollbar if it occurs. This functions as a passthrough wrapper for
 // lambda.Start(). This also waits before returning to ensure all messages completed.
-func LambdaWrapper(handlerFunc interface{}) interface{} {
-	return std.LambdaWrapper(handlerFunc)
+// See Client.LambdaWrapper for the Lambda details reported with each invocation.
+func LambdaWrapper(handlerFunc interface{}, opts ...LambdaOption) interface{} {
+	return std.LambdaWrapper(handlerFunc, opts...)
 }
 
 // Stacker is an interface that errors can implement to allow the extraction of stack traces.
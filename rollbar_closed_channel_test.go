@@ -6,7 +6,7 @@ import (
 
 func TestDisableDefaultClient(t *testing.T) {
 	defer func() {
-		std = NewAsync("", "development", "", hostname, "")
+		std = NewAsync("", "development", "", "", "")
 
 	}()
 	DisableDefaultClient(false)
@@ -0,0 +1,181 @@
+// Package rollbarapi provides a client for the read endpoints of the Rollbar API, so that tools
+// written in Go can query the items and occurrences reported by the rollbar package, and run RQL
+// queries with RunRQL:
+//
+//	api := rollbarapi.New(os.Getenv("ROLLBAR_READ_TOKEN"))
+//	item, err := api.ItemByCounter(ctx, 42)
+//	if err != nil {
+//		return err
+//	}
+//	occurrences, err := api.Occurrences(ctx, item.ID, 1)
+//
+// The access token needs the "read" scope, unlike the token used to report items.
+package rollbarapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// DefaultBaseURL is the base URL of the Rollbar API.
+const DefaultBaseURL = "https://api.rollbar.com/api/1"
+
+// Client queries the read endpoints of the Rollbar API.
+type Client struct {
+	// Token is a project access token with the "read" scope.
+	Token string
+	// BaseURL is the base URL of the API, DefaultBaseURL by default.
+	BaseURL string
+	// HTTPClient is the client used for the requests, http.DefaultClient if nil.
+	HTTPClient *http.Client
+}
+
+// New returns a Client using the given read access token.
+func New(token string) *Client {
+	return &Client{Token: token, BaseURL: DefaultBaseURL}
+}
+
+// Item is an item of a project: a group of occurrences of the same error or message.
+type Item struct {
+	ID               int64  `json:"id"`
+	ProjectID        int64  `json:"project_id"`
+	Counter          int64  `json:"counter"`
+	Environment      string `json:"environment"`
+	Title            string `json:"title"`
+	Level            string `json:"level"`
+	Status           string `json:"status"`
+	Framework        string `json:"framework"`
+	TotalOccurrences int64  `json:"total_occurrences"`
+	// FirstOccurrenceTimestamp and LastOccurrenceTimestamp are Unix timestamps in seconds.
+	FirstOccurrenceTimestamp int64 `json:"first_occurrence_timestamp"`
+	LastOccurrenceTimestamp  int64 `json:"last_occurrence_timestamp"`
+	LastOccurrenceID         int64 `json:"last_occurrence_id"`
+}
+
+// Occurrence is an occurrence of an item, called an instance by the API.
+type Occurrence struct {
+	ID     int64 `json:"id"`
+	ItemID int64 `json:"item_id"`
+	// Timestamp is a Unix timestamp in seconds.
+	Timestamp int64 `json:"timestamp"`
+	// Data is the data of the reported payload, as sent by the rollbar package.
+	Data map[string]interface{} `json:"data"`
+}
+
+// UUID returns the UUID of the occurrence, as reported in its data.
+func (o *Occurrence) UUID() string {
+	uuid, _ := o.Data["uuid"].(string)
+	return uuid
+}
+
+// Error is the error returned when the API responds with an error.
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("rollbarapi: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("rollbarapi: %d %s", e.StatusCode, e.Message)
+}
+
+// ItemByCounter returns the item with the given counter, the number shown in the web interface,
+// e.g. 42 for "#42".
+func (c *Client) ItemByCounter(ctx context.Context, counter int64) (*Item, error) {
+	var item Item
+	if err := c.get(ctx, "/item_by_counter/"+strconv.FormatInt(counter, 10), nil, &item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// Item returns the item with the given ID.
+func (c *Client) Item(ctx context.Context, id int64) (*Item, error) {
+	var item Item
+	if err := c.get(ctx, "/item/"+strconv.FormatInt(id, 10), nil, &item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// Occurrences returns a page of the occurrences of the item with the given ID, most recent first.
+// Pages start at 1; an empty page is returned past the last one.
+func (c *Client) Occurrences(ctx context.Context, itemID int64, page int) ([]Occurrence, error) {
+	var result struct {
+		Instances []Occurrence `json:"instances"`
+	}
+	query := url.Values{"page": {strconv.Itoa(page)}}
+	if err := c.get(ctx, "/item/"+strconv.FormatInt(itemID, 10)+"/instances", query, &result); err != nil {
+		return nil, err
+	}
+	return result.Instances, nil
+}
+
+// Occurrence returns the occurrence with the given ID or UUID, such as the UUID generated by the
+// rollbar package for an item.
+func (c *Client) Occurrence(ctx context.Context, id string) (*Occurrence, error) {
+	var occurrence Occurrence
+	if err := c.get(ctx, "/instance/"+url.PathEscape(id), nil, &occurrence); err != nil {
+		return nil, err
+	}
+	return &occurrence, nil
+}
+
+func (c *Client) baseURL() string {
+	if c.BaseURL == "" {
+		return DefaultBaseURL
+	}
+	return c.BaseURL
+}
+
+// get sends a GET request to the given path of the API and decodes the result of the response into
+// v. Redirects, which the API uses e.g. from counters to items, are followed by the HTTP client.
+func (c *Client) get(ctx context.Context, path string, query url.Values, v interface{}) error {
+	u := c.baseURL() + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, req, v)
+}
+
+// do sends the request to the API with the access token and decodes the result of the response
+// into v.
+func (c *Client) do(ctx context.Context, req *http.Request, v interface{}) error {
+	req.Header.Set("X-Rollbar-Access-Token", c.Token)
+	req.Header.Set("Accept", "application/json")
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Err     int             `json:"err"`
+		Message string          `json:"message"`
+		Result  json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil && resp.StatusCode == http.StatusOK {
+		return err
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode != http.StatusOK || body.Err != 0 {
+		return &Error{StatusCode: resp.StatusCode, Message: body.Message}
+	}
+	return json.Unmarshal(body.Result, v)
+}
@@ -0,0 +1,73 @@
+package rollbarapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func testServer(t *testing.T) *Client {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/1/item_by_counter/42", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/api/1/item/1001", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/api/1/item/1001", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Rollbar-Access-Token") != "read-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"err": 1, "message": "invalid access token"}`))
+			return
+		}
+		w.Write([]byte(`{"err": 0, "result": {"id": 1001, "counter": 42, "title": "boom", "level": "error", "total_occurrences": 3}}`))
+	})
+	mux.HandleFunc("/api/1/item/1001/instances", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") != "2" {
+			t.Error("wrong page, got:", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"err": 0, "result": {"page": 2, "instances": [{"id": 7, "item_id": 1001, "data": {"uuid": "u-1"}}]}}`))
+	})
+	mux.HandleFunc("/api/1/instance/u-1", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"err": 0, "result": {"id": 7, "item_id": 1001, "timestamp": 1700000000, "data": {"uuid": "u-1"}}}`))
+	})
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	client := New("read-token")
+	client.BaseURL = ts.URL + "/api/1"
+	return client
+}
+
+func TestItemByCounter(t *testing.T) {
+	client := testServer(t)
+	item, err := client.ItemByCounter(context.Background(), 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.ID != 1001 || item.Counter != 42 || item.Title != "boom" || item.TotalOccurrences != 3 {
+		t.Error("wrong item, got:", item)
+	}
+
+	client.Token = "bad"
+	_, err = client.Item(context.Background(), 1001)
+	if apiErr, ok := err.(*Error); !ok || apiErr.StatusCode != http.StatusUnauthorized || apiErr.Message != "invalid access token" {
+		t.Error("expected an API error, got:", err)
+	}
+}
+
+func TestOccurrences(t *testing.T) {
+	client := testServer(t)
+	occurrences, err := client.Occurrences(context.Background(), 1001, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(occurrences) != 1 || occurrences[0].ID != 7 || occurrences[0].UUID() != "u-1" {
+		t.Error("wrong occurrences, got:", occurrences)
+	}
+
+	occurrence, err := client.Occurrence(context.Background(), "u-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if occurrence.ItemID != 1001 || occurrence.Timestamp != 1700000000 {
+		t.Error("wrong occurrence, got:", occurrence)
+	}
+}
@@ -0,0 +1,142 @@
+package rollbarapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// The statuses of RQL jobs.
+const (
+	RQLStatusNew       = "new"
+	RQLStatusRunning   = "running"
+	RQLStatusSuccess   = "success"
+	RQLStatusFailed    = "failed"
+	RQLStatusCancelled = "cancelled"
+	RQLStatusTimedOut  = "timed_out"
+)
+
+// RQLJob is a job running an RQL query.
+type RQLJob struct {
+	ID          int64  `json:"id"`
+	ProjectID   int64  `json:"project_id"`
+	QueryString string `json:"query_string"`
+	// Status is one of the RQLStatus constants.
+	Status string `json:"status"`
+}
+
+// Done returns whether the job is over, successfully or not.
+func (j *RQLJob) Done() bool {
+	return j.Status != RQLStatusNew && j.Status != RQLStatusRunning
+}
+
+// RQLResult is the result of an RQL job: the rows selected by the query, with their values in the
+// order of the columns.
+type RQLResult struct {
+	Columns []string        `json:"columns"`
+	Rows    [][]interface{} `json:"rows"`
+	// Errors are the errors of the query, if any.
+	Errors []string `json:"errors"`
+}
+
+// Maps returns the rows as maps from the column names to the values.
+func (r *RQLResult) Maps() []map[string]interface{} {
+	maps := make([]map[string]interface{}, len(r.Rows))
+	for i, row := range r.Rows {
+		m := make(map[string]interface{}, len(r.Columns))
+		for j, column := range r.Columns {
+			if j < len(row) {
+				m[column] = row[j]
+			}
+		}
+		maps[i] = m
+	}
+	return maps
+}
+
+// Decode stores the rows in v, a pointer to a slice of structs or maps, as encoding/json would
+// for an array of objects keyed by the column names, e.g.
+//
+//	var rows []struct {
+//		Environment string `json:"environment"`
+//		Count       int    `json:"count(*)"`
+//	}
+//	err := result.Decode(&rows)
+func (r *RQLResult) Decode(v interface{}) error {
+	b, err := json.Marshal(r.Maps())
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// CreateRQLJob starts a job running the RQL query. Unless forceRefresh is true, the API may reuse
+// the result of a recent job running the same query.
+func (c *Client) CreateRQLJob(ctx context.Context, query string, forceRefresh bool) (*RQLJob, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"query_string":  query,
+		"force_refresh": forceRefresh,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("POST", c.baseURL()+"/rql/jobs", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	var job RQLJob
+	if err := c.do(ctx, req, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// RQLJob returns the job with the given ID, whose status tells whether its result is available.
+func (c *Client) RQLJob(ctx context.Context, id int64) (*RQLJob, error) {
+	var job RQLJob
+	if err := c.get(ctx, "/rql/job/"+strconv.FormatInt(id, 10), nil, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// RQLJobResult returns the result of the job with the given ID, once it succeeded.
+func (c *Client) RQLJobResult(ctx context.Context, id int64) (*RQLResult, error) {
+	var result struct {
+		Result RQLResult `json:"result"`
+	}
+	if err := c.get(ctx, "/rql/job/"+strconv.FormatInt(id, 10)+"/result", nil, &result); err != nil {
+		return nil, err
+	}
+	return &result.Result, nil
+}
+
+// RunRQL runs the RQL query, polling the status of its job every interval until it is done, and
+// returns its result. An error is returned if the job does not succeed or ctx is done first.
+func (c *Client) RunRQL(ctx context.Context, query string, interval time.Duration) (*RQLResult, error) {
+	job, err := c.CreateRQLJob(ctx, query, false)
+	if err != nil {
+		return nil, err
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for !job.Done() {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+		if job, err = c.RQLJob(ctx, job.ID); err != nil {
+			return nil, err
+		}
+	}
+	if job.Status != RQLStatusSuccess {
+		return nil, fmt.Errorf("rollbarapi: RQL job %d %s", job.ID, job.Status)
+	}
+	return c.RQLJobResult(ctx, job.ID)
+}
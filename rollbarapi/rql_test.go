@@ -0,0 +1,69 @@
+package rollbarapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRunRQL(t *testing.T) {
+	polls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/1/rql/jobs", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if r.Method != "POST" || body["query_string"] != "select environment, count(*) from item_occurrence group by environment" {
+			t.Error("wrong job request, got:", r.Method, body)
+		}
+		w.Write([]byte(`{"err": 0, "result": {"id": 5, "status": "new"}}`))
+	})
+	mux.HandleFunc("/api/1/rql/job/5", func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		status := "running"
+		if polls > 1 {
+			status = "success"
+		}
+		w.Write([]byte(`{"err": 0, "result": {"id": 5, "status": "` + status + `"}}`))
+	})
+	mux.HandleFunc("/api/1/rql/job/5/result", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"err": 0, "result": {"job_id": 5, "result": {"columns": ["environment", "count(*)"], "rows": [["production", 12], ["staging", 3]]}}}`))
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	client := New("read-token")
+	client.BaseURL = ts.URL + "/api/1"
+
+	result, err := client.RunRQL(context.Background(), "select environment, count(*) from item_occurrence group by environment", time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if polls != 2 {
+		t.Error("expected the job to be polled until done, got:", polls)
+	}
+	var rows []struct {
+		Environment string `json:"environment"`
+		Count       int    `json:"count(*)"`
+	}
+	if err := result.Decode(&rows); err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[0].Environment != "production" || rows[0].Count != 12 || rows[1].Count != 3 {
+		t.Error("wrong rows, got:", rows)
+	}
+}
+
+func TestRunRQLFailed(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"err": 0, "result": {"id": 6, "status": "failed"}}`))
+	}))
+	defer ts.Close()
+	client := New("read-token")
+	client.BaseURL = ts.URL
+
+	if _, err := client.RunRQL(context.Background(), "select bad", time.Millisecond); err == nil || err.Error() != "rollbarapi: RQL job 6 failed" {
+		t.Error("expected the job to fail, got:", err)
+	}
+}
@@ -0,0 +1,21 @@
+package rollbartest
+
+import "github.com/rollbar/rollbar-go"
+
+// NewRecorderClient returns a synchronous Client reporting to a new FakeServer, which is closed
+// when the test finishes, so that the items reported by middleware and handlers under test can be
+// inspected once they have returned:
+//
+//	client, server := rollbartest.NewRecorderClient(t)
+//	handler := rollbar.Middleware(rollbar.WithMiddlewareClient(client))(next)
+//	handler.ServeHTTP(w, r)
+//	if server.Len() != 1 {
+//		t.Fatal("expected one item, got:", server.Len())
+//	}
+func NewRecorderClient(t rollbar.TB) (*rollbar.Client, *FakeServer) {
+	server := NewFakeServer()
+	t.Cleanup(server.Close)
+	client := rollbar.NewSync("token", "test", "", "", "")
+	client.SetEndpoint(server.Endpoint())
+	return client, server
+}
@@ -0,0 +1,41 @@
+package rollbartest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rollbar/rollbar-go"
+)
+
+// fakeTB runs the cleanup functions when asked to instead of at the end of the test.
+type fakeTB struct {
+	cleanups []func()
+}
+
+func (tb *fakeTB) Name() string { return "TestFake" }
+
+func (tb *fakeTB) Cleanup(f func()) { tb.cleanups = append(tb.cleanups, f) }
+
+func TestNewRecorderClient(t *testing.T) {
+	tb := &fakeTB{}
+	client, server := NewRecorderClient(tb)
+
+	client.ErrorWithLevel(rollbar.ERR, errors.New("card declined"))
+	if server.Len() != 1 || server.LastItem().Title() != "card declined" {
+		t.Fatal("expected the item to be recorded, got:", server.Items())
+	}
+	if tokens := server.Tokens(); len(tokens) != 1 || tokens[0] != "token" {
+		t.Error("unexpected tokens:", tokens)
+	}
+
+	if len(tb.cleanups) != 1 {
+		t.Fatal("expected the server to be closed on cleanup, got:", len(tb.cleanups))
+	}
+	tb.cleanups[0]()
+	client.SetLogger(&rollbar.SilentClientLogger{})
+	client.SetRetryAttempts(0)
+	client.ErrorWithLevel(rollbar.ERR, errors.New("card declined"))
+	if server.Len() != 1 {
+		t.Error("expected the server to be closed, got:", server.Len())
+	}
+}
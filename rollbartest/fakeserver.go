@@ -0,0 +1,188 @@
+package rollbartest
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rollbar/rollbar-go"
+)
+
+// FakeServer is an HTTP server speaking enough of the item API of Rollbar to exercise the
+// transports end-to-end in integration tests. It accepts the items posted with an access token,
+// answering 200 with the UUID of the item as the API does, rather than 202, since the transports
+// treat any other status as a failure. It records their decoded payloads, which can be inspected
+// with the same methods as those of RecorderTransport, and can simulate rate limiting with
+// RateLimitNext and payloads too large with SetMaxPayloadSize:
+//
+//	server := rollbartest.NewFakeServer()
+//	defer server.Close()
+//	client := rollbar.NewSync("token", "test", "", "", "")
+//	client.SetEndpoint(server.Endpoint())
+type FakeServer struct {
+	*httptest.Server
+	recording
+
+	lock           sync.Mutex
+	responses      []int
+	retryAfter     time.Duration
+	maxPayloadSize int64
+	tokens         []string
+}
+
+// NewFakeServer starts and returns a FakeServer, which must be closed when done.
+func NewFakeServer() *FakeServer {
+	s := &FakeServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Endpoint returns the endpoint of the item API of the server, to be set on clients or transports.
+func (s *FakeServer) Endpoint() string {
+	return s.URL + "/api/1/item/"
+}
+
+// RateLimitNext makes the server respond to the next n items with the status 429 and the
+// Retry-After and X-Rate-Limit-* headers sent by the API, without recording them. Retry-After is
+// given in whole seconds, so retryAfter is rounded up to the next second.
+func (s *FakeServer) RateLimitNext(n int, retryAfter time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.retryAfter = retryAfter
+	for i := 0; i < n; i++ {
+		s.responses = append(s.responses, http.StatusTooManyRequests)
+	}
+}
+
+// RespondNext makes the server respond to the next n items with the given status, e.g.
+// http.StatusInternalServerError, and an error message, without recording them.
+func (s *FakeServer) RespondNext(n int, status int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for i := 0; i < n; i++ {
+		s.responses = append(s.responses, status)
+	}
+}
+
+// SetMaxPayloadSize sets the max size in bytes of the payloads accepted by the server, beyond which
+// items are rejected with the status 413. The default value is 0, which accepts any size.
+func (s *FakeServer) SetMaxPayloadSize(size int64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.maxPayloadSize = size
+}
+
+// Tokens returns the access tokens of the recorded items, in the order they were sent.
+func (s *FakeServer) Tokens() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]string(nil), s.tokens...)
+}
+
+// Reset discards the recorded items and the pending simulated responses.
+func (s *FakeServer) Reset() {
+	s.recording.reset()
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.responses = nil
+	s.tokens = nil
+}
+
+// nextResponse returns the next simulated status, or 0 if there is none.
+func (s *FakeServer) nextResponse() (status int, retryAfter time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if len(s.responses) == 0 {
+		return 0, 0
+	}
+	status = s.responses[0]
+	s.responses = s.responses[1:]
+	return status, s.retryAfter
+}
+
+func (s *FakeServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodHead {
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	token := r.Header.Get("X-Rollbar-Access-Token")
+	if token == "" {
+		writeError(w, http.StatusUnauthorized, "missing access token")
+		return
+	}
+	s.lock.Lock()
+	maxPayloadSize := s.maxPayloadSize
+	s.lock.Unlock()
+	var reader io.Reader = r.Body
+	if maxPayloadSize > 0 {
+		reader = io.LimitReader(r.Body, maxPayloadSize+1)
+	}
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if maxPayloadSize > 0 && int64(len(data)) > maxPayloadSize {
+		writeError(w, http.StatusRequestEntityTooLarge, "payload too large")
+		return
+	}
+
+	switch status, retryAfter := s.nextResponse(); status {
+	case 0:
+	case http.StatusTooManyRequests:
+		seconds := strconv.Itoa(int(math.Ceil(retryAfter.Seconds())))
+		w.Header().Set("Retry-After", seconds)
+		w.Header().Set("X-Rate-Limit-Limit", "5000")
+		w.Header().Set("X-Rate-Limit-Remaining", "0")
+		w.Header().Set("X-Rate-Limit-Remaining-Seconds", seconds)
+		w.Header().Set("X-Rate-Limit-Reset", strconv.FormatInt(time.Now().Add(retryAfter).Unix(), 10))
+		writeError(w, status, "rate limit reached")
+		return
+	default:
+		writeError(w, status, http.StatusText(status))
+		return
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(data, &body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+	item, _ := body["data"].(map[string]interface{})
+	if item == nil {
+		writeError(w, http.StatusUnprocessableEntity, "Invalid format. data is missing")
+		return
+	}
+	uuid, _ := item["uuid"].(string)
+	if uuid == "" {
+		uuid = rollbar.UUIDGenerator.NewID()
+	}
+	s.record(body)
+	s.lock.Lock()
+	s.tokens = append(s.tokens, token)
+	s.lock.Unlock()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"err":    0,
+		"result": map[string]interface{}{"id": nil, "uuid": uuid},
+	})
+}
+
+// writeError writes an error response in the format of the API.
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{"err": 1, "message": message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,72 @@
+package rollbartest
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rollbar/rollbar-go"
+)
+
+func TestFakeServer(t *testing.T) {
+	server := NewFakeServer()
+	defer server.Close()
+	client := rollbar.NewSync("token", "test", "", "", "")
+	client.SetEndpoint(server.Endpoint())
+	client.SetLogger(&rollbar.SilentClientLogger{})
+
+	server.RateLimitNext(1, 30*time.Second)
+	client.Message(rollbar.WARN, "disk almost full")
+
+	if server.Len() != 1 {
+		t.Fatal("expected the item to be recorded after the retry, got:", server.Len())
+	}
+	item := server.LastItem()
+	if item.Level() != rollbar.WARN || item.Title() != "disk almost full" || item.UUID() == "" {
+		t.Error("unexpected item:", item)
+	}
+	if tokens := server.Tokens(); len(tokens) != 1 || tokens[0] != "token" {
+		t.Error("unexpected tokens:", tokens)
+	}
+}
+
+func TestFakeServerRejections(t *testing.T) {
+	server := NewFakeServer()
+	defer server.Close()
+	transport := rollbar.NewSyncTransport("token", server.Endpoint())
+	transport.SetLogger(&rollbar.SilentClientLogger{})
+	transport.SetPrintPayloadOnError(false)
+	transport.SetRetryAttempts(0)
+	body := map[string]interface{}{"data": map[string]interface{}{"title": strings.Repeat("x", 100)}}
+
+	server.RateLimitNext(1, 30*time.Second)
+	err := transport.Send(body)
+	var apiErr *rollbar.APIError
+	if !errors.As(err, &apiErr) || !apiErr.Retryable || apiErr.RateLimit.Limit != 5000 {
+		t.Error("expected a rate limited APIError, got:", err)
+	}
+	var rateLimited rollbar.ErrRateLimited
+	if !errors.As(err, &rateLimited) || rateLimited.RetryAfter != 30*time.Second {
+		t.Error("expected ErrRateLimited with RetryAfter, got:", err)
+	}
+
+	server.RateLimitNext(1, 1500*time.Millisecond)
+	err = transport.Send(body)
+	if !errors.As(err, &rateLimited) || rateLimited.RetryAfter != 2*time.Second {
+		t.Error("expected RetryAfter to be rounded up, got:", err)
+	}
+
+	server.SetMaxPayloadSize(50)
+	if err = transport.Send(body); !errors.Is(err, rollbar.ErrPayloadTooLarge{}) {
+		t.Error("expected ErrPayloadTooLarge, got:", err)
+	}
+	if server.Len() != 0 {
+		t.Error("expected no item to be recorded, got:", server.Len())
+	}
+
+	server.SetMaxPayloadSize(0)
+	if err = transport.Send(body); err != nil || server.Len() != 1 {
+		t.Error("expected the item to be recorded, got:", err, server.Len())
+	}
+}
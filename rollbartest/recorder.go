@@ -0,0 +1,117 @@
+// Package rollbartest provides helpers for testing code reporting to Rollbar: RecorderTransport,
+// a Transport recording the items reported by a client instead of sending them, so that tests can
+// assert on them:
+//
+//	recorder := rollbartest.NewRecorderTransport()
+//	client := rollbar.New("token", "test", "", "", "")
+//	client.Transport = recorder
+//
+//	checkout(client)
+//
+//	if item := recorder.LastItem(); item.Level() != rollbar.ERR {
+//		t.Error("expected an error, got:", item.Level())
+//	}
+//
+// FakeServer, a fake of the item API to exercise the transports end-to-end, and NewRecorderClient,
+// which returns a client reporting to a FakeServer for the duration of a test.
+package rollbartest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rollbar/rollbar-go"
+)
+
+// transport is embedded by RecorderTransport without exporting the field.
+type transport = rollbar.Transport
+
+// RecorderTransport is a rollbar.Transport recording the bodies of the items sent through it. It
+// can simulate failures such as rate limiting with FailNext and RateLimitNext. It is safe for
+// concurrent use.
+type RecorderTransport struct {
+	// transport handles the settings of the Transport interface, which do not affect recording.
+	transport
+	recording
+
+	lock     sync.Mutex
+	failures []error
+	waits    int
+	closed   bool
+}
+
+// NewRecorderTransport returns an empty RecorderTransport.
+func NewRecorderTransport() *RecorderTransport {
+	return &RecorderTransport{transport: rollbar.NewSyncTransport("", "")}
+}
+
+// Send records the body of an item, or returns the next simulated failure without recording it.
+func (r *RecorderTransport) Send(body map[string]interface{}) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if len(r.failures) > 0 {
+		err := r.failures[0]
+		r.failures = r.failures[1:]
+		return err
+	}
+	r.record(body)
+	return nil
+}
+
+// Wait records that it was called, see Waits.
+func (r *RecorderTransport) Wait() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.waits++
+}
+
+// Close records that it was called, see Closed.
+func (r *RecorderTransport) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.closed = true
+	return nil
+}
+
+// FailNext makes the next n calls to Send return err instead of recording the items.
+func (r *RecorderTransport) FailNext(n int, err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for i := 0; i < n; i++ {
+		r.failures = append(r.failures, err)
+	}
+}
+
+// RateLimitNext makes the next n calls to Send fail as if the API rate limited the items, with the
+// same error as the transports of the rollbar package.
+func (r *RecorderTransport) RateLimitNext(n int, retryAfter time.Duration) {
+	r.FailNext(n, &rollbar.APIError{
+		StatusCode: 429,
+		Retryable:  true,
+		Err:        rollbar.ErrRateLimited{RetryAfter: retryAfter},
+	})
+}
+
+// Waits returns the number of calls to Wait.
+func (r *RecorderTransport) Waits() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.waits
+}
+
+// Closed returns whether Close was called.
+func (r *RecorderTransport) Closed() bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.closed
+}
+
+// Reset discards the recorded items and the pending failures.
+func (r *RecorderTransport) Reset() {
+	r.recording.reset()
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.failures = nil
+	r.waits = 0
+	r.closed = false
+}
@@ -0,0 +1,65 @@
+package rollbartest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rollbar/rollbar-go"
+)
+
+func TestRecorderTransport(t *testing.T) {
+	recorder := NewRecorderTransport()
+	client := rollbar.New("token", "test", "", "", "")
+	client.Transport = recorder
+
+	client.Message(rollbar.INFO, "started")
+	client.ErrorWithExtras(rollbar.ERR, errors.New("card declined"), map[string]interface{}{"order": 42})
+	client.Wait()
+
+	if recorder.Len() != 2 || recorder.Waits() != 1 {
+		t.Fatal("expected 2 items and 1 wait, got:", recorder.Len(), recorder.Waits())
+	}
+	item := recorder.LastItem()
+	if item.Level() != rollbar.ERR || item.Title() != "card declined" || item.Custom()["order"] != 42 {
+		t.Error("unexpected last item:", item)
+	}
+	if item.UUID() == "" {
+		t.Error("expected the item to have a UUID")
+	}
+	if infos := recorder.ByLevel(rollbar.INFO); len(infos) != 1 || infos[0].Title() != "started" {
+		t.Error("unexpected info items:", infos)
+	}
+	if len(recorder.ByTitle("card declined")) != 1 || len(recorder.ByTitle("missing")) != 0 {
+		t.Error("unexpected items by title:", recorder.Items())
+	}
+
+	client.Close()
+	if !recorder.Closed() {
+		t.Error("expected the transport to be closed")
+	}
+}
+
+func TestRecorderTransportFailures(t *testing.T) {
+	recorder := NewRecorderTransport()
+	recorder.RateLimitNext(1, 30*time.Second)
+	recorder.FailNext(1, errors.New("connection refused"))
+
+	body := map[string]interface{}{"data": map[string]interface{}{"title": "boom"}}
+	err := recorder.Send(body)
+	var rateLimited rollbar.ErrRateLimited
+	if !errors.As(err, &rateLimited) || rateLimited.RetryAfter != 30*time.Second {
+		t.Error("expected the item to be rate limited, got:", err)
+	}
+	if err = recorder.Send(body); err == nil || err.Error() != "connection refused" {
+		t.Error("expected the simulated failure, got:", err)
+	}
+	if err = recorder.Send(body); err != nil || recorder.Len() != 1 {
+		t.Error("expected the item to be recorded once the failures are exhausted, got:", err, recorder.Len())
+	}
+
+	recorder.Reset()
+	if recorder.Len() != 0 || recorder.LastItem() != nil {
+		t.Error("expected no items after Reset")
+	}
+}
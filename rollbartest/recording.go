@@ -0,0 +1,112 @@
+package rollbartest
+
+import "sync"
+
+// recording holds the bodies of the items recorded by RecorderTransport and FakeServer.
+type recording struct {
+	lock   sync.Mutex
+	bodies []map[string]interface{}
+}
+
+// record records the body of an item.
+func (r *recording) record(body map[string]interface{}) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.bodies = append(r.bodies, body)
+}
+
+// reset discards the recorded items.
+func (r *recording) reset() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.bodies = nil
+}
+
+// Bodies returns the bodies of the recorded items, in the order they were sent.
+func (r *recording) Bodies() []map[string]interface{} {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]map[string]interface{}(nil), r.bodies...)
+}
+
+// Items returns the recorded items, in the order they were sent.
+func (r *recording) Items() []Item {
+	bodies := r.Bodies()
+	items := make([]Item, len(bodies))
+	for i, body := range bodies {
+		items[i] = itemOf(body)
+	}
+	return items
+}
+
+// Len returns the number of recorded items.
+func (r *recording) Len() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return len(r.bodies)
+}
+
+// LastItem returns the last recorded item, or nil if there is none.
+func (r *recording) LastItem() Item {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if len(r.bodies) == 0 {
+		return nil
+	}
+	return itemOf(r.bodies[len(r.bodies)-1])
+}
+
+// ByLevel returns the recorded items of the given level, e.g. rollbar.ERR.
+func (r *recording) ByLevel(level string) []Item {
+	return r.filter(func(item Item) bool { return item.Level() == level })
+}
+
+// ByTitle returns the recorded items with the given title, which is the message of messages and
+// the message of the error of errors.
+func (r *recording) ByTitle(title string) []Item {
+	return r.filter(func(item Item) bool { return item.Title() == title })
+}
+
+// filter returns the recorded items matching keep.
+func (r *recording) filter(keep func(Item) bool) []Item {
+	var items []Item
+	for _, item := range r.Items() {
+		if keep(item) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Item is the data of a recorded item, the "data" object of its payload.
+type Item map[string]interface{}
+
+// itemOf returns the data of the body of an item.
+func itemOf(body map[string]interface{}) Item {
+	data, _ := body["data"].(map[string]interface{})
+	return Item(data)
+}
+
+// Level returns the level of the item.
+func (i Item) Level() string {
+	level, _ := i["level"].(string)
+	return level
+}
+
+// Title returns the title of the item.
+func (i Item) Title() string {
+	title, _ := i["title"].(string)
+	return title
+}
+
+// UUID returns the UUID of the item.
+func (i Item) UUID() string {
+	uuid, _ := i["uuid"].(string)
+	return uuid
+}
+
+// Custom returns the custom data of the item, or nil if it has none.
+func (i Item) Custom() map[string]interface{} {
+	custom, _ := i["custom"].(map[string]interface{})
+	return custom
+}
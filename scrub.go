@@ -0,0 +1,198 @@
+package rollbar
+
+import (
+	"regexp"
+	"strings"
+)
+
+// The sources of the default scrubbing regular expressions. They can be extended without being
+// weakened with CombineScrubPatterns, e.g.
+//
+//	rollbar.SetScrubFields(rollbar.CombineScrubPatterns(rollbar.DefaultScrubFields, "ssn|card_number"))
+const (
+	// DefaultScrubHeaders matches the request headers scrubbed by default, see SetScrubHeaders.
+	DefaultScrubHeaders = "Authorization"
+	// DefaultScrubFields matches the keys scrubbed by default, see SetScrubFields.
+	DefaultScrubFields = "password|secret|token"
+	// DefaultScrubCookies matches the names of the cookies scrubbed by default when cookies are
+	// captured, see SetCaptureCookies: session identifiers, authentication tokens and CSRF tokens.
+	DefaultScrubCookies = "(?i)session|auth|csrf|xsrf"
+)
+
+// CombineScrubPatterns returns a regular expression matching what any of the patterns matches.
+// Each pattern is grouped on its own, so that alternations and flags such as (?i) in one pattern
+// do not affect the others. Empty patterns are ignored. Like regexp.MustCompile, it panics if a
+// pattern cannot be parsed.
+func CombineScrubPatterns(patterns ...string) *regexp.Regexp {
+	groups := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		if pattern != "" {
+			groups = append(groups, "(?:"+pattern+")")
+		}
+	}
+	return regexp.MustCompile(strings.Join(groups, "|"))
+}
+
+// fieldMatcher matches the keys scrubbed by SetScrubFields, except those exempted with
+// SetScrubExemptFields.
+type fieldMatcher struct {
+	pattern *regexp.Regexp
+	exempt  []string
+}
+
+// matches returns whether the value of key must be scrubbed.
+func (m fieldMatcher) matches(key string) bool {
+	if !m.pattern.MatchString(key) {
+		return false
+	}
+	for _, exempt := range m.exempt {
+		if strings.EqualFold(exempt, key) {
+			return false
+		}
+	}
+	return true
+}
+
+// fieldMatcher returns the matcher of the keys scrubbed with this configuration.
+func (c configuration) fieldMatcher() fieldMatcher {
+	return fieldMatcher{pattern: c.scrubFields, exempt: c.scrubExempt}
+}
+
+// A Scrubber redacts the items before they are sent, e.g. by delegating to the central redaction
+// library of an organization, see SetScrubber. It is applied consistently to every section of the
+// payload: the request, the custom data, the person, the error and message texts and the telemetry
+// events. The built-in scrubbing of headers, fields and PII is applied the same way, by a Scrubber
+// of its own.
+type Scrubber interface {
+	// ScrubHeader returns whether the value of the header name, of a request or of a network
+	// telemetry event, is replaced by FILTERED.
+	ScrubHeader(name string) bool
+	// ScrubField returns whether the value of key is replaced by FILTERED, at any depth below the
+	// top level of the item.
+	ScrubField(key string) bool
+	// ScrubValue returns the text to report in place of value, for every string of the item
+	// which is not replaced by FILTERED.
+	ScrubValue(value string) string
+}
+
+// builtinScrubber is the Scrubber applying the built-in scrubbing of a configuration, see
+// SetScrubHeaders, SetScrubFields, SetScrubExemptFields and SetScrubPII.
+type builtinScrubber struct {
+	headers *regexp.Regexp
+	fields  fieldMatcher
+	pii     bool
+}
+
+// builtinScrubber returns the Scrubber applying the built-in scrubbing of this configuration.
+func (c configuration) builtinScrubber() builtinScrubber {
+	return builtinScrubber{headers: c.scrubHeaders, fields: c.fieldMatcher(), pii: c.scrubPII}
+}
+
+// ScrubHeader implements Scrubber.
+func (s builtinScrubber) ScrubHeader(name string) bool {
+	return s.headers != nil && s.headers.MatchString(name)
+}
+
+// ScrubField implements Scrubber.
+func (s builtinScrubber) ScrubField(key string) bool {
+	return s.fields.pattern != nil && s.fields.matches(key)
+}
+
+// ScrubValue implements Scrubber.
+func (s builtinScrubber) ScrubValue(value string) string {
+	if !s.pii {
+		return value
+	}
+	return scrubPII(value)
+}
+
+// headerKeys are the keys of the header maps of the items and of the network telemetry events.
+var headerKeys = map[string]struct{}{
+	"headers":         {},
+	"request_headers": {},
+}
+
+// redactor walks the values of an item, replacing the headers and fields it matches by FILTERED
+// and the strings by the result of value. Nil functions are skipped.
+type redactor struct {
+	header func(name string) bool
+	field  func(key string) bool
+	value  func(value string) string
+}
+
+// newRedactor returns the redactor applying s.
+func newRedactor(s Scrubber) redactor {
+	return redactor{header: s.ScrubHeader, field: s.ScrubField, value: s.ScrubValue}
+}
+
+// redactData redacts the values of the top level keys of an item, except for its UUID, in place.
+func (r redactor) redactData(data map[string]interface{}) {
+	for key, value := range data {
+		if key != "uuid" {
+			data[key] = r.redact(value, false)
+		}
+	}
+}
+
+// filtered returns whether the value of key is replaced by FILTERED, key being a header name if
+// headers is true.
+func (r redactor) filtered(key string, headers bool) bool {
+	if headers {
+		return r.header != nil && r.header(key)
+	}
+	return r.field != nil && r.field(key)
+}
+
+// redact returns a copy of v in which the matched keys and the strings are redacted, the keys of
+// its maps being header names if headers is true. The values of v are not modified since they may
+// be shared with the configuration, the telemetry queue or the context of the report.
+func (r redactor) redact(v interface{}, headers bool) interface{} {
+	switch val := v.(type) {
+	case string:
+		if r.value == nil {
+			return val
+		}
+		return r.value(val)
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			if r.filtered(k, headers) {
+				m[k] = FILTERED
+				continue
+			}
+			_, isHeaders := headerKeys[k]
+			m[k] = r.redact(item, isHeaders)
+		}
+		return m
+	case map[string]string:
+		m := make(map[string]string, len(val))
+		for k, item := range val {
+			if r.filtered(k, headers) {
+				m[k] = FILTERED
+			} else {
+				m[k] = r.redact(item, false).(string)
+			}
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(val))
+		for i, item := range val {
+			s[i] = r.redact(item, false)
+		}
+		return s
+	case []map[string]interface{}:
+		s := make([]map[string]interface{}, len(val))
+		for i, item := range val {
+			s[i] = r.redact(item, false).(map[string]interface{})
+		}
+		return s
+	case []string:
+		s := make([]string, len(val))
+		for i, item := range val {
+			s[i] = r.redact(item, false).(string)
+		}
+		return s
+	default:
+		return v
+	}
+}
@@ -0,0 +1,132 @@
+package rollbar
+
+import (
+	"strconv"
+	"strings"
+)
+
+// SetScrubPaths sets the paths of the fields scrubbed in the data of items, in addition to the keys
+// matching SetScrubFields, for fields which must only be scrubbed in a given location, e.g.
+// "request.body.card.number", or which cannot be told apart by their key alone. A path is made of
+// keys of the data of the item separated by dots; a "*" matches any key or any element of a list,
+// e.g. "custom.*.secret" or "custom.orders.*.card", and a number matches the element of a list at
+// that index. Fields are scrubbed after the enrichers have run. The default value is nil.
+func (c *Client) SetScrubPaths(paths ...string) {
+	c.ApplyOptions(WithScrubPaths(paths...))
+}
+
+// ScrubPaths returns the paths of the scrubbed fields, see SetScrubPaths.
+func (c *Client) ScrubPaths() []string {
+	return c.configuration().scrubPaths
+}
+
+// scrubPathsInData replaces the values of the fields at the given paths by FILTERED in the data of
+// an item. The maps and slices along the paths are copied rather than modified, since they may
+// belong to the caller, e.g. the extras of an item.
+func scrubPathsInData(data map[string]interface{}, paths []string) {
+	for _, path := range paths {
+		segments := strings.Split(path, ".")
+		for key, value := range data {
+			if !pathSegmentMatches(segments[0], key) {
+				continue
+			}
+			if len(segments) == 1 {
+				data[key] = FILTERED
+			} else if scrubbed, ok := scrubPath(value, segments[1:]); ok {
+				data[key] = scrubbed
+			}
+		}
+	}
+}
+
+// scrubPath returns a copy of v in which the values at path are replaced by FILTERED, and whether
+// any value was found at path.
+func scrubPath(v interface{}, path []string) (interface{}, bool) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		var m map[string]interface{}
+		for key, item := range val {
+			if !pathSegmentMatches(path[0], key) {
+				continue
+			}
+			scrubbed, ok := interface{}(FILTERED), true
+			if len(path) > 1 {
+				scrubbed, ok = scrubPath(item, path[1:])
+			}
+			if !ok {
+				continue
+			}
+			if m == nil {
+				m = make(map[string]interface{}, len(val))
+				for k, item := range val {
+					m[k] = item
+				}
+			}
+			m[key] = scrubbed
+		}
+		return m, m != nil
+	case map[string]string:
+		if len(path) > 1 {
+			return v, false
+		}
+		var m map[string]string
+		for key := range val {
+			if !pathSegmentMatches(path[0], key) {
+				continue
+			}
+			if m == nil {
+				m = make(map[string]string, len(val))
+				for k, item := range val {
+					m[k] = item
+				}
+			}
+			m[key] = FILTERED
+		}
+		return m, m != nil
+	case []interface{}:
+		var s []interface{}
+		for i, item := range val {
+			if !pathSegmentMatches(path[0], strconv.Itoa(i)) {
+				continue
+			}
+			scrubbed, ok := interface{}(FILTERED), true
+			if len(path) > 1 {
+				scrubbed, ok = scrubPath(item, path[1:])
+			}
+			if !ok {
+				continue
+			}
+			if s == nil {
+				s = append([]interface{}(nil), val...)
+			}
+			s[i] = scrubbed
+		}
+		return s, s != nil
+	case []map[string]interface{}:
+		if len(path) == 1 {
+			return v, false
+		}
+		var s []map[string]interface{}
+		for i, item := range val {
+			if !pathSegmentMatches(path[0], strconv.Itoa(i)) {
+				continue
+			}
+			scrubbed, ok := scrubPath(item, path[1:])
+			if !ok {
+				continue
+			}
+			if s == nil {
+				s = append([]map[string]interface{}(nil), val...)
+			}
+			s[i] = scrubbed.(map[string]interface{})
+		}
+		return s, s != nil
+	default:
+		return v, false
+	}
+}
+
+// pathSegmentMatches returns whether a segment of a scrub path matches a key or a list index.
+func pathSegmentMatches(segment, key string) bool {
+	return segment == "*" || segment == key
+}
@@ -0,0 +1,59 @@
+package rollbar
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScrubPathsInData(t *testing.T) {
+	card := map[string]interface{}{"number": "4242424242424242", "brand": "visa"}
+	orders := []interface{}{
+		map[string]interface{}{"id": 1, "card": "4242"},
+		map[string]interface{}{"id": 2, "card": "1881"},
+	}
+	data := map[string]interface{}{
+		"request": map[string]interface{}{"body": map[string]interface{}{"card": card}},
+		"custom": map[string]interface{}{
+			"stripe": map[string]interface{}{"secret": "sk_live", "mode": "live"},
+			"orders": orders,
+			"card":   map[string]interface{}{"number": "kept"},
+		},
+		"person": map[string]string{"id": "7", "email": "jane@example.com"},
+	}
+
+	scrubPathsInData(data, []string{"request.body.card.number", "custom.*.secret", "custom.orders.1.card", "person.email", "custom.missing.path"})
+
+	body := data["request"].(map[string]interface{})["body"].(map[string]interface{})
+	if !reflect.DeepEqual(body["card"], map[string]interface{}{"number": FILTERED, "brand": "visa"}) {
+		t.Error("expected the card number to be scrubbed, got:", body["card"])
+	}
+	custom := data["custom"].(map[string]interface{})
+	if custom["stripe"].(map[string]interface{})["secret"] != FILTERED || custom["card"].(map[string]interface{})["number"] != "kept" {
+		t.Error("expected only the fields at the paths to be scrubbed, got:", custom)
+	}
+	scrubbedOrders := custom["orders"].([]interface{})
+	if scrubbedOrders[0].(map[string]interface{})["card"] != "4242" || scrubbedOrders[1].(map[string]interface{})["card"] != FILTERED {
+		t.Error("expected the card of the second order to be scrubbed, got:", scrubbedOrders)
+	}
+	if person := data["person"].(map[string]string); person["email"] != FILTERED || person["id"] != "7" {
+		t.Error("expected the email of the person to be scrubbed, got:", person)
+	}
+	if card["number"] != "4242424242424242" || orders[1].(map[string]interface{})["card"] != "1881" {
+		t.Error("the values of the caller should not be modified")
+	}
+}
+
+func TestSetScrubPaths(t *testing.T) {
+	client := testClient()
+	client.SetScrubPaths("custom.payment.iban")
+	client.MessageWithExtras(INFO, "refund", map[string]interface{}{
+		"payment": map[string]interface{}{"iban": "DE89370400440532013000", "amount": 12},
+		"iban":    "kept",
+	})
+
+	custom := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})["custom"].(map[string]interface{})
+	payment := custom["payment"].(map[string]interface{})
+	if payment["iban"] != FILTERED || payment["amount"] != 12 || custom["iban"] != "kept" {
+		t.Error("expected only the IBAN of the payment to be scrubbed, got:", custom)
+	}
+}
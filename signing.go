@@ -0,0 +1,70 @@
+package rollbar
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// SignatureHeader is the HTTP header carrying the signature of payloads sent by transports with a
+// signing key. Its value is "sha256=" followed by the hex encoded HMAC-SHA256 of the request body.
+const SignatureHeader = "X-Rollbar-Signature"
+
+const signaturePrefix = "sha256="
+
+// Sign returns the value of the SignatureHeader for a request body signed with key.
+func Sign(body, key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature reports whether signature, the value of the SignatureHeader of a request, is a
+// valid signature of body with key. Relays and proxies can use it to check that items come from
+// trusted services before forwarding them to Rollbar.
+func VerifySignature(body []byte, signature string, key []byte) bool {
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return false
+	}
+	expected, err := hex.DecodeString(strings.TrimPrefix(signature, signaturePrefix))
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// SetSigningKey sets the key used to sign payloads. When set, every request carries the
+// SignatureHeader with the HMAC-SHA256 of its body, so that a relay between the application and
+// the Rollbar API can verify the origin of items. A nil key disables signing, which is the default.
+func (t *baseTransport) SetSigningKey(key []byte) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.signingKey = key
+}
+
+func (t *interceptedTransport) SetSigningKey(key []byte) {
+	if inner, ok := t.Transport.(signer); ok {
+		inner.SetSigningKey(key)
+	}
+}
+
+// signer is implemented by the transports supporting payload signing.
+type signer interface {
+	SetSigningKey(key []byte)
+}
+
+// SetSigningKey sets the key used by the transport of the client to sign payloads with HMAC-SHA256
+// in the SignatureHeader, so that relays can verify that items originate from trusted services.
+// See VerifySignature. A nil key disables signing, which is the default. Transports which do not
+// support signing are left unchanged and an error is logged.
+func (c *Client) SetSigningKey(key []byte) {
+	s, ok := c.Transport.(signer)
+	if !ok {
+		rollbarError(nil, "transport %T does not support payload signing", c.Transport)
+		return
+	}
+	s.SetSigningKey(key)
+}
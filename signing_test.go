@@ -0,0 +1,43 @@
+package rollbar
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSetSigningKey(t *testing.T) {
+	key := []byte("relay secret")
+	var signature string
+	var body []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get(SignatureHeader)
+		body, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer ts.Close()
+
+	client := NewSync("token", "test", "", "", "")
+	client.SetEndpoint(ts.URL)
+	client.SetSigningKey(key)
+	if !client.Config().Transport.Signed {
+		t.Error("expected the transport to sign payloads")
+	}
+	client.Message(INFO, "signed")
+
+	if !VerifySignature(body, signature, key) {
+		t.Error("expected a valid signature, got:", signature)
+	}
+	if VerifySignature(body, signature, []byte("other secret")) || VerifySignature(append(body, ' '), signature, key) {
+		t.Error("expected the signature to depend on the key and the body")
+	}
+	if VerifySignature(body, "md5=abc", key) {
+		t.Error("expected unknown signature schemes to be rejected")
+	}
+
+	client.SetSigningKey(nil)
+	client.Message(INFO, "unsigned")
+	if signature != "" {
+		t.Error("expected no signature, got:", signature)
+	}
+}
@@ -0,0 +1,254 @@
+package rollbar
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// spoolExt is the extension of the files written by a SpoolTransport.
+const spoolExt = ".ndjson"
+
+// rejectedExt is appended to the name of a spool file to name the file where the items rejected by
+// the API for good are kept by ReplaySpool.
+const rejectedExt = ".rejected"
+
+// SpoolTransport is a Transport which writes the items to newline-delimited JSON files in a
+// directory instead of sending them to the API, so that machines without network access can
+// report items which are sent later with ReplaySpool. Each transport writes to a file of its own,
+// named after the time it was created and the process ID, so that several processes can share the
+// directory. The payloads written are those which would have been sent; the access token is not
+// written.
+type SpoolTransport struct {
+	dir    string
+	logger ClientLogger
+	file   *os.File
+	lock   sync.Mutex
+}
+
+// NewSpoolTransport returns a SpoolTransport writing to dir, which is created if it does not
+// exist. The file is created when the first item is written.
+func NewSpoolTransport(dir string) (*SpoolTransport, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &SpoolTransport{dir: dir}, nil
+}
+
+// Send writes the body as a line of the spool file.
+func (t *SpoolTransport) Send(body map[string]interface{}) error {
+	ensureItemUUID(body)
+	line, err := json.Marshal(body)
+	if err != nil {
+		rollbarError(t.getLogger(), "failed to encode payload: %s", err.Error())
+		return err
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.file == nil {
+		name := fmt.Sprintf("rollbar-%s-%d%s", time.Now().UTC().Format("20060102T150405.000000000"), os.Getpid(), spoolExt)
+		t.file, err = os.OpenFile(filepath.Join(t.dir, name), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+		if err != nil {
+			rollbarError(t.logger, "failed to create spool file: %s", err.Error())
+			return err
+		}
+	}
+	if _, err = t.file.Write(append(line, '\n')); err != nil {
+		rollbarError(t.logger, "failed to write spool file: %s", err.Error())
+	}
+	return err
+}
+
+// Wait is a no-op for the spool transport, which writes the items as they are sent.
+func (t *SpoolTransport) Wait() {}
+
+// Close closes the spool file, if any.
+func (t *SpoolTransport) Close() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.file == nil {
+		return nil
+	}
+	err := t.file.Close()
+	t.file = nil
+	return err
+}
+
+// Dir returns the directory the items are written to.
+func (t *SpoolTransport) Dir() string {
+	return t.dir
+}
+
+// SetLogger sets the logger used to report the items which could not be written.
+func (t *SpoolTransport) SetLogger(logger ClientLogger) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.logger = logger
+}
+
+func (t *SpoolTransport) getLogger() ClientLogger {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.logger
+}
+
+// SetToken is a no-op for the spool transport, as the token is given to ReplaySpool.
+func (t *SpoolTransport) SetToken(token string) {}
+
+// SetEndpoint is a no-op for the spool transport, as the endpoint is given to ReplaySpool.
+func (t *SpoolTransport) SetEndpoint(endpoint string) {}
+
+// SetRetryAttempts is a no-op for the spool transport.
+func (t *SpoolTransport) SetRetryAttempts(retryAttempts int) {}
+
+// SetPrintPayloadOnError is a no-op for the spool transport.
+func (t *SpoolTransport) SetPrintPayloadOnError(printPayloadOnError bool) {}
+
+// SetHTTPClient is a no-op for the spool transport.
+func (t *SpoolTransport) SetHTTPClient(httpClient *http.Client) {}
+
+// SetItemsPerMinute is a no-op for the spool transport, which writes all the items.
+func (t *SpoolTransport) SetItemsPerMinute(itemsPerMinute int) {}
+
+func (t *SpoolTransport) setContext(ctx context.Context) {}
+
+// SetOfflineMode replaces the transport of the client by a SpoolTransport writing the items to
+// newline-delimited JSON files in dir instead of sending them, e.g. on air-gapped machines which
+// sync periodically. The items are sent later with ReplaySpool. The logger of the previous
+// transport is kept and the previous transport is closed. An error is returned, and the transport
+// left unchanged, if dir cannot be created.
+func (c *Client) SetOfflineMode(dir string) error {
+	spool, err := NewSpoolTransport(dir)
+	if err != nil {
+		return err
+	}
+	previous := c.Transport
+	if p, ok := previous.(loggerProvider); ok {
+		spool.SetLogger(p.getLogger())
+	}
+	c.Transport = spool
+	return previous.Close()
+}
+
+// ReplaySpool sends the items written to dir by SpoolTransport, see SetOfflineMode, with the token
+// and endpoint of the client, oldest file first. Each file is removed once all its items are sent.
+// Replaying stops at the first item which cannot be sent for now, e.g. because the network is still
+// down, or when ctx is done, and the error is returned: the items not sent yet are kept in their
+// file so that they are sent by the next replay. The items the API rejects for good, i.e. with a 4xx
+// status other than 401, 403 and 429 which are not about the item itself, are logged and moved to a
+// file named after the spool file with the ".rejected" suffix, so that they do not block the next
+// replays. Lines which cannot be decoded,
+// such as one truncated by a crash, are logged and skipped. Dir must not be written to by a
+// transport while it is replayed.
+func (c *Client) ReplaySpool(ctx context.Context, dir string) error {
+	conf := c.configuration()
+	if conf.token == "" {
+		return errors.New("rollbar: cannot replay the spool without a token")
+	}
+	transport := NewSyncTransport(conf.token, conf.endpoint)
+	transport.SetPrintPayloadOnError(false)
+	if p, ok := c.Transport.(loggerProvider); ok {
+		transport.SetLogger(p.getLogger())
+	}
+	if p, ok := c.Transport.(httpClientProvider); ok {
+		transport.SetHTTPClient(p.getHTTPClient())
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*"+spoolExt))
+	if err != nil {
+		return err
+	}
+	// The names start with the creation time of the file, so that they sort chronologically.
+	sort.Strings(files)
+	for _, file := range files {
+		if err := replayFile(ctx, transport, file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replayFile sends the items of a spool file with transport and removes it. The items rejected for
+// good are appended to the rejected file. If an item cannot be sent for now, the file is rewritten
+// with the items not sent yet.
+func replayFile(ctx context.Context, transport *SyncTransport, file string) error {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(nil, len(content)+1)
+	offset := 0
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		start := offset
+		offset += len(line) + 1
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var body map[string]interface{}
+		if err := json.Unmarshal(line, &body); err != nil {
+			rollbarError(transport.getLogger(), "skipping invalid spool line in %s: %s", file, err.Error())
+			continue
+		}
+		err := sendReplayed(ctx, transport, body)
+		if rejectedItem(err) {
+			rollbarError(transport.getLogger(), "moving item rejected by the API to %s: %s", file+rejectedExt, err.Error())
+			if err := appendLine(file+rejectedExt, line); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			if writeErr := ioutil.WriteFile(file, content[start:], 0600); writeErr != nil {
+				return writeErr
+			}
+			return err
+		}
+	}
+	return os.Remove(file)
+}
+
+// rejectedItem returns whether err is the API rejecting the item sent for good, so that sending it
+// again can never succeed, rather than a failure of the network, the server or the credentials.
+func rejectedItem(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Retryable {
+		return false
+	}
+	switch apiErr.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return false
+	}
+	return apiErr.StatusCode >= 400 && apiErr.StatusCode < 500
+}
+
+// appendLine appends line to file, which is created if it does not exist.
+func appendLine(file string, line []byte) error {
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(append([]byte(nil), line...), '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// sendReplayed sends body with transport unless ctx is done.
+func sendReplayed(ctx context.Context, transport *SyncTransport, body map[string]interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return transport.Send(body)
+}
@@ -0,0 +1,130 @@
+package rollbar
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOfflineModeAndReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rollbar-spool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var titles []string
+	failing := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Rollbar-Access-Token") != "token" {
+			t.Error("expected the token of the client")
+		}
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		title := body["data"].(map[string]interface{})["title"].(string)
+		if failing && title == "second" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		titles = append(titles, title)
+	}))
+	defer ts.Close()
+
+	client := NewSync("token", "test", "", "", "")
+	client.SetEndpoint(ts.URL)
+	client.SetLogger(&SilentClientLogger{})
+	if err := client.SetOfflineMode(filepath.Join(dir, "spool")); err != nil {
+		t.Fatal(err)
+	}
+	for _, title := range []string{"first", "second", "third"} {
+		client.Message(INFO, title)
+	}
+	if err := client.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(titles) != 0 {
+		t.Fatal("expected no item to be sent in offline mode, got:", titles)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "spool", "*.ndjson"))
+	if len(files) != 1 {
+		t.Fatal("expected one spool file, got:", files)
+	}
+	content, _ := ioutil.ReadFile(files[0])
+	if lines := strings.Split(strings.TrimSpace(string(content)), "\n"); len(lines) != 3 {
+		t.Fatal("expected one line per item, got:", lines)
+	}
+	// A line truncated by a crash is skipped.
+	ioutil.WriteFile(files[0], append(content, []byte(`{"data": {"ti`)...), 0600)
+
+	failing = true
+	if err := client.ReplaySpool(context.Background(), filepath.Join(dir, "spool")); err == nil {
+		t.Error("expected the error of the item which could not be sent")
+	}
+	if strings.Join(titles, ",") != "first" {
+		t.Error("expected the items before the failure to be sent, got:", titles)
+	}
+
+	failing = false
+	if err := client.ReplaySpool(context.Background(), filepath.Join(dir, "spool")); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(titles, ",") != "first,second,third" {
+		t.Error("expected the remaining items to be sent, got:", titles)
+	}
+	if _, err := os.Stat(files[0]); !os.IsNotExist(err) {
+		t.Error("expected the spool file to be removed, got:", err)
+	}
+}
+
+func TestReplaySpoolRejected(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rollbar-spool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var titles []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		title := body["data"].(map[string]interface{})["title"].(string)
+		if title == "second" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		titles = append(titles, title)
+	}))
+	defer ts.Close()
+
+	client := NewSync("token", "test", "", "", "")
+	client.SetEndpoint(ts.URL)
+	client.SetLogger(&SilentClientLogger{})
+	if err := client.SetOfflineMode(dir); err != nil {
+		t.Fatal(err)
+	}
+	for _, title := range []string{"first", "second", "third"} {
+		client.Message(INFO, title)
+	}
+	client.Close()
+
+	if err := client.ReplaySpool(context.Background(), dir); err != nil {
+		t.Fatal("expected the rejected item not to stop the replay, got:", err)
+	}
+	if strings.Join(titles, ",") != "first,third" {
+		t.Error("expected the other items to be sent, got:", titles)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*"))
+	if len(files) != 1 || !strings.HasSuffix(files[0], ".ndjson.rejected") {
+		t.Fatal("expected only the rejected file to be left, got:", files)
+	}
+	content, _ := ioutil.ReadFile(files[0])
+	if lines := strings.Split(strings.TrimSpace(string(content)), "\n"); len(lines) != 1 || !strings.Contains(lines[0], `"second"`) {
+		t.Error("expected the rejected item to be kept, got:", lines)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"hash/crc32"
 	"os"
 	"runtime"
+	"strconv"
 	"strings"
 )
 
@@ -82,3 +83,185 @@ func functionName(pcFuncName string) string {
 	end := strings.LastIndex(pcFuncName, string(os.PathSeparator))
 	return pcFuncName[end+1:]
 }
+
+// A SkipPreset lists the prefixes of the function names of the frames added by a wrapper around a
+// Client, such as a logging hook or a middleware. The leading frames of a stack trace matching
+// one of the presets set with SetSkipPresets are dropped, so that the stack trace starts in user
+// code without having to count the frames to skip.
+type SkipPreset []string
+
+var (
+	// SkipLogrusHook drops the frames of logrus leading up to a hook reporting to Rollbar.
+	SkipLogrusHook = SkipPreset{"github.com/sirupsen/logrus."}
+	// SkipMiddleware drops the frames of the middlewares provided by this module and of the web
+	// frameworks and routers they integrate with.
+	SkipMiddleware = SkipPreset{
+		"github.com/rollbar/rollbar-go/contrib/",
+		"github.com/gin-gonic/gin.",
+		"github.com/labstack/echo/v4.",
+		"github.com/labstack/echo/v4/middleware.",
+		"github.com/gofiber/fiber/v2.",
+		"github.com/go-chi/chi/v5.",
+		"github.com/go-chi/chi/v5/middleware.",
+		"github.com/gorilla/mux.",
+	}
+
+	// ownFramePrefixes are the prefixes of the function names of the frames of this module and of
+	// the runtime functions raising panics, which are always dropped from the top of stack traces.
+	ownFramePrefixes = SkipPreset{
+		"github.com/rollbar/rollbar-go.",
+		"github.com/rollbar/rollbar-go/contrib/",
+		"github.com/rollbar/rollbar-go/errors.",
+		"runtime.gopanic",
+		"runtime.goPanic",
+		"runtime.panic",
+		"runtime.sigpanic",
+	}
+)
+
+// matches returns whether the function of the frame starts with one of the prefixes of the preset.
+func (p SkipPreset) matches(fr runtime.Frame) bool {
+	for _, prefix := range p {
+		if strings.HasPrefix(fr.Function, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// trimFrames drops the leading frames of this module and of the runtime panic functions, as well
+// as those matching one of the presets. Frames defined in test files are never dropped, and the
+// frames are returned untouched if all of them would be dropped.
+func trimFrames(frames []runtime.Frame, presets []SkipPreset) []runtime.Frame {
+	for i, fr := range frames {
+		if strings.HasSuffix(fr.File, "_test.go") {
+			return frames[i:]
+		}
+		skip := ownFramePrefixes.matches(fr)
+		for _, preset := range presets {
+			skip = skip || preset.matches(fr)
+		}
+		if !skip {
+			return frames[i:]
+		}
+	}
+	return frames
+}
+
+// ParseStack parses a stack trace in the textual format of the Go runtime, as returned by
+// debug.Stack or written when a program crashes because of a panic, into frames. Only the first
+// goroutine of the text is parsed, which is the current or panicking one. The frames of
+// debug.Stack itself are omitted.
+//
+// This allows hooks which only have the text of a stack trace, such as log forwarders, to report
+// it as a real trace, see NewStackError.
+func ParseStack(stack []byte) []runtime.Frame {
+	var frames []runtime.Frame
+	lines := strings.Split(strings.Replace(string(stack), "\r\n", "\n", -1), "\n")
+	inGoroutine := false
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if !inGoroutine {
+			inGoroutine = strings.HasPrefix(line, "goroutine ") && strings.HasSuffix(line, ":")
+			continue
+		}
+		if line == "" {
+			if len(frames) > 0 {
+				break
+			}
+			continue
+		}
+		if i+1 >= len(lines) || !strings.HasPrefix(lines[i+1], "\t") {
+			continue
+		}
+		file, lineno := parseStackLocation(strings.TrimSpace(lines[i+1]))
+		i++
+		function := parseStackFunction(line)
+		if function == "runtime/debug.Stack" || function == "runtime/debug.PrintStack" {
+			continue
+		}
+		frames = append(frames, runtime.Frame{Function: function, File: file, Line: lineno})
+	}
+	return frames
+}
+
+// parseStackFunction returns the function of a line such as "main.(*T).f(0x1, {0x2, 0x3})",
+// "main.f(...)" or "created by main.g in goroutine 1".
+func parseStackFunction(line string) string {
+	if strings.HasPrefix(line, "created by ") {
+		line = strings.TrimPrefix(line, "created by ")
+		if i := strings.Index(line, " in goroutine "); i >= 0 {
+			line = line[:i]
+		}
+		return line
+	}
+	if !strings.HasSuffix(line, ")") {
+		return line
+	}
+	// Drop the argument list, the last parenthesized group of the line.
+	depth := 0
+	for i := len(line) - 1; i >= 0; i-- {
+		switch line[i] {
+		case ')':
+			depth++
+		case '(':
+			depth--
+			if depth == 0 {
+				return line[:i]
+			}
+		}
+	}
+	return line
+}
+
+// parseStackLocation returns the file and line of a line such as "/src/main.go:12 +0x1d".
+func parseStackLocation(line string) (string, int) {
+	if i := strings.LastIndex(line, " +0x"); i >= 0 {
+		line = line[:i]
+	}
+	i := strings.LastIndex(line, ":")
+	if i < 0 {
+		return line, 0
+	}
+	lineno, err := strconv.Atoi(line[i+1:])
+	if err != nil {
+		return line, 0
+	}
+	return line[:i], lineno
+}
+
+// A StackError is an error with a stack trace given as frames, such as those parsed by ParseStack.
+// It implements Stacker, so its frames are reported as its trace instead of the stack of the
+// caller.
+type StackError struct {
+	Message string
+	Frames  []runtime.Frame
+}
+
+// NewStackError returns a StackError with the frames parsed from the textual stack trace. When
+// message is empty and the text is the output of a crash, the message is the value of the panic,
+// e.g. "boom" for a text starting with "panic: boom".
+func NewStackError(message string, stack []byte) *StackError {
+	if message == "" {
+		for _, line := range strings.Split(string(stack), "\n") {
+			if strings.HasPrefix(line, "panic: ") {
+				message = strings.TrimPrefix(line, "panic: ")
+				if i := strings.Index(message, " [recovered"); i >= 0 {
+					message = message[:i]
+				}
+				break
+			}
+		}
+	}
+	return &StackError{Message: message, Frames: ParseStack(stack)}
+}
+
+// Error returns the message of the error.
+func (e *StackError) Error() string {
+	return e.Message
+}
+
+// Stack returns the frames of the error.
+func (e *StackError) Stack() []runtime.Frame {
+	return e.Frames
+}
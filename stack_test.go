@@ -1,24 +1,33 @@
 package rollbar
 
 import (
+	"runtime"
+	"runtime/debug"
 	"strings"
 	"testing"
 )
 
 func TestBuildStack(t *testing.T) {
-	frame := buildStack(getCallersFrames(0))[0]
+	frames, line := getCallersFrames(0), callerLine()
+	frame := buildStack(frames)[0]
 
-	if !strings.HasSuffix(frame.Filename, "rollbar-go/stack_test.go") {
+	if !strings.HasSuffix(frame.Filename, "/stack_test.go") {
 		t.Errorf("got: %s", frame.Filename)
 	}
 	if frame.Method != "rollbar-go.TestBuildStack" {
 		t.Errorf("got: %s", frame.Method)
 	}
-	if frame.Line != 9 {
+	if frame.Line != line {
 		t.Errorf("got: %d", frame.Line)
 	}
 }
 
+// callerLine returns the line of its call.
+func callerLine() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line
+}
+
 func TestStackFingerprint(t *testing.T) {
 	tests := []struct {
 		Fingerprint string
@@ -70,3 +79,92 @@ func TestShortenFilePath(t *testing.T) {
 		}
 	}
 }
+
+func TestTrimFrames(t *testing.T) {
+	frames := []runtime.Frame{
+		{Function: "github.com/rollbar/rollbar-go.(*Client).ErrorWithStackSkip", File: "/src/client.go"},
+		{Function: "github.com/rollbar/rollbar-go/contrib/gin.Middleware.func1.1", File: "/src/rollbargin.go"},
+		{Function: "runtime.gopanic", File: "/go/src/runtime/panic.go"},
+		{Function: "runtime.sigpanic", File: "/go/src/runtime/signal_unix.go"},
+		{Function: "github.com/sirupsen/logrus.(*Entry).fireHooks", File: "/src/logrus/entry.go"},
+		{Function: "main.handler", File: "/src/main.go"},
+	}
+
+	trimmed := trimFrames(frames, nil)
+	if len(trimmed) != 2 || trimmed[0].Function != "github.com/sirupsen/logrus.(*Entry).fireHooks" {
+		t.Errorf("expected own frames to be dropped, got: %v", trimmed)
+	}
+
+	trimmed = trimFrames(frames, []SkipPreset{SkipLogrusHook})
+	if len(trimmed) != 1 || trimmed[0].Function != "main.handler" {
+		t.Errorf("expected preset frames to be dropped, got: %v", trimmed)
+	}
+
+	trimmed = trimFrames(frames[:4], nil)
+	if len(trimmed) != 4 {
+		t.Errorf("expected all frames to be kept when none would remain, got: %v", trimmed)
+	}
+
+	testFrames := []runtime.Frame{
+		{Function: "github.com/rollbar/rollbar-go.TestTrimFrames", File: "/src/stack_test.go"},
+	}
+	if trimmed = trimFrames(testFrames, nil); len(trimmed) != 1 {
+		t.Errorf("expected test frames to be kept, got: %v", trimmed)
+	}
+}
+
+const testPanicOutput = `panic: boom [recovered]
+	panic: boom
+
+goroutine 7 [running]:
+main.(*Worker).process(0xc000010018, {0x4b2f60, 0x5})
+	/src/app/worker.go:42 +0x65
+main.run(...)
+	/src/app/main.go:17
+created by main.main in goroutine 1
+	/src/app/main.go:12 +0x1d
+
+goroutine 1 [chan receive]:
+main.main()
+	/src/app/main.go:13 +0x2a
+`
+
+func TestParseStack(t *testing.T) {
+	frames := ParseStack([]byte(testPanicOutput))
+	expected := []runtime.Frame{
+		{Function: "main.(*Worker).process", File: "/src/app/worker.go", Line: 42},
+		{Function: "main.run", File: "/src/app/main.go", Line: 17},
+		{Function: "main.main", File: "/src/app/main.go", Line: 12},
+	}
+	if len(frames) != len(expected) {
+		t.Fatal("expected the frames of the first goroutine, got:", frames)
+	}
+	for i := range expected {
+		if frames[i] != expected[i] {
+			t.Errorf("frame %d: expected %v, got: %v", i, expected[i], frames[i])
+		}
+	}
+
+	frames = ParseStack(debug.Stack())
+	if len(frames) == 0 || !strings.HasSuffix(frames[0].Function, "TestParseStack") {
+		t.Error("expected the stack to start in the test, got:", frames)
+	}
+	if !strings.HasSuffix(frames[0].File, "stack_test.go") || frames[0].Line == 0 {
+		t.Error("expected the location of the test, got:", frames[0])
+	}
+}
+
+func TestNewStackError(t *testing.T) {
+	client := testClient()
+	client.ErrorWithLevel(CRIT, NewStackError("", []byte(testPanicOutput)))
+
+	data := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	trace := data["body"].(map[string]interface{})["trace_chain"].([]map[string]interface{})[0]
+	if trace["exception"].(map[string]interface{})["message"] != "boom" {
+		t.Error("expected the message of the panic, got:", trace["exception"])
+	}
+	frames := trace["frames"].(stack)
+	if len(frames) != 3 || frames[0].Method != "main.(*Worker).process" || frames[0].Line != 42 {
+		t.Error("expected the parsed frames, got:", frames)
+	}
+}
@@ -0,0 +1,43 @@
+package rollbar
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// StatusLevels maps the status codes of error responses, 400 and above, to the level at which the
+// middleware reports them, see WithMiddlewareStatusLevels. Keys are either status codes, e.g.
+// "429", or classes of status codes, e.g. "5xx"; status codes take precedence over their class. An
+// empty level, like a missing status code, means the responses are not reported.
+type StatusLevels map[string]string
+
+// DefaultStatusLevels reports server errors as errors and throttled requests as warnings, while
+// ignoring the other client errors. It is opt-in, with WithMiddlewareStatusLevels or the
+// WithStatusLevels options of the contrib packages, rather than applied by default, so that
+// upgrading does not change what is reported: by default the middleware of this package only
+// reports panics, as the handlers usually report the errors they respond with, and the contrib
+// middleware report the server errors only.
+var DefaultStatusLevels = StatusLevels{
+	"5xx": ERR,
+	"429": WARN,
+	"4xx": "",
+}
+
+// Level returns the level at which responses with the given status code are reported, or an empty
+// string if they are not.
+func (levels StatusLevels) Level(status int) string {
+	if level, ok := levels[strconv.Itoa(status)]; ok {
+		return level
+	}
+	return levels[strconv.Itoa(status/100)+"xx"]
+}
+
+// statusMessage returns the message reported for a response with the given status code, e.g.
+// "server error: 503 Service Unavailable".
+func statusMessage(status int) string {
+	kind := "client error"
+	if status >= http.StatusInternalServerError {
+		kind = "server error"
+	}
+	return kind + ": " + strconv.Itoa(status) + " " + http.StatusText(status)
+}
@@ -0,0 +1,126 @@
+package rollbar
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// maxAPIMessage is the max number of bytes read from the responses of the API rejecting items.
+const maxAPIMessage = 4096
+
+// A SendErrorFunc is called with the body of an item which could not be sent, after all retries,
+// and the error of the last attempt.
+type SendErrorFunc func(body map[string]interface{}, err error)
+
+// strictReporter is implemented by the transports able to surface the rejections of the API.
+type strictReporter interface {
+	SetStrict(strict bool)
+	SetSendErrorHandler(handler SendErrorFunc)
+}
+
+// SetStrict sets whether the errors of the items rejected by the API carry the message of the API,
+// e.g. the reason a payload fails validation: the statuses without a more specific error are
+// wrapped as ErrRejected rather than ErrHTTPError in the APIError returned, and the message is
+// logged along with the status. The synchronous transport returns the error from Send; use
+// SetSendErrorHandler to receive the errors of the asynchronous transport.
+func (t *baseTransport) SetStrict(strict bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.strict = strict
+}
+
+// SetSendErrorHandler sets the function called with each item which could not be sent, after all
+// retries, and the error of the last attempt. It is called from the goroutine sending items, so it
+// must not block. The default value is nil, which calls nothing.
+func (t *baseTransport) SetSendErrorHandler(handler SendErrorFunc) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.onSendError = handler
+}
+
+// sendFailed calls the handler set with SetSendErrorHandler, if any.
+func (t *baseTransport) sendFailed(body map[string]interface{}, err error) {
+	t.lock.RLock()
+	handler := t.onSendError
+	t.lock.RUnlock()
+	if handler != nil && err != nil {
+		handler(body, err)
+	}
+}
+
+func (t *interceptedTransport) SetStrict(strict bool) {
+	if inner, ok := t.Transport.(strictReporter); ok {
+		inner.SetStrict(strict)
+	}
+}
+
+func (t *interceptedTransport) SetSendErrorHandler(handler SendErrorFunc) {
+	if inner, ok := t.Transport.(strictReporter); ok {
+		inner.SetSendErrorHandler(handler)
+	}
+}
+
+// SetStrict sets whether the destinations supporting it surface the messages of the API.
+func (t *FanOutTransport) SetStrict(strict bool) {
+	for _, d := range t.destinations {
+		if s, ok := d.(strictReporter); ok {
+			s.SetStrict(strict)
+		}
+	}
+}
+
+// SetSendErrorHandler sets the function called with each item which a destination supporting it
+// could not send.
+func (t *FanOutTransport) SetSendErrorHandler(handler SendErrorFunc) {
+	for _, d := range t.destinations {
+		if s, ok := d.(strictReporter); ok {
+			s.SetSendErrorHandler(handler)
+		}
+	}
+}
+
+// readExcerpt returns the beginning of the body of a response of the API, up to maxAPIMessage
+// bytes.
+func readExcerpt(body io.Reader) string {
+	data, _ := ioutil.ReadAll(io.LimitReader(body, maxAPIMessage))
+	return strings.TrimSpace(string(data))
+}
+
+// apiErrorMessage returns the error message of a response of the API given the beginning of its
+// body, {"err": 1, "message": "..."}, or the excerpt itself if it is not in this format.
+func apiErrorMessage(excerpt string) string {
+	var response struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal([]byte(excerpt), &response) == nil && response.Message != "" {
+		return response.Message
+	}
+	return excerpt
+}
+
+// SetStrict sets whether the transport of the client surfaces the rejections of items by the API
+// with their message, see SyncTransport.SetStrict. Transports which do not support it are left
+// unchanged and an error is logged.
+func (c *Client) SetStrict(strict bool) {
+	s, ok := c.Transport.(strictReporter)
+	if !ok {
+		rollbarError(nil, "transport %T does not support strict mode", c.Transport)
+		return
+	}
+	s.SetStrict(strict)
+}
+
+// SetSendErrorHandler sets the function called by the transport of the client with each item which
+// could not be sent, after all retries, and the error of the last attempt, which is how the errors
+// of the asynchronous transport reach the application. Transports which do not support it are
+// left unchanged and an error is logged.
+func (c *Client) SetSendErrorHandler(handler SendErrorFunc) {
+	s, ok := c.Transport.(strictReporter)
+	if !ok {
+		rollbarError(nil, "transport %T does not support send error handlers", c.Transport)
+		return
+	}
+	s.SetSendErrorHandler(handler)
+}
@@ -0,0 +1,75 @@
+package rollbar
+
+import (
+	"sync"
+	"time"
+)
+
+// suppressionCounter counts the occurrences of each distinct item dropped by the rate limits of a
+// transport, so that the next item of the same fingerprint, or title, which is sent carries their
+// number as custom.suppressed_count and the time range in which they occurred as
+// custom.suppression_window. Items are identified like by the escalation policy, see
+// occurrenceKey.
+type suppressionCounter struct {
+	entries map[string]*suppression
+	lock    sync.Mutex
+}
+
+type suppression struct {
+	count       int
+	first, last time.Time
+}
+
+// suppress counts the suppressed occurrence described by body at now. Occurrences of new items
+// are not counted once maxOccurrenceEntries items are tracked.
+func (sc *suppressionCounter) suppress(body map[string]interface{}, now time.Time) {
+	data, ok := body["data"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	key := occurrenceKey(data)
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+	entry, ok := sc.entries[key]
+	if !ok {
+		if len(sc.entries) >= maxOccurrenceEntries {
+			return
+		}
+		if sc.entries == nil {
+			sc.entries = map[string]*suppression{}
+		}
+		entry = &suppression{first: now}
+		sc.entries[key] = entry
+	}
+	entry.count++
+	entry.last = now
+}
+
+// attach adds the occurrences suppressed since the last item described like body was sent to its
+// custom data, and resets their count.
+func (sc *suppressionCounter) attach(body map[string]interface{}) {
+	data, ok := body["data"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	key := occurrenceKey(data)
+	sc.lock.Lock()
+	entry, ok := sc.entries[key]
+	delete(sc.entries, key)
+	sc.lock.Unlock()
+	if !ok {
+		return
+	}
+	// The custom data is copied as it may be shared with the configuration of the client.
+	existing, _ := data["custom"].(map[string]interface{})
+	custom := make(map[string]interface{}, len(existing)+2)
+	for k, v := range existing {
+		custom[k] = v
+	}
+	custom["suppressed_count"] = entry.count
+	custom["suppression_window"] = map[string]interface{}{
+		"start": entry.first.Unix(),
+		"end":   entry.last.Unix(),
+	}
+	data["custom"] = custom
+}
@@ -0,0 +1,58 @@
+package rollbar
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSuppressedCount(t *testing.T) {
+	var customs []map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		custom, _ := body["data"].(map[string]interface{})["custom"].(map[string]interface{})
+		customs = append(customs, custom)
+	}))
+	defer ts.Close()
+
+	start := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	clock := &fakeClock{now: start}
+	transport := NewSyncTransport("token", ts.URL)
+	transport.SetLogger(&SilentClientLogger{})
+	transport.SetClock(clock)
+	transport.SetItemsPerMinute(2)
+	item := func(title string) map[string]interface{} {
+		return map[string]interface{}{"data": map[string]interface{}{
+			"title":  title,
+			"custom": map[string]interface{}{"job": "import"},
+		}}
+	}
+
+	transport.Send(item("timeout"))
+	transport.Send(item("refused"))
+	for i := 0; i < 3; i++ {
+		clock.now = clock.now.Add(time.Second)
+		transport.Send(item("timeout"))
+	}
+	clock.now = start.Add(time.Minute)
+	transport.Send(item("refused"))
+	transport.Send(item("timeout"))
+
+	if len(customs) != 4 {
+		t.Fatal("expected 4 items to be sent, got:", len(customs))
+	}
+	if _, ok := customs[2]["suppressed_count"]; ok {
+		t.Error("expected no suppressed count for items without suppressed occurrences, got:", customs[2])
+	}
+	custom := customs[3]
+	if custom["suppressed_count"] != float64(3) || custom["job"] != "import" {
+		t.Error("wrong custom data, got:", custom)
+	}
+	window := custom["suppression_window"].(map[string]interface{})
+	if window["start"] != float64(start.Add(time.Second).Unix()) || window["end"] != float64(start.Add(3*time.Second).Unix()) {
+		t.Error("wrong suppression window, got:", window)
+	}
+}
@@ -32,27 +32,27 @@ func NewSyncTransport(token, endpoint string) *SyncTransport {
 // If the access token has not been set or is empty then this will
 // not send anything and will return nil.
 func (t *SyncTransport) Send(body map[string]interface{}) error {
+	ensureItemUUID(body)
 	return t.doSend(body, t.RetryAttempts)
 }
 
 func (t *SyncTransport) doSend(body map[string]interface{}, retriesLeft int) error {
-	elapsedTime := time.Now().Sub(t.startTime).Seconds()
-	if elapsedTime < 0 || elapsedTime >= 60 {
-		t.startTime = time.Now()
-		t.perMinCounter = 0
-	}
-	if t.shouldSend() {
+	t.resetCounters(t.now())
+	if t.shouldSend(body) {
 		canRetry, err := t.post(body)
 		if err != nil {
 			if !canRetry || retriesLeft <= 0 {
+				t.dropped(body, DropReasonFailed)
 				if t.PrintPayloadOnError {
-					writePayloadToStderr(t.Logger, body)
+					writePayloadToStderr(t.Logger, body, t.CompressPayloadOnError)
 				}
+				t.sendFailed(body, err)
 				return err
 			}
+			t.metrics.retried()
 			return t.doSend(body, retriesLeft-1)
 		} else {
-			t.perMinCounter++
+			t.countSent(body)
 		}
 	}
 	return nil
@@ -1,7 +1,15 @@
 package rollbar
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestSyncTransportSend(t *testing.T) {
@@ -84,3 +92,235 @@ func TestSyncTransportNotSend(t *testing.T) {
 		t.Error("shouldSend check failed")
 	}
 }
+
+func TestSyncTransportItemsPerMinuteByLevel(t *testing.T) {
+	transport := NewSyncTransport("", "")
+	transport.SetLogger(&SilentClientLogger{})
+	transport.SetItemsPerMinute(1)
+	transport.SetItemsPerMinuteByLevel(map[string]int{DEBUG: 1, CRIT: 0})
+	item := func(level string) map[string]interface{} {
+		return map[string]interface{}{"data": map[string]interface{}{"level": level}}
+	}
+
+	for _, level := range []string{DEBUG, DEBUG, CRIT, CRIT, CRIT, ERR, ERR} {
+		transport.Send(item(level))
+	}
+	if transport.perMinCounter != 1 || transport.levelCounters[DEBUG] != 1 || transport.levelCounters[CRIT] != 3 {
+		t.Error("wrong counters, got:", transport.perMinCounter, transport.levelCounters)
+	}
+	if dropped := transport.Metrics().Dropped; dropped != 2 {
+		t.Error("expected a debug and an error item to be dropped, got:", dropped)
+	}
+}
+
+func TestSyncTransportItemsPerMinuteByLevelConcurrent(t *testing.T) {
+	transport := NewSyncTransport("", "")
+	transport.SetLogger(&SilentClientLogger{})
+	transport.SetItemsPerMinuteByLevel(map[string]int{DEBUG: 0})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		level := DEBUG
+		if i%2 == 1 {
+			level = ERR
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			transport.Send(map[string]interface{}{"data": map[string]interface{}{"level": level}})
+		}()
+	}
+	wg.Wait()
+
+	if transport.perMinCounter != 25 || transport.levelCounters[DEBUG] != 25 {
+		t.Error("wrong counters, got:", transport.perMinCounter, transport.levelCounters)
+	}
+}
+
+func TestSyncTransportItemUUID(t *testing.T) {
+	var uuids []interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		uuids = append(uuids, body["data"].(map[string]interface{})["uuid"])
+		if len(uuids) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer ts.Close()
+
+	transport := NewSyncTransport("token", ts.URL)
+	transport.SetLogger(&SilentClientLogger{})
+	transport.Send(map[string]interface{}{"data": map[string]interface{}{"title": "built by hand"}})
+
+	if len(uuids) != 2 || uuids[0] == nil || uuids[0] == "" || uuids[0] != uuids[1] {
+		t.Error("expected the retry to carry the same UUID, got:", uuids)
+	}
+}
+
+func TestSyncTransportTypedErrors(t *testing.T) {
+	status := http.StatusTooManyRequests
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.Header().Set("X-Rate-Limit-Limit", "5000")
+		w.Header().Set("X-Rate-Limit-Remaining", "0")
+		w.Header().Set("X-Rate-Limit-Reset", "1700000000")
+		w.WriteHeader(status)
+		w.Write([]byte(`{"err": 1, "message": "rejected"}`))
+	}))
+	defer ts.Close()
+
+	transport := NewSyncTransport("token", ts.URL)
+	transport.SetLogger(&SilentClientLogger{})
+	transport.SetRetryAttempts(0)
+	body := map[string]interface{}{
+		"hello": "world",
+	}
+
+	err := transport.Send(body)
+	var rateLimited ErrRateLimited
+	if !errors.As(err, &rateLimited) || rateLimited.RetryAfter != 30*time.Second {
+		t.Error("expected ErrRateLimited with RetryAfter, got:", err)
+	}
+	if !errors.Is(err, ErrRateLimited{}) || !errors.Is(err, ErrHTTPError(http.StatusTooManyRequests)) {
+		t.Error("expected the error to match ErrRateLimited and ErrHTTPError, got:", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests || !apiErr.Retryable {
+		t.Fatal("expected a retryable APIError, got:", err)
+	}
+	expected := RateLimit{Limit: 5000, Remaining: 0, Reset: time.Unix(1700000000, 0)}
+	if apiErr.RateLimit != expected || apiErr.Body != `{"err": 1, "message": "rejected"}` {
+		t.Error("expected the rate limit and body of the response, got:", apiErr.RateLimit, apiErr.Body)
+	}
+
+	status = http.StatusForbidden
+	err = transport.Send(body)
+	if !errors.Is(err, ErrUnauthorized{}) {
+		t.Error("expected ErrUnauthorized, got:", err)
+	}
+	var httpErr ErrHTTPError
+	if !errors.As(err, &httpErr) || httpErr != http.StatusForbidden {
+		t.Error("expected ErrHTTPError 403, got:", err)
+	}
+
+	status = http.StatusRequestEntityTooLarge
+	if err = transport.Send(body); !errors.Is(err, ErrPayloadTooLarge{}) {
+		t.Error("expected ErrPayloadTooLarge, got:", err)
+	}
+
+	status = http.StatusUnprocessableEntity
+	err = transport.Send(body)
+	if !errors.As(err, &apiErr) || apiErr.Err != ErrHTTPError(http.StatusUnprocessableEntity) || apiErr.Retryable {
+		t.Error("expected a permanent APIError wrapping ErrHTTPError, got:", err)
+	}
+}
+
+type bufferLogger struct {
+	lines []string
+}
+
+func (l *bufferLogger) Printf(format string, args ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, args...))
+}
+
+func TestSyncTransportCompressPayloadOnError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	logger := &bufferLogger{}
+	transport := NewSyncTransport("token", ts.URL)
+	transport.SetLogger(logger)
+	transport.SetRetryAttempts(0)
+	transport.SetCompressPayloadOnError(true)
+	body := map[string]interface{}{
+		"data": map[string]interface{}{"title": strings.Repeat("goroutine dump ", 1000)},
+	}
+	transport.Send(body)
+
+	line := logger.lines[len(logger.lines)-1]
+	if !strings.HasPrefix(line, compressedPayloadHeader) || len(line) > 1000 {
+		t.Fatal("expected a short compressed line, got:", len(line), line[:50])
+	}
+	payload, err := DecodePayload(line)
+	if err != nil {
+		t.Fatal("failed to decode the payload:", err)
+	}
+	if payload["data"].(map[string]interface{})["title"] != body["data"].(map[string]interface{})["title"] {
+		t.Error("expected the decoded payload to match")
+	}
+}
+
+func TestSyncTransportMetrics(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch requests {
+		case 1:
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer ts.Close()
+
+	transport := NewSyncTransport("token", ts.URL)
+	transport.SetLogger(&SilentClientLogger{})
+	transport.SetPrintPayloadOnError(false)
+	transport.SetRetryAttempts(1)
+	body := map[string]interface{}{"hello": "world"}
+
+	transport.Send(body)
+	transport.Send(body)
+	transport.SetItemsPerMinute(1)
+	transport.Send(body)
+
+	metrics := transport.Metrics()
+	if metrics.Sent != 1 || metrics.Retried != 1 || metrics.Failed != 1 || metrics.Dropped != 1 {
+		t.Error("wrong metrics, got:", metrics)
+	}
+	if metrics.LastSendLatency <= 0 {
+		t.Error("expected the latency of the last send to be recorded")
+	}
+}
+
+func TestSyncTransportStrict(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"err": 1, "message": "Invalid format. data.body is missing"}`))
+	}))
+	defer ts.Close()
+
+	transport := NewSyncTransport("token", ts.URL)
+	logger := &bufferLogger{}
+	transport.SetLogger(logger)
+	transport.SetPrintPayloadOnError(false)
+	var handled error
+	transport.SetSendErrorHandler(func(body map[string]interface{}, err error) {
+		handled = err
+	})
+	body := map[string]interface{}{"data": map[string]interface{}{}}
+
+	err := transport.Send(body)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Err != ErrHTTPError(http.StatusUnprocessableEntity) || handled != err {
+		t.Error("expected ErrHTTPError without strict mode, got:", err, handled)
+	}
+
+	transport.SetStrict(true)
+	err = transport.Send(body)
+	var rejected ErrRejected
+	if !errors.As(err, &rejected) || rejected.StatusCode != 422 || rejected.Message != "Invalid format. data.body is missing" {
+		t.Error("expected ErrRejected with the message of the API, got:", err)
+	}
+	if !errors.Is(err, ErrHTTPError(http.StatusUnprocessableEntity)) || handled != err {
+		t.Error("expected the error to match ErrHTTPError and to be handled, got:", err, handled)
+	}
+	if !strings.Contains(strings.Join(logger.lines, "\n"), "data.body is missing") {
+		t.Error("expected the message of the API to be logged, got:", logger.lines)
+	}
+}
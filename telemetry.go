@@ -1,12 +1,15 @@
 package rollbar
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
 	"regexp"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -21,17 +24,88 @@ type Telemetry struct {
 	Network struct {
 		Proxied      http.RoundTripper
 		ScrubHeaders *regexp.Regexp
+		// AllowedHeaders are the only headers captured when not empty, see SetAllowedHeaders.
+		AllowedHeaders []string
 
 		enableReqHeaders bool
 		enableResHeaders bool
 	}
 	Queue *Queue
+
+	filter   func(event map[string]interface{}) bool
+	minLevel string
+	clock    Clock
+	lock     sync.RWMutex
+}
+
+// SetFilter sets the function deciding which telemetry events are captured: events for which it
+// returns false, such as the network events of health checks, are discarded before they are
+// queued, so that they do not evict useful events. A nil filter, the default, captures all events.
+func (t *Telemetry) SetFilter(filter func(event map[string]interface{}) bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.filter = filter
+}
+
+// SetMinLevel sets the minimum level of the captured telemetry events, ranked like with
+// Client.SetTelemetryLevel, e.g. "info" to discard debug logs. The default value is "", which
+// captures events of all levels.
+func (t *Telemetry) SetMinLevel(level string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.minLevel = level
+}
+
+// MinLevel returns the minimum level of the captured telemetry events, see SetMinLevel.
+func (t *Telemetry) MinLevel() string {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	return t.minLevel
+}
+
+// setHeaderFilters sets the headers scrubbed from the network events and the only ones captured,
+// under the lock so that the events being captured use either the previous or the new filters.
+func (t *Telemetry) setHeaderFilters(scrubHeaders *regexp.Regexp, allowed []string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.Network.ScrubHeaders = scrubHeaders
+	t.Network.AllowedHeaders = allowed
+}
+
+// headerFilters returns the headers scrubbed from the network events and the only ones captured.
+func (t *Telemetry) headerFilters() (*regexp.Regexp, []string) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	return t.Network.ScrubHeaders, t.Network.AllowedHeaders
+}
+
+// record queues the telemetry event in the telemetry queue of ctx if it carries one, see
+// NewTelemetryContext, or in the queue of t otherwise, unless it is discarded by the level or the
+// filter set on t.
+func (t *Telemetry) record(ctx context.Context, event map[string]interface{}) {
+	t.lock.RLock()
+	filter, minLevel := t.filter, t.minLevel
+	t.lock.RUnlock()
+	if min, ok := telemetryLevels[minLevel]; ok {
+		level, _ := event["level"].(string)
+		if rank, ok := telemetryLevels[level]; ok && rank < min {
+			return
+		}
+	}
+	if filter != nil && !filter(event) {
+		return
+	}
+	if queue, ok := TelemetryFromContext(ctx); ok {
+		queue.Push(event)
+		return
+	}
+	t.Queue.Push(event)
 }
 
 // Write is the writer for telemetry logs
 func (t *Telemetry) Write(p []byte) (int, error) {
 	telemetryData := t.populateLoggerBody(p)
-	t.Queue.Push(telemetryData)
+	t.record(context.Background(), telemetryData)
 	return t.Logger.Writer.Write(p)
 }
 
@@ -44,16 +118,31 @@ func (t *Telemetry) RoundTrip(req *http.Request) (res *http.Response, e error) {
 		fmt.Printf("Error: %v", e)
 	}
 	telemetryData := t.populateTransporterBody(req, res)
-	t.Queue.Push(telemetryData)
+	t.record(req.Context(), telemetryData)
 	return
 }
 
+// NewTelemetryContext returns a new Context that carries a telemetry queue of its own, e.g. for
+// the duration of a request, see WithMiddlewareRequestTelemetry. Items reported within this context
+// carry the telemetry events captured within it, such as those captured with
+// CaptureTelemetryEventWithContext and the network events of the requests made with it, instead of
+// the events of the client, so that the breadcrumbs of concurrent requests do not interleave.
+func NewTelemetryContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, telemetryKey, NewQueue(TelemetryQueueSize))
+}
+
+// TelemetryFromContext returns the telemetry queue stored in ctx, if any.
+func TelemetryFromContext(ctx context.Context) (*Queue, bool) {
+	queue, ok := ctx.Value(telemetryKey).(*Queue)
+	return queue, ok
+}
+
 func (t *Telemetry) populateLoggerBody(p []byte) map[string]interface{} {
 	var data = map[string]interface{}{}
 	message := map[string]interface{}{"message": string(p)}
 	data["body"] = message
 	data["source"] = "client"
-	data["timestamp_ms"] = time.Now().UnixNano() / int64(time.Millisecond)
+	data["timestamp_ms"] = t.now().UnixNano() / int64(time.Millisecond)
 	data["type"] = "log"
 	data["level"] = "log"
 	return data
@@ -62,22 +151,19 @@ func (t *Telemetry) populateLoggerBody(p []byte) map[string]interface{} {
 func (t *Telemetry) populateTransporterBody(req *http.Request, res *http.Response) map[string]interface{} {
 	var data = map[string]interface{}{}
 	var dataBody = map[string]interface{}{}
+	scrubHeaders, allowed := t.headerFilters()
 	dataBody["status_code"] = nil
 	data["level"] = "info"
 	if res != nil {
 		dataBody["status_code"] = res.StatusCode
-		if res.StatusCode >= http.StatusInternalServerError {
-			data["level"] = "critical"
-		} else if res.StatusCode >= http.StatusBadRequest {
-			data["level"] = "error"
-		}
+		data["level"] = networkTelemetryLevel(res.StatusCode)
 
 		if t.Network.enableResHeaders {
 			var dataHeaders = map[string][]string{}
-			for k, v := range res.Header {
+			for k, v := range allowedHeaders(res.Header, allowed) {
 				dataHeaders[k] = v
 			}
-			filteredDataHeaders := filterFlatten(t.Network.ScrubHeaders, dataHeaders, nil)
+			filteredDataHeaders := filterFlatten(scrubHeaders, dataHeaders, nil)
 			response := map[string]interface{}{"headers": filteredDataHeaders}
 			dataBody["response"] = response
 		}
@@ -88,22 +174,98 @@ func (t *Telemetry) populateTransporterBody(req *http.Request, res *http.Respons
 
 	if t.Network.enableReqHeaders {
 		var dataHeaders = map[string][]string{}
-		for k, v := range req.Header {
+		for k, v := range allowedHeaders(req.Header, allowed) {
 			dataHeaders[k] = v
 		}
-		filteredDataHeaders := filterFlatten(t.Network.ScrubHeaders, dataHeaders, nil)
+		filteredDataHeaders := filterFlatten(scrubHeaders, dataHeaders, nil)
 		dataBody["request_headers"] = filteredDataHeaders
 	}
 	data["body"] = dataBody
 	data["source"] = "client"
-	data["timestamp_ms"] = time.Now().UnixNano() / int64(time.Millisecond)
+	data["timestamp_ms"] = t.now().UnixNano() / int64(time.Millisecond)
 	data["type"] = "network"
 	return data
 }
 
+// networkTelemetryLevel returns the level of a network telemetry event for a response with the
+// given status code: critical for server errors, error for client errors and info otherwise.
+func networkTelemetryLevel(status int) string {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return "critical"
+	case status >= http.StatusBadRequest:
+		return "error"
+	}
+	return "info"
+}
+
+// telemetryLevels ranks the levels of telemetry events.
+var telemetryLevels = map[string]int{
+	"debug":    0,
+	"info":     1,
+	"log":      1,
+	"warning":  2,
+	"error":    3,
+	"critical": 4,
+}
+
+// selectTelemetry returns a copy of the telemetry events whose level is at least level, limited to
+// the max most recent ones when max is positive.
+func selectTelemetry(items []interface{}, level string, max int) []interface{} {
+	min, filter := telemetryLevels[level]
+	selected := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		if filter {
+			event, _ := item.(map[string]interface{})
+			eventLevel, _ := event["level"].(string)
+			if rank, ok := telemetryLevels[eventLevel]; ok && rank < min {
+				continue
+			}
+		}
+		selected = append(selected, item)
+	}
+	if max > 0 && len(selected) > max {
+		selected = selected[len(selected)-max:]
+	}
+	return selected
+}
+
 // GetQueueItems gets all the items from the queue
 func (t *Telemetry) GetQueueItems() []interface{} {
-	return t.Queue.Items()
+	return t.Queue.Snapshot()
+}
+
+// DrainQueueItems removes all the items from the queue and returns them, e.g. to consume the
+// telemetry events once they have been reported.
+func (t *Telemetry) DrainQueueItems() []interface{} {
+	return t.Queue.Drain()
+}
+
+// QueueSize returns the size the telemetry queue was initialized with.
+func (t *Telemetry) QueueSize() int {
+	return t.Queue.size
+}
+
+// NetworkEnabled returns whether network telemetry is captured, see EnableNetworkTelemetry.
+func (t *Telemetry) NetworkEnabled() bool {
+	return t.Network.Proxied != nil
+}
+
+// NetworkRequestHeadersEnabled returns whether request headers are captured with network telemetry.
+func (t *Telemetry) NetworkRequestHeadersEnabled() bool {
+	return t.Network.enableReqHeaders
+}
+
+// NetworkResponseHeadersEnabled returns whether response headers are captured with network
+// telemetry.
+func (t *Telemetry) NetworkResponseHeadersEnabled() bool {
+	return t.Network.enableResHeaders
+}
+
+// LoggerEnabled returns whether the output of the standard logger is captured, see
+// EnableLoggerTelemetry.
+func (t *Telemetry) LoggerEnabled() bool {
+	return t.Logger.Writer != nil
 }
 
 // OptionFunc is the pointer to the optional parameter function
@@ -144,6 +306,30 @@ func SetCustomQueueSize(size int) OptionFunc {
 	}
 }
 
+// telemetryWriter records what is written to it as log telemetry events of a Client.
+type telemetryWriter struct {
+	client *Client
+	level  string
+}
+
+// Write records p as a log telemetry event, without its trailing newline. It never fails.
+func (w *telemetryWriter) Write(p []byte) (int, error) {
+	if message := strings.TrimRight(string(p), "\r\n"); message != "" {
+		w.client.RecordLogTelemetry(w.level, message)
+	}
+	return len(p), nil
+}
+
+// TelemetryWriter returns an io.Writer recording each write, such as each line written by a
+// log.Logger, as a log telemetry event with the given level. Unlike EnableLoggerTelemetry, it does
+// not change the output of the standard logger: it is meant to be plugged into a logger of the
+// application, e.g.
+//
+//	logger := log.New(io.MultiWriter(os.Stderr, client.TelemetryWriter("info")), "", log.LstdFlags)
+func (c *Client) TelemetryWriter(level string) io.Writer {
+	return &telemetryWriter{client: c, level: level}
+}
+
 // EnableLoggerTelemetry enables logger telemetry
 func EnableLoggerTelemetry() OptionFunc {
 	return func(f *Telemetry) {
@@ -163,7 +349,7 @@ func NewTelemetry(scrubHeaders *regexp.Regexp, options ...OptionFunc) *Telemetry
 	}
 
 	if scrubHeaders == nil {
-		res.Network.ScrubHeaders = regexp.MustCompile("Authorization")
+		res.Network.ScrubHeaders = regexp.MustCompile(DefaultScrubHeaders)
 	} else {
 		res.Network.ScrubHeaders = scrubHeaders
 	}
@@ -1,6 +1,7 @@
 package rollbar
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -37,6 +38,37 @@ func TestNewTelemetryWithOptions(t *testing.T) {
 	assert.Equal(t, expectedTelemetry, telemetry)
 	assert.Equal(t, client.Transport, expectedTelemetry)
 }
+
+func TestTelemetryGetters(t *testing.T) {
+	telemetry := NewTelemetry(nil)
+	assert.Equal(t, TelemetryQueueSize, telemetry.QueueSize())
+	assert.False(t, telemetry.NetworkEnabled())
+	assert.False(t, telemetry.NetworkRequestHeadersEnabled())
+	assert.False(t, telemetry.NetworkResponseHeadersEnabled())
+	assert.False(t, telemetry.LoggerEnabled())
+
+	client := http.Client{}
+	telemetry = NewTelemetry(nil, SetCustomQueueSize(10), EnableNetworkTelemetry(&client),
+		EnableNetworkTelemetryRequestHeaders(), EnableNetworkTelemetryResponseHeaders())
+	assert.Equal(t, 10, telemetry.QueueSize())
+	assert.True(t, telemetry.NetworkEnabled())
+	assert.True(t, telemetry.NetworkRequestHeadersEnabled())
+	assert.True(t, telemetry.NetworkResponseHeadersEnabled())
+	assert.False(t, telemetry.LoggerEnabled())
+}
+
+func TestRootTelemetryGetters(t *testing.T) {
+	client := http.Client{}
+	SetTelemetry(SetCustomQueueSize(20), EnableNetworkTelemetry(&client), EnableNetworkTelemetryRequestHeaders())
+	defer SetTelemetry()
+
+	assert.Equal(t, 20, CustomQueueSize())
+	assert.True(t, NetworkTelemetryEnabled())
+	assert.True(t, NetworkTelemetryRequestHeadersEnabled())
+	assert.False(t, NetworkTelemetryResponseHeadersEnabled())
+	assert.False(t, LoggerTelemetryEnabled())
+}
+
 func TestPopulateBody(t *testing.T) {
 	req := httptest.NewRequest("GET", "/some_url", nil)
 	req.Header.Set("Some_name", "some_value")
@@ -126,3 +158,53 @@ func TestWrite(t *testing.T) {
 	delete(expectedData, "timestamp_ms")
 	assert.Equal(t, item, expectedData)
 }
+
+func TestTelemetryFilterAndMinLevel(t *testing.T) {
+	telemetry := NewTelemetry(nil)
+	telemetry.SetMinLevel("info")
+	telemetry.SetFilter(func(event map[string]interface{}) bool {
+		body, _ := event["body"].(map[string]interface{})
+		return body["url"] != "http://localhost/healthz"
+	})
+	ctx := context.Background()
+
+	telemetry.record(ctx, map[string]interface{}{"type": "log", "level": "debug", "body": map[string]interface{}{}})
+	telemetry.record(ctx, map[string]interface{}{"type": "network", "level": "info", "body": map[string]interface{}{"url": "http://localhost/healthz"}})
+	telemetry.record(ctx, map[string]interface{}{"type": "log", "level": "log", "body": map[string]interface{}{"message": "kept"}})
+
+	items := telemetry.GetQueueItems()
+	assert.Equal(t, 1, len(items))
+	assert.Equal(t, "kept", items[0].(map[string]interface{})["body"].(map[string]interface{})["message"])
+	assert.Equal(t, "info", telemetry.MinLevel())
+}
+
+func TestQueueSnapshotAndDrain(t *testing.T) {
+	queue := NewQueue(2)
+	queue.Push(1)
+	queue.Push(2)
+	queue.Pop()
+	queue.Push(3)
+
+	snapshot := queue.Snapshot()
+	assert.Equal(t, []interface{}{2, 3}, snapshot)
+	snapshot[0] = "modified"
+	assert.Equal(t, []interface{}{2, 3}, queue.Snapshot())
+
+	assert.Equal(t, []interface{}{2, 3}, queue.Drain())
+	assert.Equal(t, []interface{}{}, queue.Snapshot())
+	queue.Push(4)
+	assert.Equal(t, []interface{}{4}, queue.Items())
+}
+
+func TestTelemetryWriter(t *testing.T) {
+	client := testClient()
+	logger := log.New(client.TelemetryWriter("warning"), "", 0)
+	logger.Print("disk almost full")
+
+	items := client.Telemetry.GetQueueItems()
+	assert.Equal(t, 1, len(items))
+	event := items[0].(map[string]interface{})
+	assert.Equal(t, "log", event["type"])
+	assert.Equal(t, "warning", event["level"])
+	assert.Equal(t, map[string]interface{}{"message": "disk almost full"}, event["body"])
+}
@@ -0,0 +1,61 @@
+package rollbar
+
+import "strings"
+
+// testEnvironmentPrefix is prepended to the environment of items reported in test mode.
+const testEnvironmentPrefix = "test-"
+
+// TB is the part of testing.TB used by Client.StartTest.
+type TB interface {
+	Name() string
+	Cleanup(func())
+}
+
+// SetTestMode sets whether the client reports from tests. In test mode the environment of items is
+// prefixed with "test-", so that items accidentally reported from CI are easy to identify and to
+// filter out in Rollbar. This is disabled by default.
+func (c *Client) SetTestMode(testMode bool) {
+	c.ApplyOptions(func(conf *configuration) { conf.testMode = testMode })
+}
+
+// TestMode specifies whether or not the client reports from tests.
+func (c *Client) TestMode() bool {
+	return c.configuration().testMode
+}
+
+// StartTest enables test mode and reports the name of the running test as custom.test_name with
+// every item until the test completes:
+//
+//	func TestCheckout(t *testing.T) {
+//		client.StartTest(t)
+//		...
+//	}
+//
+// The test name is set on the client, so tests sharing a client should not run in parallel.
+func (c *Client) StartTest(t TB) {
+	c.ApplyOptions(func(conf *configuration) {
+		conf.testMode = true
+		conf.testName = t.Name()
+	})
+	t.Cleanup(func() {
+		c.ApplyOptions(func(conf *configuration) { conf.testName = "" })
+	})
+}
+
+// addTestMode applies test mode to the data of an item.
+func addTestMode(configuration configuration, data map[string]interface{}) {
+	if !configuration.testMode {
+		return
+	}
+	if env, _ := data["environment"].(string); !strings.HasPrefix(env, testEnvironmentPrefix) {
+		data["environment"] = testEnvironmentPrefix + env
+	}
+	if configuration.testName != "" {
+		custom, _ := data["custom"].(map[string]interface{})
+		if custom == nil {
+			custom = map[string]interface{}{}
+		}
+		custom["test_name"] = configuration.testName
+		data["custom"] = custom
+	}
+}
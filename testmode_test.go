@@ -0,0 +1,40 @@
+package rollbar
+
+import "testing"
+
+func TestSetTestMode(t *testing.T) {
+	client := testClient()
+	client.SetTestMode(true)
+	client.Message(INFO, "hello")
+	data := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	if data["environment"] != "test-test" {
+		t.Error("expected the environment to be prefixed, got:", data["environment"])
+	}
+
+	client.SetEnvironment("test-ci")
+	client.Message(INFO, "hello")
+	data = client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	if data["environment"] != "test-ci" {
+		t.Error("expected the prefix not to be repeated, got:", data["environment"])
+	}
+}
+
+func TestStartTest(t *testing.T) {
+	client := testClient()
+	client.SetEnvironment("ci")
+	t.Run("checkout", func(t *testing.T) {
+		client.StartTest(t)
+		client.Message(ERR, "payment declined")
+	})
+
+	data := client.Transport.(*TestTransport).Body["data"].(map[string]interface{})
+	if data["environment"] != "test-ci" {
+		t.Error("expected the environment to be prefixed, got:", data["environment"])
+	}
+	if data["custom"].(map[string]interface{})["test_name"] != "TestStartTest/checkout" {
+		t.Error("expected the test name, got:", data["custom"])
+	}
+	if !client.TestMode() || client.Config().TestName != "" {
+		t.Error("expected test mode to stay enabled and the test name to be cleared")
+	}
+}
@@ -2,13 +2,15 @@ package rollbar
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
 	"net/http"
 	"net/url"
 	"reflect"
 	"regexp"
 	"runtime"
 	"strings"
-	"time"
 )
 
 // Build the main JSON structure that will be sent to Rollbar with the
@@ -16,18 +18,23 @@ import (
 func buildBody(ctx context.Context, configuration configuration, diagnostic diagnostic,
 	level, title string, extras map[string]interface{}) map[string]interface{} {
 
-	timestamp := time.Now().Unix()
+	timestamp := configuration.clock.Now().Unix()
+	environment := configuration.environment
+	if env, ok := EnvironmentFromContext(ctx); ok && env != "" {
+		environment = env
+	}
 
 	data := map[string]interface{}{
-		"environment":  configuration.environment,
+		"environment":  environment,
 		"title":        title,
 		"level":        level,
 		"timestamp":    timestamp,
+		"uuid":         configuration.idGenerator.NewID(),
 		"platform":     configuration.platform,
 		"language":     "go",
 		"code_version": configuration.codeVersion,
 		"server": map[string]interface{}{
-			"host": configuration.serverHost,
+			"host": configuration.host(),
 			"root": configuration.serverRoot,
 		},
 		"notifier": map[string]interface{}{
@@ -35,17 +42,51 @@ func buildBody(ctx context.Context, configuration configuration, diagnostic diag
 			"version": VERSION,
 			"diagnostic": map[string]interface{}{
 				"languageVersion":   diagnostic.languageVersion,
+				"goos":              runtime.GOOS,
+				"goarch":            runtime.GOARCH,
 				"configuredOptions": buildConfiguredOptions(configuration),
+				"lookups":           lookupStatuses(),
 			},
 		},
 	}
+	server := data["server"].(map[string]interface{})
+	for key, details := range configuration.platformInfo {
+		server[key] = buildCustom(details.(map[string]interface{}), nil)
+	}
+	if modules := moduleDiagnostic(configuration.modules); modules != nil {
+		notifier := data["notifier"].(map[string]interface{})
+		notifier["diagnostic"].(map[string]interface{})["modules"] = modules
+	}
 
 	custom := buildCustom(configuration.custom, extras)
+	if ctxCustom, ok := CustomFromContext(ctx); ok && ctxCustom != nil {
+		custom = buildCustom(buildCustom(configuration.custom, ctxCustom), extras)
+	}
 	if custom != nil {
 		data["custom"] = custom
 	}
 
+	addContextValues(configuration, data, ctx)
+	addTestMode(configuration, data)
+
+	if contextName, ok := ContextNameFromContext(ctx); ok && contextName != "" {
+		data["context"] = contextName
+	} else if configuration.contextName != "" {
+		data["context"] = configuration.contextName
+	}
+
+	if r, ok := RequestFromContext(ctx); ok && r != nil {
+		data["request"] = requestDetails(configuration, r)
+	} else if info, ok := RequestInfoFromContext(ctx); ok && info != nil {
+		data["request"] = requestInfoDetails(configuration, info)
+	}
+
 	person, ok := PersonFromContext(ctx)
+	if !ok && configuration.personProvider != nil {
+		r, _ := RequestFromContext(ctx)
+		person = configuration.personProvider(ctx, r)
+		ok = person != nil
+	}
 	if !ok {
 		person = &configuration.person
 	}
@@ -62,6 +103,7 @@ func buildBody(ctx context.Context, configuration configuration, diagnostic diag
 				personData[key] = value
 			}
 		}
+		scrubPerson(personData, configuration.personScrub)
 		data["person"] = personData
 	}
 
@@ -84,28 +126,84 @@ func buildCustom(custom map[string]interface{}, extras map[string]interface{}) m
 	return m
 }
 
+// contextValue is a key of context values registered with RegisterContextValue.
+type contextValue struct {
+	name string
+	key  interface{}
+}
+
+// addContextValues adds the values of the registered context keys found in ctx to
+// custom.context_values, unless a value with the same name was already added.
+func addContextValues(configuration configuration, data map[string]interface{}, ctx context.Context) {
+	if len(configuration.contextValues) == 0 || ctx == nil {
+		return
+	}
+	custom, _ := data["custom"].(map[string]interface{})
+	if custom == nil {
+		custom = map[string]interface{}{}
+	}
+	values, _ := custom["context_values"].(map[string]interface{})
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+	for _, cv := range configuration.contextValues {
+		if _, ok := values[cv.name]; ok {
+			continue
+		}
+		if v := ctx.Value(cv.key); v != nil {
+			values[cv.name] = jsonSafeValue(v)
+		}
+	}
+	if len(values) > 0 {
+		custom["context_values"] = values
+		data["custom"] = custom
+	}
+}
+
+// jsonSafeValue converts v into a value which can always be encoded as JSON, by encoding and
+// decoding it. Values which cannot be encoded, including those whose MarshalJSON or String methods
+// panic, are converted into text.
+func jsonSafeValue(v interface{}) (result interface{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = fmt.Sprintf("<%T: panic while encoding: %v>", v, r)
+		}
+	}()
+	b, err := json.Marshal(v)
+	if err == nil {
+		var decoded interface{}
+		if err = json.Unmarshal(b, &decoded); err == nil {
+			return decoded
+		}
+	}
+	return fmt.Sprintf("%+v", v)
+}
+
 func buildConfiguredOptions(configuration configuration) map[string]interface{} {
+	person := map[string]string{
+		"Id":       configuration.person.Id,
+		"Username": configuration.person.Username,
+		"Email":    configuration.person.Email,
+	}
+	scrubPersonKeys(person, configuration.personScrub, "Email", "Username")
 	return map[string]interface{}{
 		"environment":    configuration.environment,
 		"endpoint":       configuration.endpoint,
 		"platform":       configuration.platform,
 		"codeVersion":    configuration.codeVersion,
-		"serverHost":     configuration.serverHost,
+		"serverHost":     configuration.host(),
 		"serverRoot":     configuration.serverRoot,
 		"fingerprint":    configuration.fingerprint,
 		"scrubHeaders":   configuration.scrubHeaders,
 		"scrubFields":    configuration.scrubFields,
+		"scrubSecrets":   configuration.scrubSecrets,
 		"transform":      functionToString(configuration.transform),
 		"unwrapper":      functionToString(configuration.unwrapper),
 		"stackTracer":    functionToString(configuration.stackTracer),
 		"checkIgnore":    functionToString(configuration.checkIgnore),
 		"captureIp":      configuration.captureIp,
 		"itemsPerMinute": configuration.itemsPerMinute,
-		"person": map[string]string{
-			"Id":       configuration.person.Id,
-			"Username": configuration.person.Username,
-			"Email":    configuration.person.Email,
-		},
+		"person":         person,
 	}
 }
 
@@ -113,50 +211,133 @@ func addErrorToBody(configuration configuration, body map[string]interface{}, er
 	data := body["data"].(map[string]interface{})
 	errBody, fingerprint := errorBody(configuration, err, skip)
 	dataBody := errBody
-	dataBody["telemetry"] = telemetry
+	if telemetry != nil {
+		dataBody["telemetry"] = telemetry
+	}
 	data["body"] = dataBody
 	if configuration.fingerprint {
 		data["fingerprint"] = fingerprint
 	}
+	addErrorTags(configuration, data, err)
 	return data
 }
 
+// addErrorTags adds the tags returned by the error tagger for err to custom.tags, after the tags
+// which may have been given as extras.
+func addErrorTags(configuration configuration, data map[string]interface{}, err error) {
+	if configuration.errorTagger == nil {
+		return
+	}
+	tags := configuration.errorTagger(err)
+	if len(tags) == 0 {
+		return
+	}
+	custom, _ := data["custom"].(map[string]interface{})
+	if custom == nil {
+		custom = map[string]interface{}{}
+	}
+	if existing, ok := custom["tags"].([]string); ok {
+		tags = append(append([]string{}, existing...), tags...)
+	}
+	custom["tags"] = tags
+	data["custom"] = custom
+}
+
 func requestDetails(configuration configuration, r *http.Request) map[string]interface{} {
-	cleanQuery := filterParams(configuration.scrubFields, r.URL.Query())
+	info := &RequestInfo{
+		URL:     requestURL(configuration, r),
+		Method:  r.Method,
+		Headers: r.Header,
+		Query:   r.URL.Query(),
+		Form:    r.Form,
+		UserIP:  clientIP(configuration, r),
+	}
+	// The body of the request is never read here: multipart forms are only reported once parsed by
+	// the handler, e.g. with ParseMultipartForm.
+	if r.MultipartForm != nil {
+		if info.Form == nil {
+			info.Form = r.MultipartForm.Value
+		}
+		info.Files = r.MultipartForm.File
+	}
+	details := requestInfoDetails(configuration, info)
+	if route, ok := RouteFromContext(r.Context()); ok && route != nil {
+		details["route"] = route.Pattern
+		if route.Operation != "" {
+			details["operation"] = route.Operation
+		}
+		if len(route.Params) > 0 {
+			params := make(map[string]string, len(route.Params))
+			for k, v := range route.Params {
+				if configuration.fieldMatcher().matches(k) {
+					v = FILTERED
+				}
+				params[k] = v
+			}
+			details["params"] = params
+		}
+	}
+	return details
+}
+
+func requestInfoDetails(configuration configuration, info *RequestInfo) map[string]interface{} {
+	fields := configuration.fieldMatcher()
+	cleanQuery := filterParams(fields, info.Query)
 	specialHeaders := map[string]struct{}{
 		"Content-Type": struct{}{},
 	}
 
-	return map[string]interface{}{
-		"url":     r.URL.String(),
-		"method":  r.Method,
-		"headers": filterFlatten(configuration.scrubHeaders, r.Header, specialHeaders),
+	details := map[string]interface{}{
+		"url":     scrubURL(fields, info.URL),
+		"method":  info.Method,
+		"headers": filterFlatten(configuration.scrubHeaders, allowedHeaders(info.Headers, configuration.allowHeaders), specialHeaders),
 
 		// GET params
 		"query_string": url.Values(cleanQuery).Encode(),
 		"GET":          flattenValues(cleanQuery),
 
 		// POST / PUT params
-		"POST":    filterFlatten(configuration.scrubFields, r.Form, nil),
-		"user_ip": filterIp(remoteIP(r), configuration.captureIp),
+		"POST":    flattenValues(filterParams(fields, info.Form)),
+		"user_ip": filterIp(info.UserIP, configuration.captureIp, configuration.ipv4Mask, configuration.ipv6Mask),
+	}
+	if len(info.Files) > 0 {
+		details["files"] = fileDetails(configuration.fieldMatcher(), info.Files)
 	}
+	if configuration.captureCookies {
+		addCookies(configuration, details, info.Headers)
+	}
+	return details
+}
+
+// fileDetails describes the files of a multipart form by field: the name, size and content type
+// of each file. The files of the fields matching pattern are replaced by FILTERED.
+func fileDetails(fields fieldMatcher, files map[string][]*multipart.FileHeader) map[string]interface{} {
+	result := make(map[string]interface{}, len(files))
+	for field, headers := range files {
+		if fields.matches(field) {
+			result[field] = FILTERED
+			continue
+		}
+		described := make([]map[string]interface{}, 0, len(headers))
+		for _, header := range headers {
+			described = append(described, map[string]interface{}{
+				"filename":     header.Filename,
+				"size":         header.Size,
+				"content_type": header.Header.Get("Content-Type"),
+			})
+		}
+		result[field] = described
+	}
+	return result
 }
 
-// remoteIP attempts to extract the real remote IP address by looking first at the headers X-Real-IP
-// and X-Forwarded-For, and then falling back to RemoteAddr defined in http.Request
-func remoteIP(req *http.Request) string {
-	realIP := req.Header.Get("X-Real-IP")
-	if realIP != "" {
-		return realIP
-	}
-	forwardedIPs := req.Header.Get("X-Forwarded-For")
-	if forwardedIPs != "" {
-		ips := strings.Split(forwardedIPs, ", ")
-		return ips[0]
-	}
-	remoteAddr := req.RemoteAddr
-	spltRemoteAddr := strings.Split(remoteAddr, ":")
-	return spltRemoteAddr[0]
+// clientIP returns the client IP address carried by the context of the request if there is one,
+// and otherwise falls back to remoteIP.
+func clientIP(configuration configuration, req *http.Request) string {
+	if ip, ok := ClientIPFromContext(req.Context()); ok {
+		return ip
+	}
+	return remoteIP(configuration, req)
 }
 
 // filterFlatten filters sensitive information like passwords from being sent to Rollbar, and
@@ -185,16 +366,65 @@ func filterFlatten(pattern *regexp.Regexp, values map[string][]string, specialKe
 	return result
 }
 
+// allowedHeaders returns the headers whose name is in allowed, compared without regard to case, or
+// all the headers if allowed is empty. The headers are left untouched as they belong to the caller.
+func allowedHeaders(headers map[string][]string, allowed []string) map[string][]string {
+	if len(allowed) == 0 {
+		return headers
+	}
+	result := make(map[string][]string, len(allowed))
+	for name, values := range headers {
+		for _, allowedName := range allowed {
+			if strings.EqualFold(name, allowedName) {
+				result[name] = values
+				break
+			}
+		}
+	}
+	return result
+}
+
 // filterParams filters sensitive information like passwords from being sent to
-// Rollbar.
-func filterParams(pattern *regexp.Regexp, values map[string][]string) map[string][]string {
-	for key := range values {
-		if pattern.Match([]byte(key)) {
-			values[key] = []string{FILTERED}
+// Rollbar. The input values are left untouched as they may be owned by the caller.
+func filterParams(fields fieldMatcher, values map[string][]string) map[string][]string {
+	result := make(map[string][]string, len(values))
+	for key, value := range values {
+		if fields.matches(key) {
+			result[key] = []string{FILTERED}
+		} else {
+			result[key] = value
 		}
 	}
 
-	return values
+	return result
+}
+
+// scrubURL returns rawURL with the values of the query parameters matched by fields replaced by
+// FILTERED, so that the secrets filtered out of query_string and GET are not reported in the url
+// either. The other parameters are kept as is, in their original order. rawURL is returned as is if
+// it cannot be parsed or has no parameter to filter.
+func scrubURL(fields fieldMatcher, rawURL string) string {
+	if fields.pattern == nil {
+		return rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.RawQuery == "" {
+		return rawURL
+	}
+	pairs := strings.Split(u.RawQuery, "&")
+	filtered := false
+	for i, pair := range pairs {
+		key := strings.SplitN(pair, "=", 2)[0]
+		if name, err := url.QueryUnescape(key); err == nil && fields.matches(name) {
+			pairs[i] = key + "=" + FILTERED
+			filtered = true
+		}
+	}
+	if !filtered {
+		return rawURL
+	}
+	u.RawQuery = strings.Join(pairs, "&")
+	return u.String()
 }
 
 // flattenValues takes a map from strings to lists of strings and performs a lift
@@ -214,27 +444,13 @@ func flattenValues(values map[string][]string) map[string]interface{} {
 }
 
 // filterIp takes an ip address string and a capture policy and returns a possibly
-// transformed ip address string.
-func filterIp(ip string, captureIp captureIp) string {
+// transformed ip address string. Anonymized addresses keep their first v4Bits or v6Bits bits.
+func filterIp(ip string, captureIp captureIp, v4Bits, v6Bits int) string {
 	switch captureIp {
 	case CaptureIpFull:
 		return ip
 	case CaptureIpAnonymize:
-		if strings.Contains(ip, ".") {
-			parts := strings.Split(ip, ".")
-			parts[len(parts)-1] = "0"
-			return strings.Join(parts, ".")
-		}
-		if strings.Contains(ip, ":") {
-			parts := strings.Split(ip, ":")
-			if len(parts) > 2 {
-				parts = parts[0:3]
-				parts = append(parts, "0000:0000:0000:0000:0000")
-				return strings.Join(parts, ":")
-			}
-			return ip
-		}
-		return ip
+		return anonymizeIP(ip, v4Bits, v6Bits)
 	case CaptureIpNone:
 		return ""
 	default:
@@ -242,6 +458,60 @@ func filterIp(ip string, captureIp captureIp) string {
 	}
 }
 
+var (
+	bearerTokenPattern = regexp.MustCompile(`(?i)(bearer\s+)[a-z0-9\-._~+/]+=*`)
+	jwtPattern         = regexp.MustCompile(`eyJ[a-zA-Z0-9_\-]+\.[a-zA-Z0-9_\-]+\.[a-zA-Z0-9_\-]*`)
+	hexSecretPattern   = regexp.MustCompile(`\b[0-9a-fA-F]{32,}\b`)
+)
+
+// scrubSecrets masks JWTs, bearer tokens and hexadecimal strings of 32 characters or more, such as
+// API keys and digests, found in free text.
+func scrubSecrets(s string) string {
+	s = bearerTokenPattern.ReplaceAllString(s, "${1}"+FILTERED)
+	s = jwtPattern.ReplaceAllString(s, FILTERED)
+	return hexSecretPattern.ReplaceAllString(s, FILTERED)
+}
+
+// scrubSecretsInData masks the secrets embedded in the title, the error and message texts and the
+// bodies of the telemetry events of an item. Telemetry events are copied rather than modified as
+// they are shared with the telemetry queue.
+func scrubSecretsInData(data map[string]interface{}) {
+	scrubTextsInData(data, scrubSecrets)
+	body, ok := data["body"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	if telemetry, ok := body["telemetry"].([]interface{}); ok {
+		body["telemetry"] = redactor{value: scrubSecrets}.redact(telemetry, false)
+	}
+}
+
+// scrubTextsInData replaces the title, the exception messages and the message body of an item by
+// the result of scrub.
+func scrubTextsInData(data map[string]interface{}, scrub func(string) string) {
+	if title, ok := data["title"].(string); ok {
+		data["title"] = scrub(title)
+	}
+	body, ok := data["body"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	if message, ok := body["message"].(map[string]interface{}); ok {
+		if text, ok := message["body"].(string); ok {
+			message["body"] = scrub(text)
+		}
+	}
+	if traceChain, ok := body["trace_chain"].([]map[string]interface{}); ok {
+		for _, trace := range traceChain {
+			if exception, ok := trace["exception"].(map[string]interface{}); ok {
+				if text, ok := exception["message"].(string); ok {
+					exception["message"] = scrub(text)
+				}
+			}
+		}
+	}
+}
+
 // Build an error inner-body for the given error. If skip is provided, that
 // number of stack trace frames will be skipped. If the error has a Cause
 // method, the causes will be traversed until nil.
@@ -251,7 +521,8 @@ func errorBody(configuration configuration, err error, skip int) (map[string]int
 	traceChain := []map[string]interface{}{}
 	fingerprint := ""
 	for {
-		stack := buildStack(getOrBuildFrames(err, parent, 1+skip, configuration.stackTracer))
+		frames := getOrBuildFrames(err, parent, 1+skip, configuration.stackTracer)
+		stack := buildStack(trimFrames(frames, configuration.skipPresets))
 		traceChain = append(traceChain, buildTrace(err, stack))
 		if configuration.fingerprint {
 			fingerprint = fingerprint + stack.Fingerprint()
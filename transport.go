@@ -1,12 +1,17 @@
 package rollbar
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
+	"strings"
 )
 
 const (
@@ -82,11 +87,76 @@ func rollbarError(logger ClientLogger, format string, args ...interface{}) {
 	}
 }
 
-func writePayloadToStderr(logger ClientLogger, payload map[string]interface{}) {
+func rollbarDebug(logger ClientLogger, format string, args ...interface{}) {
+	format = "Rollbar debug: " + format + "\n"
+	if logger != nil {
+		logger.Printf(format, args...)
+	} else {
+		log.Printf(format, args...)
+	}
+}
+
+func writePayloadToStderr(logger ClientLogger, payload map[string]interface{}, compress bool) {
 	format := "Rollbar item failed to send: %v\n"
+	var args []interface{}
+	if compress {
+		if encoded, size, err := encodePayload(payload); err == nil {
+			format = compressedPayloadHeader + " %d bytes: %s\n"
+			args = []interface{}{size, encoded}
+		}
+	}
+	if args == nil {
+		args = []interface{}{payload}
+	}
 	if logger != nil {
-		logger.Printf(format, payload)
+		logger.Printf(format, args...)
 	} else {
-		fmt.Fprintf(os.Stderr, format, payload)
+		fmt.Fprintf(os.Stderr, format, args...)
+	}
+}
+
+// compressedPayloadHeader starts the lines of payloads written gzip compressed and base64 encoded
+// by transports, see SetCompressPayloadOnError.
+const compressedPayloadHeader = "Rollbar item failed to send (gzip+base64):"
+
+// encodePayload returns the gzip compressed and base64 encoded JSON of payload, along with the
+// size of the JSON.
+func encodePayload(payload map[string]interface{}) (string, int, error) {
+	jsonBody, err := json.Marshal(payload)
+	if err != nil {
+		return "", 0, err
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(jsonBody); err != nil {
+		return "", 0, err
+	}
+	if err := zw.Close(); err != nil {
+		return "", 0, err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), len(jsonBody), nil
+}
+
+// DecodePayload decodes a payload written by a transport which failed to send it with
+// SetCompressPayloadOnError enabled. It accepts either the whole logged line or only its encoded
+// part, so that items recovered from logs can be inspected or sent again.
+func DecodePayload(s string) (map[string]interface{}, error) {
+	s = strings.TrimSpace(s)
+	if i := strings.LastIndex(s, " "); i >= 0 {
+		s = s[i+1:]
+	}
+	compressed, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	var payload map[string]interface{}
+	if err := json.NewDecoder(zr).Decode(&payload); err != nil {
+		return nil, err
 	}
+	return payload, nil
 }
@@ -0,0 +1,63 @@
+package rollbar
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// warmup establishes a connection to the endpoint with a HEAD request, leaving it in the pool of
+// the HTTP client of the transport for the next items. The status of the response is irrelevant.
+func (t *baseTransport) warmup(ctx context.Context) error {
+	t.lock.RLock()
+	endpoint := t.Endpoint
+	client := t.getHTTPClient()
+	t.lock.RUnlock()
+	req, err := http.NewRequest("HEAD", endpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	return resp.Body.Close()
+}
+
+func (t *interceptedTransport) warmup(ctx context.Context) error {
+	if inner, ok := t.Transport.(warmer); ok {
+		return inner.warmup(ctx)
+	}
+	return nil
+}
+
+// warmer is implemented by the transports able to establish their connection ahead of time.
+type warmer interface {
+	warmup(ctx context.Context) error
+}
+
+// Warmup resolves the endpoint and establishes a connection to it, including the TLS handshake,
+// so that the first item reported after startup is not delayed by the connection setup, or lost
+// because the program exits before it is sent. It is meant to be called once the client is
+// configured, e.g. in a goroutine at startup:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+//	defer cancel()
+//	if err := client.Warmup(ctx); err != nil {
+//		log.Printf("rollbar endpoint unreachable: %v", err)
+//	}
+//
+// The connection is only reused if the HTTP client of the transport keeps idle connections, which
+// http.DefaultClient does. Warmup does nothing when the client is disabled or its transport is not
+// implemented by this package.
+func (c *Client) Warmup(ctx context.Context) error {
+	if !c.enabled() {
+		return nil
+	}
+	if t, ok := c.Transport.(warmer); ok {
+		return t.warmup(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package rollbar
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestWarmup(t *testing.T) {
+	var conns, heads int32
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "HEAD" {
+			atomic.AddInt32(&heads, 1)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	ts.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	ts.Start()
+	defer ts.Close()
+
+	client := NewSync("token", "test", "", "", "")
+	client.SetEndpoint(ts.URL)
+	client.SetHTTPClient(&http.Client{Transport: &http.Transport{}})
+
+	if err := client.Warmup(context.Background()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	client.Message(INFO, "first item")
+
+	if atomic.LoadInt32(&heads) != 1 {
+		t.Error("expected one HEAD request, got:", heads)
+	}
+	if atomic.LoadInt32(&conns) != 1 {
+		t.Error("expected the first item to reuse the warm connection, got connections:", conns)
+	}
+}
+
+func TestWarmupUnreachable(t *testing.T) {
+	client := NewSync("token", "test", "", "", "")
+	client.SetEndpoint("http://127.0.0.1:1/")
+
+	if err := client.Warmup(context.Background()); err == nil {
+		t.Error("expected an error for an unreachable endpoint")
+	}
+}